@@ -0,0 +1,6 @@
+// Package todopb is the gRPC API of the todo service, generated from
+// todo.proto. Clients dial a server started with `todo grpc` and use
+// NewTodoServiceClient.
+package todopb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative todo.proto
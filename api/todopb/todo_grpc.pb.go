@@ -0,0 +1,511 @@
+// The todo service lets other programs read, change and watch a todo list.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v27.3.0
+// source: todo.proto
+
+package todopb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	TodoService_ListItems_FullMethodName      = "/todo.v1.TodoService/ListItems"
+	TodoService_AddItem_FullMethodName        = "/todo.v1.TodoService/AddItem"
+	TodoService_EditItem_FullMethodName       = "/todo.v1.TodoService/EditItem"
+	TodoService_CompleteItem_FullMethodName   = "/todo.v1.TodoService/CompleteItem"
+	TodoService_UncompleteItem_FullMethodName = "/todo.v1.TodoService/UncompleteItem"
+	TodoService_DeleteItem_FullMethodName     = "/todo.v1.TodoService/DeleteItem"
+	TodoService_SetPriority_FullMethodName    = "/todo.v1.TodoService/SetPriority"
+	TodoService_SetDueDate_FullMethodName     = "/todo.v1.TodoService/SetDueDate"
+	TodoService_TagItem_FullMethodName        = "/todo.v1.TodoService/TagItem"
+	TodoService_GetStats_FullMethodName       = "/todo.v1.TodoService/GetStats"
+	TodoService_Watch_FullMethodName          = "/todo.v1.TodoService/Watch"
+)
+
+// TodoServiceClient is the client API for TodoService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type TodoServiceClient interface {
+	// ListItems returns the items matching a filter, in list order
+	ListItems(ctx context.Context, in *ListItemsRequest, opts ...grpc.CallOption) (*ListItemsResponse, error)
+	AddItem(ctx context.Context, in *AddItemRequest, opts ...grpc.CallOption) (*NumberedItem, error)
+	EditItem(ctx context.Context, in *EditItemRequest, opts ...grpc.CallOption) (*NumberedItem, error)
+	CompleteItem(ctx context.Context, in *ItemRef, opts ...grpc.CallOption) (*NumberedItem, error)
+	UncompleteItem(ctx context.Context, in *ItemRef, opts ...grpc.CallOption) (*NumberedItem, error)
+	DeleteItem(ctx context.Context, in *ItemRef, opts ...grpc.CallOption) (*NumberedItem, error)
+	SetPriority(ctx context.Context, in *SetPriorityRequest, opts ...grpc.CallOption) (*NumberedItem, error)
+	SetDueDate(ctx context.Context, in *SetDueDateRequest, opts ...grpc.CallOption) (*NumberedItem, error)
+	TagItem(ctx context.Context, in *TagItemRequest, opts ...grpc.CallOption) (*NumberedItem, error)
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*Stats, error)
+	// Watch streams changes to the list until the client cancels
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Change], error)
+}
+
+type todoServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTodoServiceClient(cc grpc.ClientConnInterface) TodoServiceClient {
+	return &todoServiceClient{cc}
+}
+
+func (c *todoServiceClient) ListItems(ctx context.Context, in *ListItemsRequest, opts ...grpc.CallOption) (*ListItemsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListItemsResponse)
+	err := c.cc.Invoke(ctx, TodoService_ListItems_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoServiceClient) AddItem(ctx context.Context, in *AddItemRequest, opts ...grpc.CallOption) (*NumberedItem, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NumberedItem)
+	err := c.cc.Invoke(ctx, TodoService_AddItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoServiceClient) EditItem(ctx context.Context, in *EditItemRequest, opts ...grpc.CallOption) (*NumberedItem, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NumberedItem)
+	err := c.cc.Invoke(ctx, TodoService_EditItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoServiceClient) CompleteItem(ctx context.Context, in *ItemRef, opts ...grpc.CallOption) (*NumberedItem, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NumberedItem)
+	err := c.cc.Invoke(ctx, TodoService_CompleteItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoServiceClient) UncompleteItem(ctx context.Context, in *ItemRef, opts ...grpc.CallOption) (*NumberedItem, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NumberedItem)
+	err := c.cc.Invoke(ctx, TodoService_UncompleteItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoServiceClient) DeleteItem(ctx context.Context, in *ItemRef, opts ...grpc.CallOption) (*NumberedItem, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NumberedItem)
+	err := c.cc.Invoke(ctx, TodoService_DeleteItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoServiceClient) SetPriority(ctx context.Context, in *SetPriorityRequest, opts ...grpc.CallOption) (*NumberedItem, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NumberedItem)
+	err := c.cc.Invoke(ctx, TodoService_SetPriority_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoServiceClient) SetDueDate(ctx context.Context, in *SetDueDateRequest, opts ...grpc.CallOption) (*NumberedItem, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NumberedItem)
+	err := c.cc.Invoke(ctx, TodoService_SetDueDate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoServiceClient) TagItem(ctx context.Context, in *TagItemRequest, opts ...grpc.CallOption) (*NumberedItem, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NumberedItem)
+	err := c.cc.Invoke(ctx, TodoService_TagItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoServiceClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*Stats, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Stats)
+	err := c.cc.Invoke(ctx, TodoService_GetStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoServiceClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Change], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TodoService_ServiceDesc.Streams[0], TodoService_Watch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRequest, Change]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TodoService_WatchClient = grpc.ServerStreamingClient[Change]
+
+// TodoServiceServer is the server API for TodoService service.
+// All implementations must embed UnimplementedTodoServiceServer
+// for forward compatibility.
+type TodoServiceServer interface {
+	// ListItems returns the items matching a filter, in list order
+	ListItems(context.Context, *ListItemsRequest) (*ListItemsResponse, error)
+	AddItem(context.Context, *AddItemRequest) (*NumberedItem, error)
+	EditItem(context.Context, *EditItemRequest) (*NumberedItem, error)
+	CompleteItem(context.Context, *ItemRef) (*NumberedItem, error)
+	UncompleteItem(context.Context, *ItemRef) (*NumberedItem, error)
+	DeleteItem(context.Context, *ItemRef) (*NumberedItem, error)
+	SetPriority(context.Context, *SetPriorityRequest) (*NumberedItem, error)
+	SetDueDate(context.Context, *SetDueDateRequest) (*NumberedItem, error)
+	TagItem(context.Context, *TagItemRequest) (*NumberedItem, error)
+	GetStats(context.Context, *GetStatsRequest) (*Stats, error)
+	// Watch streams changes to the list until the client cancels
+	Watch(*WatchRequest, grpc.ServerStreamingServer[Change]) error
+	mustEmbedUnimplementedTodoServiceServer()
+}
+
+// UnimplementedTodoServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTodoServiceServer struct{}
+
+func (UnimplementedTodoServiceServer) ListItems(context.Context, *ListItemsRequest) (*ListItemsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListItems not implemented")
+}
+func (UnimplementedTodoServiceServer) AddItem(context.Context, *AddItemRequest) (*NumberedItem, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddItem not implemented")
+}
+func (UnimplementedTodoServiceServer) EditItem(context.Context, *EditItemRequest) (*NumberedItem, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EditItem not implemented")
+}
+func (UnimplementedTodoServiceServer) CompleteItem(context.Context, *ItemRef) (*NumberedItem, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompleteItem not implemented")
+}
+func (UnimplementedTodoServiceServer) UncompleteItem(context.Context, *ItemRef) (*NumberedItem, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UncompleteItem not implemented")
+}
+func (UnimplementedTodoServiceServer) DeleteItem(context.Context, *ItemRef) (*NumberedItem, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteItem not implemented")
+}
+func (UnimplementedTodoServiceServer) SetPriority(context.Context, *SetPriorityRequest) (*NumberedItem, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetPriority not implemented")
+}
+func (UnimplementedTodoServiceServer) SetDueDate(context.Context, *SetDueDateRequest) (*NumberedItem, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetDueDate not implemented")
+}
+func (UnimplementedTodoServiceServer) TagItem(context.Context, *TagItemRequest) (*NumberedItem, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TagItem not implemented")
+}
+func (UnimplementedTodoServiceServer) GetStats(context.Context, *GetStatsRequest) (*Stats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
+}
+func (UnimplementedTodoServiceServer) Watch(*WatchRequest, grpc.ServerStreamingServer[Change]) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedTodoServiceServer) mustEmbedUnimplementedTodoServiceServer() {}
+func (UnimplementedTodoServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeTodoServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TodoServiceServer will
+// result in compilation errors.
+type UnsafeTodoServiceServer interface {
+	mustEmbedUnimplementedTodoServiceServer()
+}
+
+func RegisterTodoServiceServer(s grpc.ServiceRegistrar, srv TodoServiceServer) {
+	// If the following call pancis, it indicates UnimplementedTodoServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TodoService_ServiceDesc, srv)
+}
+
+func _TodoService_ListItems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListItemsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServiceServer).ListItems(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TodoService_ListItems_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServiceServer).ListItems(ctx, req.(*ListItemsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoService_AddItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServiceServer).AddItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TodoService_AddItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServiceServer).AddItem(ctx, req.(*AddItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoService_EditItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EditItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServiceServer).EditItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TodoService_EditItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServiceServer).EditItem(ctx, req.(*EditItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoService_CompleteItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ItemRef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServiceServer).CompleteItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TodoService_CompleteItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServiceServer).CompleteItem(ctx, req.(*ItemRef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoService_UncompleteItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ItemRef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServiceServer).UncompleteItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TodoService_UncompleteItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServiceServer).UncompleteItem(ctx, req.(*ItemRef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoService_DeleteItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ItemRef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServiceServer).DeleteItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TodoService_DeleteItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServiceServer).DeleteItem(ctx, req.(*ItemRef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoService_SetPriority_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetPriorityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServiceServer).SetPriority(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TodoService_SetPriority_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServiceServer).SetPriority(ctx, req.(*SetPriorityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoService_SetDueDate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetDueDateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServiceServer).SetDueDate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TodoService_SetDueDate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServiceServer).SetDueDate(ctx, req.(*SetDueDateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoService_TagItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TagItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServiceServer).TagItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TodoService_TagItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServiceServer).TagItem(ctx, req.(*TagItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoService_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServiceServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TodoService_GetStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServiceServer).GetStats(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoService_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TodoServiceServer).Watch(m, &grpc.GenericServerStream[WatchRequest, Change]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TodoService_WatchServer = grpc.ServerStreamingServer[Change]
+
+// TodoService_ServiceDesc is the grpc.ServiceDesc for TodoService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TodoService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "todo.v1.TodoService",
+	HandlerType: (*TodoServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListItems",
+			Handler:    _TodoService_ListItems_Handler,
+		},
+		{
+			MethodName: "AddItem",
+			Handler:    _TodoService_AddItem_Handler,
+		},
+		{
+			MethodName: "EditItem",
+			Handler:    _TodoService_EditItem_Handler,
+		},
+		{
+			MethodName: "CompleteItem",
+			Handler:    _TodoService_CompleteItem_Handler,
+		},
+		{
+			MethodName: "UncompleteItem",
+			Handler:    _TodoService_UncompleteItem_Handler,
+		},
+		{
+			MethodName: "DeleteItem",
+			Handler:    _TodoService_DeleteItem_Handler,
+		},
+		{
+			MethodName: "SetPriority",
+			Handler:    _TodoService_SetPriority_Handler,
+		},
+		{
+			MethodName: "SetDueDate",
+			Handler:    _TodoService_SetDueDate_Handler,
+		},
+		{
+			MethodName: "TagItem",
+			Handler:    _TodoService_TagItem_Handler,
+		},
+		{
+			MethodName: "GetStats",
+			Handler:    _TodoService_GetStats_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _TodoService_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "todo.proto",
+}
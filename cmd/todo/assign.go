@@ -0,0 +1,208 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+// userFile holds the identity set with `todo whoami <name>`
+func userFile() string {
+	return filepath.Join(configDir(), "user")
+}
+
+// currentUser is who is using the CLI: $TODO_USER, the name set with
+// `todo whoami <name>`, or the login name
+func currentUser() string {
+	if name := os.Getenv("TODO_USER"); name != "" {
+		return name
+	}
+	if data, err := os.ReadFile(userFile()); err == nil {
+		if name := strings.TrimSpace(string(data)); name != "" {
+			return name
+		}
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return ""
+}
+
+// runWhoami implements `todo whoami [name]`, showing or setting the identity
+func runWhoami(args []string) {
+	if len(args) == 0 {
+		name := currentUser()
+		if name == "" {
+			fmt.Println("No identity set; set one with 'todo whoami <name>'")
+			return
+		}
+		fmt.Println(name)
+		return
+	}
+
+	name := strings.TrimSpace(strings.Join(args, " "))
+	if name == "" || strings.ContainsAny(name, ",\n") {
+		fmt.Println("Error: Invalid name:", name)
+		os.Exit(1)
+	}
+	if err := os.MkdirAll(configDir(), 0700); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(userFile(), []byte(name+"\n"), 0644); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	fmt.Println("You are now", name)
+	if env := os.Getenv("TODO_USER"); env != "" {
+		fmt.Fprintf(os.Stderr, "Warning: TODO_USER=%s takes precedence\n", env)
+	}
+}
+
+// runAssign implements `todo assign <n> <user>`; "me" is the current user
+// and "none" unassigns the task
+func runAssign(list *todo.List, args []string) {
+	if len(args) < 2 {
+		fmt.Println("Error: Missing item number or user")
+		fmt.Println("Usage: todo assign <n> <user|me|none>")
+		os.Exit(1)
+	}
+	num, err := strconv.Atoi(args[0])
+	if err != nil {
+		fmt.Println("Error: Invalid item number:", args[0])
+		os.Exit(1)
+	}
+
+	name := strings.Join(args[1:], " ")
+	switch strings.ToLower(name) {
+	case "me":
+		if name = currentUser(); name == "" {
+			fmt.Println("Error: No identity set; set one with 'todo whoami <name>'")
+			os.Exit(1)
+		}
+	case "none", "-":
+		name = ""
+	}
+
+	if err := list.SetAssignee(num-1, name); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	saveTodos(list)
+	if name == "" {
+		fmt.Println("Unassigned item")
+	} else {
+		fmt.Println("Assigned to", name)
+	}
+}
+
+// assigneeFlags are the --mine and --assignee flags of list, search and overdue
+type assigneeFlags struct {
+	mine     *bool
+	assignee *string
+}
+
+func addAssigneeFlags(fs *flag.FlagSet) assigneeFlags {
+	return assigneeFlags{
+		mine:     fs.Bool("mine", false, "Only tasks assigned to you"),
+		assignee: fs.String("assignee", "", "Only tasks assigned to this user (none for unassigned)"),
+	}
+}
+
+// user returns whose tasks to show, and false when the flags weren't given
+func (f assigneeFlags) user() (string, bool, error) {
+	switch {
+	case *f.mine && *f.assignee != "":
+		return "", false, errors.New("Use either --mine or --assignee")
+	case *f.mine:
+		name := currentUser()
+		if name == "" {
+			return "", false, errors.New("No identity set; set one with 'todo whoami <name>'")
+		}
+		return name, true, nil
+	case strings.EqualFold(*f.assignee, "none"):
+		return "", true, nil
+	case *f.assignee != "":
+		return *f.assignee, true, nil
+	}
+	return "", false, nil
+}
+
+// parseAssigneeFlags parses a command's arguments, returning the remaining
+// arguments and a filter for the results, or nil when no filter was asked for
+func parseAssigneeFlags(name string, args []string) ([]string, func([]todo.Item) []todo.Item) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	flags := addAssigneeFlags(fs)
+	fs.Parse(args)
+
+	assignee, ok, err := flags.user()
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	if !ok {
+		return fs.Args(), nil
+	}
+	return fs.Args(), func(items []todo.Item) []todo.Item {
+		return todo.AssignedTo(items, assignee)
+	}
+}
+
+// runList implements `todo list [--mine | --assignee <user>] [--all]`; tasks
+// whose context isn't active are hidden unless --all is given
+func runList(list *todo.List, args []string) {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	flags := addAssigneeFlags(fs)
+	all := fs.Bool("all", false, "Also show tasks whose context isn't active")
+	fs.Parse(args)
+
+	assignee, ok, err := flags.user()
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	if len(list.Items) == 0 {
+		fmt.Println(list)
+		return
+	}
+
+	active := activeContext()
+	shown, hidden := 0, 0
+	keep := func(item todo.Item) bool {
+		return (!ok || strings.EqualFold(item.Assignee, assignee)) && (*all || active(item))
+	}
+	for _, item := range list.Items {
+		switch {
+		case keep(item):
+			shown++
+		case !ok || strings.EqualFold(item.Assignee, assignee):
+			hidden++
+		}
+	}
+	if shown == 0 {
+		fmt.Println("No items found")
+	} else {
+		fmt.Println(list.Filtered(keep))
+	}
+	if hidden > 0 {
+		fmt.Printf("%d tasks hidden in inactive contexts; 'todo list --all' shows them\n", hidden)
+	}
+}
+
+func printUserStats(stats []todo.UserStats) {
+	fmt.Println("By assignee:")
+	for _, s := range stats {
+		line := fmt.Sprintf("  %s: %d pending, %d completed", s.User, s.Pending, s.Completed)
+		if remaining := formatRemaining(s.Stats); remaining != "" {
+			line += ", " + remaining + " remaining"
+		}
+		fmt.Println(line)
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/rahul4507/todo/internal/auth"
+	"github.com/rahul4507/todo/internal/caldav"
+)
+
+// runCalDAV implements `todo caldav`, serving the list to calendar and task apps
+func runCalDAV(args []string) {
+	fs := flag.NewFlagSet("caldav", flag.ExitOnError)
+	addrFlag := fs.String("addr", "localhost:5232", "Address to listen on")
+	nameFlag := fs.String("name", "Todo", "Calendar name shown in clients")
+	listFlag := fs.String("list", defaultListName(), "Name of the list, for tokens limited to some lists")
+	fs.Parse(args)
+
+	handler := caldav.NewHandler(store)
+	handler.DisplayName = *nameFlag
+	// Calendar apps send the token as their password
+	guard := &auth.Guard{Path: tokenFile(), List: *listFlag, Basic: true}
+	warnWithoutTokens()
+
+	fmt.Printf("Serving CalDAV for list %q on http://%s%s (Ctrl+C to stop)\n", *listFlag, *addrFlag, caldav.CollectionPath)
+	if err := http.ListenAndServe(*addrFlag, guard.Wrap(handler)); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/rahul4507/todo/internal/completion"
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+var (
+	itemArg     = completion.Arg{Source: completion.SourceItems}
+	tagArg      = completion.Arg{Source: completion.SourceTags}
+	priorityArg = completion.Arg{Words: todo.PriorityNames()}
+)
+
+// commands are what `todo completion` completes
+var commands = []completion.Cmd{
+	{Name: "add", Description: "Add a new todo item"},
+	{Name: "list", Description: "List todo items"},
+	{Name: "complete", Description: "Mark an item as completed", Args: []completion.Arg{itemArg}},
+	{Name: "uncomplete", Description: "Mark an item as incomplete", Args: []completion.Arg{itemArg}},
+	{Name: "delete", Description: "Delete an item", Args: []completion.Arg{itemArg}},
+	{Name: "edit", Description: "Edit the text of an item", Args: []completion.Arg{itemArg}},
+	{Name: "clear", Description: "Remove all completed items"},
+	{Name: "stats", Description: "Show statistics"},
+	{Name: "priority", Description: "Set the priority of an item", Args: []completion.Arg{itemArg, priorityArg}},
+	{Name: "due", Description: "Set the due date of an item", Args: []completion.Arg{itemArg}},
+	{Name: "tag", Description: "Add a tag to an item", Args: []completion.Arg{itemArg, tagArg}},
+	{Name: "untag", Description: "Remove a tag from an item", Args: []completion.Arg{itemArg, tagArg}},
+	{Name: "estimate", Description: "Set the estimated effort of an item", Args: []completion.Arg{itemArg}},
+	{Name: "track", Description: "Log time spent on an item", Args: []completion.Arg{itemArg}},
+	{Name: "report", Description: "Compare estimates with tracked time per tag"},
+	{Name: "assign", Description: "Assign an item to a user", Args: []completion.Arg{itemArg}},
+	{Name: "whoami", Description: "Show or set who you are"},
+	{Name: "context", Description: "Manage contexts", Args: []completion.Arg{{Words: []string{"list", "add", "remove", "use", "set"}}}},
+	{Name: "ready", Description: "List pending tasks whose context is active"},
+	{Name: "search", Description: "Search tasks by text or tag", Args: []completion.Arg{tagArg}},
+	{Name: "overdue", Description: "Show overdue tasks"},
+	{Name: "remind", Description: "Run the reminder daemon"},
+	{Name: "caldav", Description: "Serve tasks to CalDAV clients"},
+	{Name: "serve", Description: "Serve the web UI"},
+	{Name: "rpc", Description: "Serve JSON-RPC on stdin/stdout"},
+	{Name: "mcp", Description: "Serve the Model Context Protocol on stdin/stdout"},
+	{Name: "grpc", Description: "Serve the gRPC API"},
+	{Name: "token", Description: "Manage API tokens", Args: []completion.Arg{{Words: []string{"create", "list", "revoke"}}}},
+	{Name: "webhooks", Description: "Manage webhooks", Args: []completion.Arg{{Words: []string{"list", "add", "remove", "test", "flush"}}}},
+	{Name: "export", Description: "Export tasks as ics, csv or md"},
+	{Name: "import", Description: "Import tasks from a file"},
+	{Name: "sync-md", Description: "Sync the checklist in a Markdown file"},
+	{Name: "sync", Description: "Sync the list with other machines"},
+	{Name: "sync-server", Description: "Run or manage a sync server", Args: []completion.Arg{{Words: []string{"add-device", "remove-device", "devices"}}}},
+	{Name: "merge", Description: "Merge two edited copies of a todo file"},
+	{Name: "migrate", Description: "Move the list to another store"},
+	{Name: "encrypt", Description: "Encrypt todos.json with a passphrase"},
+	{Name: "decrypt", Description: "Decrypt todos.json"},
+	{Name: "completion", Description: "Print a shell completion script", Args: []completion.Arg{{Words: completion.Shells}}},
+	{Name: "help", Description: "Show help"},
+}
+
+// runCompletion implements `todo completion <shell>`
+func runCompletion(args []string) {
+	if len(args) != 1 {
+		fmt.Printf("Error: Usage: todo completion %s\n", strings.Join(completion.Shells, "|"))
+		os.Exit(1)
+	}
+	if err := completion.Write(os.Stdout, args[0], "todo", commands); err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+}
+
+// runComplete prints the values completion scripts ask for
+func runComplete(list *todo.List, args []string) {
+	if len(args) != 1 {
+		os.Exit(1)
+	}
+	switch args[0] {
+	case completion.SourceItems:
+		for i, item := range list.Items {
+			text := item.Text
+			if item.Done {
+				text = "✓ " + text
+			}
+			fmt.Printf("%d\t%s\n", i+1, text)
+		}
+	case completion.SourceTags:
+		seen := map[string]bool{}
+		var tags []string
+		for _, item := range list.Items {
+			for _, tag := range item.Tags {
+				if !seen[tag] {
+					seen[tag] = true
+					tags = append(tags, tag)
+				}
+			}
+		}
+		sort.Strings(tags)
+		for _, tag := range tags {
+			fmt.Println(tag)
+		}
+	default:
+		os.Exit(1)
+	}
+}
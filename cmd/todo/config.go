@@ -0,0 +1,19 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// configDir is where user configuration such as hooks lives: $TODO_CONFIG_DIR,
+// or a "todo" directory in the user's config directory (e.g. ~/.config/todo)
+func configDir() string {
+	if dir := os.Getenv("TODO_CONFIG_DIR"); dir != "" {
+		return dir
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ".todo"
+	}
+	return filepath.Join(dir, "todo")
+}
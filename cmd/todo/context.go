@@ -0,0 +1,286 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rahul4507/todo/internal/contexts"
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+// contextsFile holds the contexts and the current setting
+func contextsFile() string {
+	return filepath.Join(configDir(), "contexts.json")
+}
+
+// loadContexts reads the contexts; $TODO_CONTEXT, a comma separated list,
+// takes precedence over the current setting
+func loadContexts() *contexts.Config {
+	config, err := contexts.Load(contextsFile())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	if env := os.Getenv("TODO_CONTEXT"); env != "" {
+		names, err := parseContextNames(strings.Split(env, ","))
+		if err != nil {
+			fmt.Println("Error: TODO_CONTEXT:", err)
+			os.Exit(1)
+		}
+		config.Current = names
+	}
+	return config
+}
+
+func parseContextNames(args []string) ([]string, error) {
+	var names []string
+	for _, arg := range args {
+		for _, part := range strings.Split(arg, ",") {
+			if strings.TrimSpace(part) == "" {
+				continue
+			}
+			name, err := todo.ContextName(part)
+			if err != nil {
+				return nil, err
+			}
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// activeContext returns whether an item's context is active now
+func activeContext() func(todo.Item) bool {
+	config := loadContexts()
+	now := time.Now()
+	return func(item todo.Item) bool {
+		return config.Active(item.Context, now)
+	}
+}
+
+// runContext implements `todo context`, managing contexts
+func runContext(list *todo.List, args []string) {
+	if len(args) == 0 {
+		args = []string{"list"}
+	}
+	config := loadContexts()
+
+	switch args[0] {
+	case "list":
+		printContexts(list, config)
+
+	case "add":
+		fs := flag.NewFlagSet("context add", flag.ExitOnError)
+		hoursFlag := fs.String("hours", "", "Time of day the context is open, e.g. 09:00-17:00")
+		daysFlag := fs.String("days", "", "Days the context is open, e.g. mon-fri")
+		locationFlag := fs.String("location", "", "Place the context belongs to, as one word, or "+contexts.Anywhere)
+		fs.Parse(args[1:])
+		if fs.NArg() != 1 {
+			fmt.Println("Error: Usage: todo context add [-hours 09:00-17:00] [-days mon-fri] [-location place] <name>")
+			os.Exit(1)
+		}
+
+		name, err := todo.ContextName(fs.Arg(0))
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		ctx := contexts.Context{Name: name, Location: *locationFlag}
+		if *hoursFlag != "" {
+			if ctx.Hours, err = contexts.ParseHours(*hoursFlag); err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+		}
+		if *daysFlag != "" {
+			if ctx.Days, err = contexts.ParseDays(*daysFlag); err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+		}
+		if err := config.Define(ctx); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		saveContexts(config)
+		ctx, _ = config.Lookup(name)
+		fmt.Println(strings.TrimSpace(fmt.Sprintf("Defined @%s %s", ctx.Name, ctx)))
+
+	case "remove":
+		if len(args) != 2 {
+			fmt.Println("Error: Usage: todo context remove <name>")
+			os.Exit(1)
+		}
+		name, err := todo.ContextName(args[1])
+		if err == nil {
+			err = config.Remove(name)
+		}
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		saveContexts(config)
+		fmt.Println("Removed @" + name)
+
+	case "use":
+		if len(args) < 2 {
+			fmt.Println("Error: Usage: todo context use <name or location>... | none")
+			os.Exit(1)
+		}
+		var names []string
+		if !(len(args) == 2 && strings.EqualFold(args[1], "none")) {
+			var err error
+			if names, err = parseContextNames(args[1:]); err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			used := usedContexts(list)
+			for _, name := range names {
+				if _, ok := config.Lookup(name); !ok && !config.IsLocation(name) && !used[name] {
+					fmt.Println("Error: Unknown context or location:", name)
+					os.Exit(1)
+				}
+			}
+		}
+		config.Current = names
+		saveContexts(config)
+		if len(names) == 0 {
+			fmt.Println("No current context; every context is active within its hours")
+		} else {
+			fmt.Println("Now in", strings.Join(names, ", "))
+		}
+		if env := os.Getenv("TODO_CONTEXT"); env != "" {
+			fmt.Fprintf(os.Stderr, "Warning: TODO_CONTEXT=%s takes precedence\n", env)
+		}
+
+	case "set":
+		if len(args) != 3 {
+			fmt.Println("Error: Usage: todo context set <n> <name|none>")
+			os.Exit(1)
+		}
+		num, err := strconv.Atoi(args[1])
+		if err != nil {
+			fmt.Println("Error: Invalid item number:", args[1])
+			os.Exit(1)
+		}
+		name := args[2]
+		if strings.EqualFold(name, "none") {
+			name = ""
+		}
+		if err := list.SetContext(num-1, name); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		saveTodos(list)
+		if context := list.Items[num-1].Context; context == "" {
+			fmt.Println("Cleared the context of item", num)
+		} else {
+			fmt.Printf("Set the context of item %d to @%s\n", num, context)
+		}
+
+	default:
+		fmt.Println("Error: Unknown context command:", args[0])
+		fmt.Println("Usage: todo context [list|add|remove|use|set]")
+		os.Exit(1)
+	}
+}
+
+func saveContexts(config *contexts.Config) {
+	if err := os.MkdirAll(configDir(), 0700); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	if err := config.Save(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+// usedContexts returns the contexts tasks are in
+func usedContexts(list *todo.List) map[string]bool {
+	used := map[string]bool{}
+	for _, item := range list.Items {
+		if item.Context != "" {
+			used[item.Context] = true
+		}
+	}
+	return used
+}
+
+// printContexts lists the defined contexts and those tasks use, marking the
+// active ones
+func printContexts(list *todo.List, config *contexts.Config) {
+	if len(config.Current) == 0 {
+		fmt.Println("Current: none (every context is active within its hours)")
+	} else {
+		fmt.Println("Current:", strings.Join(config.Current, ", "))
+	}
+
+	pending := map[string]int{}
+	for _, item := range list.Items {
+		if item.Context != "" && !item.Done {
+			pending[item.Context]++
+		}
+	}
+	names := make([]string, 0, len(config.Contexts))
+	for _, ctx := range config.Contexts {
+		names = append(names, ctx.Name)
+	}
+	for name := range usedContexts(list) {
+		if _, ok := config.Lookup(name); !ok {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		fmt.Println("No contexts; define one with 'todo context add <name>'")
+		return
+	}
+	sort.Strings(names)
+
+	now := time.Now()
+	for _, name := range names {
+		mark := " "
+		if config.Active(name, now) {
+			mark = "*"
+		}
+		ctx, ok := config.Lookup(name)
+		limits := ctx.String()
+		if !ok {
+			limits = "(not defined)"
+		}
+		fmt.Printf("%s @%-12s %-32s %d pending\n", mark, name, limits, pending[name])
+	}
+	fmt.Println("* active now")
+}
+
+// runReady implements `todo ready [--mine | --assignee <user>]`, listing
+// pending tasks whose context is active
+func runReady(list *todo.List, args []string) {
+	fs := flag.NewFlagSet("ready", flag.ExitOnError)
+	flags := addAssigneeFlags(fs)
+	fs.Parse(args)
+
+	assignee, byAssignee, err := flags.user()
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	active := activeContext()
+	ready := func(item todo.Item) bool {
+		return !item.Done && active(item) &&
+			(!byAssignee || strings.EqualFold(item.Assignee, assignee))
+	}
+	for _, item := range list.Items {
+		if ready(item) {
+			fmt.Println(list.Filtered(ready))
+			return
+		}
+	}
+	fmt.Println("No tasks ready")
+}
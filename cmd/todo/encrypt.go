@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+
+	"github.com/rahul4507/todo/internal/cryptstore"
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+// openJSON opens todos.json, which may have been encrypted with `todo encrypt`
+func openJSON() (todo.Store, error) {
+	encrypted, err := cryptstore.IsEncryptedFile(todoFile)
+	if err != nil {
+		return nil, err
+	}
+	if encrypted {
+		return &cryptstore.Store{Path: todoFile, Passphrase: passphrase(false)}, nil
+	}
+	return todo.FileStore{Path: todoFile}, nil
+}
+
+// passphrase returns a function that reads the passphrase from $TODO_PASSPHRASE,
+// the file named by $TODO_KEYFILE, or a prompt on the terminal. A new
+// passphrase is typed twice when prompting.
+func passphrase(confirm bool) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		if p := os.Getenv("TODO_PASSPHRASE"); p != "" {
+			return []byte(p), nil
+		}
+		if path := os.Getenv("TODO_KEYFILE"); path != "" {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("Reading keyfile: %w", err)
+			}
+			key := bytes.TrimRight(data, "\r\n")
+			if len(key) == 0 {
+				return nil, fmt.Errorf("Keyfile %s is empty", path)
+			}
+			return key, nil
+		}
+
+		fd := int(os.Stdin.Fd())
+		if !term.IsTerminal(fd) {
+			return nil, errors.New("No passphrase: set TODO_PASSPHRASE or TODO_KEYFILE")
+		}
+		fmt.Fprint(os.Stderr, "Passphrase: ")
+		p, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return nil, err
+		}
+		if len(p) == 0 {
+			return nil, errors.New("Passphrase cannot be empty")
+		}
+		if confirm {
+			fmt.Fprint(os.Stderr, "Repeat passphrase: ")
+			again, err := term.ReadPassword(fd)
+			fmt.Fprintln(os.Stderr)
+			if err != nil {
+				return nil, err
+			}
+			if !bytes.Equal(p, again) {
+				return nil, errors.New("Passphrases do not match")
+			}
+		}
+		return p, nil
+	}
+}
+
+// runEncrypt implements `todo encrypt`, converting todos.json to the encrypted format
+func runEncrypt(list *todo.List) {
+	if _, ok := baseStore.(todo.FileStore); !ok {
+		fmt.Printf("Error: Only %s can be encrypted, and it must not be encrypted already\n", todoFile)
+		os.Exit(1)
+	}
+	encrypted := &cryptstore.Store{Path: todoFile, Passphrase: passphrase(true)}
+	if err := encrypted.Save(list); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Encrypted %s (%d task(s))\n", todoFile, len(list.Items))
+}
+
+// runDecrypt implements `todo decrypt`, converting todos.json back to plain JSON
+func runDecrypt(list *todo.List) {
+	if _, ok := baseStore.(*cryptstore.Store); !ok {
+		fmt.Printf("Error: %s is not encrypted\n", todoFile)
+		os.Exit(1)
+	}
+	if err := (todo.FileStore{Path: todoFile}).Save(list); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Decrypted %s (%d task(s))\n", todoFile, len(list.Items))
+}
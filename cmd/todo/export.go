@@ -0,0 +1,213 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rahul4507/todo/internal/csvio"
+	"github.com/rahul4507/todo/internal/ical"
+	"github.com/rahul4507/todo/internal/markdown"
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+// csvFlags are the options shared by CSV export and import
+type csvFlags struct {
+	mapping    *string
+	delimiter  *string
+	dateFormat *string
+	tagSep     *string
+}
+
+func addCSVFlags(fs *flag.FlagSet) csvFlags {
+	return csvFlags{
+		mapping:    fs.String("map", "", `CSV column mapping, e.g. "Title=text,Due=due,Labels=tags"`),
+		delimiter:  fs.String("delimiter", ",", `CSV field delimiter ("tab" for tabs)`),
+		dateFormat: fs.String("date-format", "YYYY-MM-DD", "CSV date format (e.g. DD/MM/YYYY or a Go layout)"),
+		tagSep:     fs.String("tag-sep", ";", "Separator between tags in the CSV tags column"),
+	}
+}
+
+func (f csvFlags) options() (csvio.Options, error) {
+	opts := csvio.DefaultOptions()
+
+	if *f.mapping != "" {
+		columns, err := csvio.ParseMapping(*f.mapping)
+		if err != nil {
+			return opts, err
+		}
+		opts.Columns = columns
+	}
+
+	delimiter := *f.delimiter
+	if delimiter == "tab" || delimiter == `\t` {
+		delimiter = "\t"
+	}
+	if utf8.RuneCountInString(delimiter) != 1 {
+		return opts, fmt.Errorf("Delimiter must be a single character, got %q", delimiter)
+	}
+	opts.Delimiter, _ = utf8.DecodeRuneInString(delimiter)
+
+	opts.DateFormat = *f.dateFormat
+	if *f.tagSep == "" {
+		return opts, fmt.Errorf("Tag separator cannot be empty")
+	}
+	opts.TagSeparator = *f.tagSep
+	return opts, nil
+}
+
+// runExport implements `todo export`, writing the list to a file or stdout
+func runExport(list *todo.List, args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	formatFlag := fs.String("format", "ics", "Export format: ics, csv, md")
+	outputFlag := fs.String("o", "", "Write to this file instead of stdout")
+	groupFlag := fs.String("group", "none", "Markdown sections: none, tag, priority")
+	csvOpts := addCSVFlags(fs)
+	fs.Parse(args)
+
+	out := io.Writer(os.Stdout)
+	if *outputFlag != "" {
+		f, err := os.Create(*outputFlag)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	var err error
+	switch *formatFlag {
+	case "ics":
+		err = ical.Encode(out, list.Items)
+	case "csv":
+		opts, optErr := csvOpts.options()
+		if optErr != nil {
+			fmt.Println("Error:", optErr)
+			os.Exit(1)
+		}
+		err = csvio.Export(out, list.Items, opts)
+	case "md":
+		group, groupErr := markdown.ParseGroupBy(*groupFlag)
+		if groupErr != nil {
+			fmt.Println("Error:", groupErr)
+			os.Exit(1)
+		}
+		err = markdown.Render(out, list.Items, group)
+	default:
+		fmt.Println("Error: Unknown export format:", *formatFlag)
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error exporting todos:", err)
+		os.Exit(1)
+	}
+	if *outputFlag != "" {
+		fmt.Printf("Exported %d item(s) to %s\n", len(list.Items), *outputFlag)
+	}
+}
+
+// runImport implements `todo import`, adding items from a file (or stdin for "-")
+func runImport(list *todo.List, args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	formatFlag := fs.String("format", "ics", "Import format: ics, csv")
+	dryRunFlag := fs.Bool("dry-run", false, "Show what would be imported without saving")
+	csvOpts := addCSVFlags(fs)
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		fmt.Println("Error: Missing file to import")
+		fmt.Println("Usage: todo import --format <ics|csv> [flags] <file|->")
+		os.Exit(1)
+	}
+
+	in := io.Reader(os.Stdin)
+	if name := fs.Arg(0); name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	var added []todo.Item
+	var problems []string
+
+	switch *formatFlag {
+	case "ics":
+		items, err := ical.Decode(in)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error importing todos:", err)
+			os.Exit(1)
+		}
+		for _, item := range items {
+			if err := list.AddItem(item); err != nil {
+				problems = append(problems, fmt.Sprintf("%q: %v", item.Text, err))
+				continue
+			}
+			added = append(added, item)
+		}
+		list.Sort()
+
+	case "csv":
+		opts, err := csvOpts.options()
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		rows, rowErrs, err := csvio.Import(in, opts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error importing todos:", err)
+			os.Exit(1)
+		}
+		addedRows, addErrs := csvio.Add(list, rows)
+		for _, e := range append(rowErrs, addErrs...) {
+			problems = append(problems, e.Error())
+		}
+		for _, row := range addedRows {
+			added = append(added, row.Item)
+		}
+
+	default:
+		fmt.Println("Error: Unknown import format:", *formatFlag)
+		os.Exit(1)
+	}
+
+	for _, p := range problems {
+		fmt.Println("Skipped", p)
+	}
+
+	if *dryRunFlag {
+		fmt.Printf("Dry run: would import %d item(s), skip %d\n", len(added), len(problems))
+		for _, item := range added {
+			fmt.Println("  +", describeItem(item))
+		}
+		return
+	}
+
+	if len(added) > 0 {
+		saveTodos(list)
+	}
+	fmt.Printf("Imported %d item(s), skipped %d\n", len(added), len(problems))
+}
+
+// describeItem summarizes an item on one line for previews
+func describeItem(item todo.Item) string {
+	parts := []string{item.Text, "[" + item.Priority.String() + "]"}
+	if item.Done {
+		parts = append(parts, "(done)")
+	}
+	if item.DueDate != nil {
+		parts = append(parts, "due "+item.DueDate.Format("2006-01-02"))
+	}
+	if len(item.Tags) > 0 {
+		parts = append(parts, "tags: "+strings.Join(item.Tags, ", "))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/rahul4507/todo/api/todopb"
+	"github.com/rahul4507/todo/internal/grpcserver"
+	"github.com/rahul4507/todo/internal/rpc"
+)
+
+// runGRPC implements `todo grpc`, serving the gRPC API
+func runGRPC(args []string) {
+	fs := flag.NewFlagSet("grpc", flag.ExitOnError)
+	addrFlag := fs.String("addr", "localhost:9090", "Address to listen on")
+	watchFlag := fs.Duration("watch", time.Second, "How often to check the todo file for changes made elsewhere")
+	listFlag := fs.String("list", defaultListName(), "Name of the list, for tokens limited to some lists")
+	fs.Parse(args)
+	requirePositive("watch", *watchFlag)
+
+	server := grpcserver.New(&rpc.Service{Store: store, User: currentUser()})
+	go server.WatchFile(context.Background(), storeFile, *watchFlag, func(err error) {
+		fmt.Fprintln(os.Stderr, "Error watching todos:", err)
+	})
+
+	a := &grpcserver.Auth{Path: tokenFile(), List: *listFlag}
+	srv := grpc.NewServer(a.ServerOptions()...)
+	todopb.RegisterTodoServiceServer(srv, server)
+
+	warnWithoutTokens()
+
+	listener, err := net.Listen("tcp", *addrFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Serving gRPC for list %q on %s (Ctrl+C to stop)\n", *listFlag, listener.Addr())
+	if err := srv.Serve(listener); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
@@ -9,13 +10,24 @@ import (
 	"strings"
 	"time"
 
+	"github.com/rahul4507/todo/internal/completion"
+	"github.com/rahul4507/todo/internal/hooks"
 	"github.com/rahul4507/todo/internal/todo"
 )
 
 const (
 	todoFile = "todos.json"
+	logFile  = "todos.log"
+	dbFile   = "todos.db"
 )
 
+// store is where the todo list is loaded from and saved to. Every save goes
+// through the hooks and webhooks, whichever command or server makes it.
+var store todo.Store = todo.FileStore{Path: todoFile}
+
+// baseStore is the backend behind store, without the hooks and webhooks
+var baseStore todo.Store = store
+
 func main() {
 	//define flags
 	interactiveFlag := flag.Bool("i", false, "Run in interactive mode")
@@ -31,14 +43,46 @@ func main() {
 		return
 	}
 
+	// merge works on the files it is given, not on the store
+	if len(args) > 0 && args[0] == "merge" {
+		runMerge(args[1:])
+		return
+	}
+	// sync-server keeps its own state
+	if len(args) > 0 && args[0] == "sync-server" {
+		runSyncServer(args[1:])
+		return
+	}
+	if len(args) > 0 && args[0] == "token" {
+		runToken(args[1:])
+		return
+	}
+	if len(args) > 0 && args[0] == "completion" {
+		runCompletion(args[1:])
+		return
+	}
+
+	if err := openStore(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+
+	// Stores with indexes answer queries without loading the whole list
+	if q, ok := baseStore.(todo.Querier); ok && !*interactiveFlag && len(args) > 0 && runQuery(q, args) {
+		return
+	}
+	// and ones that change single items make changes without rewriting it
+	if m, ok := store.(todo.Mutator); ok && !*interactiveFlag && len(args) > 0 && runMutation(m, args) {
+		return
+	}
+
 	// Load Existing todos
-	todoList := todo.NewList()
-	if _, err := os.Stat(todoFile); err == nil {
-		if err := todoList.Load(todoFile); err != nil {
-			fmt.Fprintln(os.Stderr, "Error Loading todos: ", err)
-			os.Exit(1)
-		}
+	todoList, err := store.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error Loading todos: ", err)
+		os.Exit(1)
 	}
+	todoList.User = currentUser()
 
 	//Handle interactive mode
 	if *interactiveFlag {
@@ -48,7 +92,7 @@ func main() {
 
 	if len(args) == 0 {
 		// default action print the todo list
-		fmt.Println(todoList)
+		runList(todoList, nil)
 		return
 	}
 
@@ -71,7 +115,7 @@ func main() {
 		fmt.Println("Added:", text)
 
 	case "list":
-		fmt.Println(todoList)
+		runList(todoList, args[1:])
 
 	case "complete":
 		if len(args) < 2 {
@@ -160,9 +204,25 @@ func main() {
 		fmt.Printf("Cleared %d completed item(s)\n", count)
 
 	case "stats":
-		stats := todoList.GetStats()
-		fmt.Printf("Total: %d | Pending: %d | Completed: %d\n",
-			stats.Total, stats.Pending, stats.Completed)
+		fs := flag.NewFlagSet("stats", flag.ExitOnError)
+		byAssignee := fs.Bool("by-assignee", false, "Also show statistics per assignee")
+		fs.Parse(args[1:])
+		printStats(todoList.GetStats())
+		if *byAssignee {
+			printUserStats(todoList.StatsByAssignee())
+		}
+
+	case "assign":
+		runAssign(todoList, args[1:])
+
+	case "whoami":
+		runWhoami(args[1:])
+
+	case "context":
+		runContext(todoList, args[1:])
+
+	case "ready":
+		runReady(todoList, args[1:])
 
 	case "priority":
 		if len(args) < 3 {
@@ -257,39 +317,163 @@ func main() {
 		saveTodos(todoList)
 		fmt.Printf("Removed tag: %s\n", tag)
 
-	case "search":
-		if len(args) < 2 {
-			fmt.Println("Error: Missing search query")
+	case "estimate":
+		if len(args) < 3 {
+			fmt.Println("Error: Missing item number or estimate")
+			fmt.Println("Usage: todo estimate <n> <30m|2h|3pt|none>")
+			os.Exit(1)
+		}
+
+		num, err := strconv.Atoi(args[1])
+		if err != nil {
+			fmt.Println("Error: Invalid item number:", args[1])
 			os.Exit(1)
 		}
 
-		query := strings.Join(args[1:], " ")
-		results := todoList.Search(query)
+		var estimate todo.Estimate
+		if args[2] != "none" {
+			estimate, err = todo.ParseEstimate(args[2])
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+		}
+
+		if err := todoList.SetEstimate(num-1, estimate); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
 
-		if len(results) == 0 {
-			fmt.Println("No items found")
+		saveTodos(todoList)
+		if estimate.IsZero() {
+			fmt.Println("Cleared estimate")
 		} else {
-			fmt.Printf("Found %d item(s):\n", len(results))
-			for i, item := range results {
-				status := " "
-				if item.Done {
-					status = "✓"
+			fmt.Printf("Set estimate to %s\n", estimate)
+		}
+
+	case "track":
+		if len(args) < 3 {
+			fmt.Println("Error: Missing item number or time spent")
+			fmt.Println("Usage: todo track <n> <duration>")
+			os.Exit(1)
+		}
+
+		num, err := strconv.Atoi(args[1])
+		if err != nil {
+			fmt.Println("Error: Invalid item number:", args[1])
+			os.Exit(1)
+		}
+
+		spent, err := time.ParseDuration(args[2])
+		if err != nil {
+			fmt.Println("Error: Invalid duration. Use e.g. 45m or 1h30m")
+			os.Exit(1)
+		}
+
+		if err := todoList.LogTime(num-1, spent); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+
+		saveTodos(todoList)
+		fmt.Printf("Tracked %s\n", todo.FormatDuration(spent))
+
+	case "report":
+		report := todoList.EstimateReport()
+		if len(report) == 0 {
+			fmt.Println("No estimated or tracked items")
+			break
+		}
+
+		fmt.Printf("%-16s %6s %10s %10s %9s %10s\n",
+			"Tag", "Items", "Estimated", "Tracked", "Accuracy", "h/point")
+		for _, row := range report {
+			estimated := "-"
+			if row.EstimatedTime > 0 {
+				estimated = todo.FormatDuration(row.EstimatedTime)
+			}
+			if row.EstimatedPoints > 0 {
+				points := todo.Estimate{Points: row.EstimatedPoints}.String()
+				if estimated == "-" {
+					estimated = points
+				} else {
+					estimated += "+" + points
 				}
-				fmt.Printf("%d. [%s] %s\n", i+1, status, item.Text)
 			}
+
+			accuracy := "-"
+			if row.EstimatedTime > 0 {
+				accuracy = fmt.Sprintf("%.0f%%", row.Accuracy()*100)
+			}
+			perPoint := "-"
+			if row.EstimatedPoints > 0 {
+				perPoint = fmt.Sprintf("%.1f", row.HoursPerPoint())
+			}
+
+			fmt.Printf("%-16s %6d %10s %10s %9s %10s\n", row.Tag, row.Items,
+				estimated, todo.FormatDuration(row.Tracked), accuracy, perPoint)
 		}
 
+	case "search":
+		search := parseSearch(args[1:])
+		results := todoList.Filter(search.filter)
+		if search.assigned != nil {
+			results = search.assigned(results)
+		}
+		printSearchResults(results)
+
 	case "overdue":
+		_, filter := parseAssigneeFlags("overdue", args[1:])
 		results := todoList.GetOverdue()
-		if len(results) == 0 {
-			fmt.Println("No overdue items")
-		} else {
-			fmt.Printf("Overdue items (%d):\n", len(results))
-			for i, item := range results {
-				dueStr := item.DueDate.Format("2006-01-02")
-				fmt.Printf("%d. %s (Due: %s)\n", i+1, item.Text, dueStr)
-			}
+		if filter != nil {
+			results = filter(results)
 		}
+		printOverdue(results)
+
+	case "remind":
+		runRemind(args[1:])
+
+	case "caldav":
+		runCalDAV(args[1:])
+
+	case "serve":
+		runServe(args[1:])
+
+	case "rpc":
+		runRPC(args[1:])
+
+	case "mcp":
+		runMCP(args[1:])
+
+	case "grpc":
+		runGRPC(args[1:])
+
+	case "webhooks":
+		runWebhooks(args[1:])
+
+	case "export":
+		runExport(todoList, args[1:])
+
+	case "import":
+		runImport(todoList, args[1:])
+
+	case "sync-md":
+		runSyncMD(todoList, args[1:])
+
+	case "migrate":
+		runMigrate(todoList, args[1:])
+
+	case "sync":
+		runSync(todoList, args[1:])
+
+	case "encrypt":
+		runEncrypt(todoList)
+
+	case "decrypt":
+		runDecrypt(todoList)
+
+	case completion.Command:
+		runComplete(todoList, args[1:])
 
 	case "help":
 		printHelp()
@@ -303,12 +487,139 @@ func main() {
 }
 
 func saveTodos(list *todo.List) {
-	if err := list.Save(todoFile); err != nil {
-		fmt.Fprintln(os.Stderr, "Error saving todos: ", err)
+	if err := store.Save(list); err != nil {
+		reportSaveError(err)
+		os.Exit(1)
+	}
+}
+
+// saveOrRevert saves a change made in interactive mode. If the save fails,
+// e.g. because a hook rejected the change, it reports why and reloads the
+// list, discarding the change.
+func saveOrRevert(list *todo.List) bool {
+	err := store.Save(list)
+	if err == nil {
+		return true
+	}
+	reportSaveError(err)
+	saved, err := store.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error Loading todos: ", err)
+		os.Exit(1)
+	}
+	list.Items = saved.Items
+	return false
+}
+
+func reportSaveError(err error) {
+	var veto *hooks.VetoError
+	if errors.As(err, &veto) {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Fprintln(os.Stderr, "Error saving todos: ", err)
+}
+
+// requirePositive exits with an error unless a duration flag is above zero
+func requirePositive(name string, d time.Duration) {
+	if d <= 0 {
+		fmt.Printf("Error: -%s must be positive, got %s\n", name, d)
 		os.Exit(1)
 	}
 }
 
+func printStats(stats todo.Stats) {
+	fmt.Printf("Total: %d | Pending: %d | Completed: %d\n",
+		stats.Total, stats.Pending, stats.Completed)
+	if remaining := formatRemaining(stats); remaining != "" {
+		fmt.Println("Remaining estimate:", remaining)
+	}
+}
+
+func printSearchResults(results []todo.Item) {
+	if len(results) == 0 {
+		fmt.Println("No items found")
+		return
+	}
+	fmt.Printf("Found %d item(s):\n", len(results))
+	for i, item := range results {
+		status := " "
+		if item.Done {
+			status = "✓"
+		}
+		fmt.Printf("%d. [%s] %s\n", i+1, status, item.Text)
+	}
+}
+
+// searchArgs are the query and filters of `todo search`
+type searchArgs struct {
+	filter   todo.Filter
+	assigned func([]todo.Item) []todo.Item
+}
+
+// parseSearch parses `todo search [--tag <tag>] [--priority <level>]
+// [--mine | --assignee <user>] [query]`; a query or a tag or priority is needed
+func parseSearch(args []string) searchArgs {
+	fs := flag.NewFlagSet("search", flag.ExitOnError)
+	tag := fs.String("tag", "", "Only tasks with this tag")
+	priority := fs.String("priority", "", "Only tasks of this priority")
+	flags := addAssigneeFlags(fs)
+	fs.Parse(args)
+
+	search := searchArgs{filter: todo.Filter{
+		Query: strings.Join(fs.Args(), " "),
+		Tag:   *tag,
+	}}
+	if *priority != "" {
+		level, ok := todo.LookupPriority(*priority)
+		if !ok {
+			fmt.Println("Error: Invalid priority:", *priority)
+			os.Exit(1)
+		}
+		search.filter.Priority = &level
+	}
+	if search.filter.Query == "" && search.filter.Tag == "" && search.filter.Priority == nil {
+		fmt.Println("Error: Missing search query")
+		os.Exit(1)
+	}
+
+	assignee, ok, err := flags.user()
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	if ok {
+		search.assigned = func(items []todo.Item) []todo.Item {
+			return todo.AssignedTo(items, assignee)
+		}
+	}
+	return search
+}
+
+func printOverdue(results []todo.Item) {
+	if len(results) == 0 {
+		fmt.Println("No overdue items")
+		return
+	}
+	fmt.Printf("Overdue items (%d):\n", len(results))
+	for i, item := range results {
+		dueStr := item.DueDate.Format("2006-01-02")
+		fmt.Printf("%d. %s (Due: %s)\n", i+1, item.Text, dueStr)
+	}
+}
+
+// formatRemaining describes the remaining estimated effort, or "" when nothing is estimated
+func formatRemaining(stats todo.Stats) string {
+	var parts []string
+	if stats.EstimatedTime > 0 {
+		parts = append(parts, todo.FormatDuration(stats.EstimatedTime))
+	}
+	if stats.EstimatedPoints > 0 {
+		parts = append(parts, todo.Estimate{Points: stats.EstimatedPoints}.String())
+	}
+	return strings.Join(parts, " + ")
+}
+
 func printHelp() {
 	helpText := `
 Todo - A powerful command line todo manager
@@ -319,22 +630,68 @@ Usage:
 
 Commands:
   add <text>              Add a new todo item
-  list                    List all todo items
+  list [--mine] [--all]   List todo items, or those assigned to you
+                          (--assignee <user> for someone else's); tasks whose
+                          context isn't active are hidden unless --all
+  ready [--mine]          List pending tasks whose context is active
   complete <n>            Mark item n as completed
   uncomplete <n>          Mark item n as incomplete
   delete <n>              Delete item n
   edit <n> <text>         Edit the text of item n
   clear                   Remove all completed items
-  stats                   Show statistics
+  stats [--by-assignee]   Show statistics, optionally per assignee
 
   priority <n> <level>    Set priority (high/medium/low)
   due <n> <YYYY-MM-DD>    Set due date
   tag <n> <tag>           Add a tag to item
   untag <n> <tag>         Remove a tag from item
-
-  search <query>          Search tasks by text or tag
-  overdue                 Show overdue tasks
-
+  estimate <n> <effort>   Set estimate (30m, 2h, 3pt or none)
+  track <n> <duration>    Log time spent on item
+  report                  Compare estimates with tracked time per tag
+  assign <n> <user>       Assign item to a user (me for yourself, none to unassign)
+  whoami [name]           Show or set who you are
+  context [cmd]           Manage contexts: list, add [-hours 09:00-17:00]
+                          [-days mon-fri] [-location place] <name>,
+                          remove <name>, use <name|location>... or none,
+                          set <n> <name|none>
+
+  search <query>          Search tasks by text or tag (--tag <tag>,
+                          --priority <level>, --mine, --assignee <user>)
+  overdue                 Show overdue tasks (--mine, --assignee <user>)
+  remind [flags]          Run the reminder daemon (see 'todo remind -h')
+  caldav [flags]          Serve tasks to CalDAV clients
+  serve [-addr host:port] Serve the web UI (default localhost:8080)
+  rpc                     Serve JSON-RPC 2.0 on stdin/stdout for editor plugins
+  mcp                     Serve the Model Context Protocol on stdin/stdout for
+                          AI assistants
+  grpc [-addr host:port]  Serve the gRPC API (default localhost:9090)
+  token [cmd]             Manage API tokens for serve and grpc: list,
+                          revoke <id>, create [-scope read|write|admin]
+                          [-list names]
+  webhooks [cmd]          Manage webhooks: list, add <url>, remove <url>,
+                          test [url], flush
+
+  export --format <fmt>   Export tasks as ics, csv or md (stdout, or a file with -o)
+  import --format <fmt> <f>
+                          Import tasks from file f (- for stdin); csv supports
+                          --map, --delimiter, --date-format, --tag-sep, --dry-run
+  sync-md <file>          Sync the checklist in a Markdown file both ways
+  sync [-remote <url>]    Sync the list with other machines through git
+  sync -peer <url>        Exchange changes with a peer over HTTP
+  sync -listen <addr>     Serve peer exchanges
+  sync -server <url> -token <t>
+                          Sync through a sync server (remembered afterwards)
+  sync-server [cmd]       Run a sync server for several devices, or manage
+                          its devices: add-device <name>, remove-device <name>,
+                          devices
+  merge <base> <ours> <theirs>
+                          Merge two edited copies of a todo file into ours;
+                          usable as a git merge driver
+  migrate --to <store>    Move the list to another store (json, log or sqlite)
+  encrypt                 Encrypt todos.json with a passphrase
+  decrypt                 Turn an encrypted todos.json back into plain JSON
+
+  completion <shell>      Print a completion script for bash, zsh or fish
   help                    Show this help message
 
 Flags:
@@ -348,8 +705,28 @@ Examples:
   todo priority 1 high
   todo due 1 2025-12-31
   todo tag 1 work
+  todo estimate 1 2h
+  todo track 1 45m
+  todo assign 1 alice
+  todo list --mine
+  todo context add -hours 09:00-17:00 -days mon-fri -location work office
+  todo context set 1 office
+  todo context use home
+  todo ready
   todo search "go"
   todo overdue
+  todo remind -offsets 1d,1h -notify stdout,command -command notify-send
+  todo export --format ics -o todos.ics
+  todo import --format csv --map "Title=text,Due=due,Labels=tags" --dry-run tasks.csv
+  todo export --format md --group tag -o TODO.md
+  todo sync-md README.md
+  todo migrate --to log
+  todo sync -remote git@example.com:me/todos.git
+  todo sync -peer http://laptop.local:8090/
+  todo sync-server add-device phone
+  todo token create -name phone -scope write
+  todo webhooks add -secret s3cret -events added,completed https://bot.example.com/todo
+  source <(todo completion bash)
   todo -i
 
 Priority Levels:
@@ -357,11 +734,25 @@ Priority Levels:
   🟡 medium  - Normal priority (default)
   🟢 low     - Nice to have
 
+Storage:
+  Tasks are kept in todos.json, or in todos.db (SQLite) or todos.log (an
+  append-only log with history) when one exists. Set TODO_STORE=json|log|sqlite
+  to choose explicitly. An encrypted todos.json takes its passphrase from
+  TODO_PASSPHRASE, the file named by TODO_KEYFILE, or a prompt.
+
+Contexts:
+  A task's context (office, phone, ...) says where or when it can be done.
+  Contexts may be limited to hours and days and may belong to a location;
+  'todo context use' says where you are (TODO_CONTEXT overrides it), and
+  contexts at location anywhere are active wherever you are.
+
 Symbols:
   [✓] - Completed task
   [ ] - Pending task
   📅 - Due date
+  ⏱️  - Estimate
   🏷️  - Tags
+  📍 - Context
 `
 	fmt.Println(helpText)
 }
@@ -374,6 +765,9 @@ func runInteractive(list *todo.List) {
 		stats := list.GetStats()
 		fmt.Printf("\nStats: Total: %d | Pending: %d | Completed: %d\n",
 			stats.Total, stats.Pending, stats.Completed)
+		if remaining := formatRemaining(stats); remaining != "" {
+			fmt.Println("Remaining estimate:", remaining)
+		}
 		fmt.Println("\nCommands: add, complete, uncomplete, delete, edit, clear, help, quit")
 		fmt.Print("\n> ")
 
@@ -400,7 +794,9 @@ func runInteractive(list *todo.List) {
 				fmt.Println(err)
 				continue
 			}
-			saveTodos(list)
+			if !saveOrRevert(list) {
+				continue
+			}
 			fmt.Println("Added:", text)
 
 		case "complete":
@@ -417,7 +813,9 @@ func runInteractive(list *todo.List) {
 				fmt.Println("Error:", err)
 				continue
 			}
-			saveTodos(list)
+			if !saveOrRevert(list) {
+				continue
+			}
 			fmt.Println("Marked item as completed")
 
 		case "uncomplete":
@@ -434,7 +832,9 @@ func runInteractive(list *todo.List) {
 				fmt.Println("Error:", err)
 				continue
 			}
-			saveTodos(list)
+			if !saveOrRevert(list) {
+				continue
+			}
 			fmt.Println("Marked item as incomplete")
 
 		case "delete", "remove":
@@ -451,7 +851,9 @@ func runInteractive(list *todo.List) {
 				fmt.Println("Error:", err)
 				continue
 			}
-			saveTodos(list)
+			if !saveOrRevert(list) {
+				continue
+			}
 			fmt.Println("Deleted item")
 
 		case "edit":
@@ -469,18 +871,25 @@ func runInteractive(list *todo.List) {
 				fmt.Println("Error:", err)
 				continue
 			}
-			saveTodos(list)
+			if !saveOrRevert(list) {
+				continue
+			}
 			fmt.Println("Updated item")
 
 		case "clear":
 			count := list.ClearCompleted()
-			saveTodos(list)
+			if !saveOrRevert(list) {
+				continue
+			}
 			fmt.Printf("Cleared %d completed item(s)\n", count)
 
 		case "stats":
 			s := list.GetStats()
 			fmt.Printf("Total: %d | Pending: %d | Completed: %d\n",
 				s.Total, s.Pending, s.Completed)
+			if remaining := formatRemaining(s); remaining != "" {
+				fmt.Println("Remaining estimate:", remaining)
+			}
 
 		case "list":
 			fmt.Println(list)
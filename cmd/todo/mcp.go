@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"time"
+
+	"github.com/rahul4507/todo/internal/mcp"
+	"github.com/rahul4507/todo/internal/rpc"
+)
+
+// runMCP implements `todo mcp`, serving the Model Context Protocol over
+// stdin and stdout for AI assistants
+func runMCP(args []string) {
+	fs := flag.NewFlagSet("mcp", flag.ExitOnError)
+	watchFlag := fs.Duration("watch", time.Second, "How often to check the todo file for changes made elsewhere")
+	fs.Parse(args)
+	requirePositive("watch", *watchFlag)
+
+	service := &rpc.Service{Store: store, User: currentUser()}
+	server := mcp.NewServer(service, buildVersion())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go service.Watch(ctx, storeFile, *watchFlag, func(string) {
+		server.Changed()
+	}, func(err error) {
+		fmt.Fprintln(os.Stderr, "Error watching todos:", err)
+	})
+
+	// Stdout carries the protocol, so errors go to stderr
+	if err := server.Serve(ctx, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+// buildVersion is the module version the binary was built from
+func buildVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "dev"
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+// runMerge implements `todo merge base ours theirs`, a three-way merge of todo
+// files. Like a git merge driver it writes the result over ours and exits
+// with status 1 when there are conflicts.
+func runMerge(args []string) {
+	fs := flag.NewFlagSet("merge", flag.ExitOnError)
+	outFlag := fs.String("o", "", "Write the result to this file instead of ours")
+	fs.Parse(args)
+	if fs.NArg() != 3 {
+		fmt.Println("Error: Expected three files")
+		fmt.Println("Usage: todo merge [-o file] <base> <ours> <theirs>")
+		os.Exit(1)
+	}
+
+	var lists [3]*todo.List
+	for i, path := range fs.Args() {
+		list, err := readListFile(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(2)
+		}
+		lists[i] = list
+	}
+
+	merged, conflicts := todo.Merge(lists[0], lists[1], lists[2])
+	out := *outFlag
+	if out == "" {
+		out = fs.Arg(1)
+	}
+	if err := merged.Save(out); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(2)
+	}
+
+	for _, c := range conflicts {
+		fmt.Fprintln(os.Stderr, "Conflict:", c)
+	}
+	if len(conflicts) > 0 {
+		os.Exit(1)
+	}
+}
+
+// readListFile reads a todo file. An empty file, as git passes for a missing
+// base, is an empty list.
+func readListFile(path string) (*todo.List, error) {
+	list := todo.NewList()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return list, nil
+	}
+	if err := json.Unmarshal(data, list); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return list, nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+// runMigrate implements `todo migrate`, moving the list to another storage
+// backend. The old files are kept with a .bak suffix.
+func runMigrate(list *todo.List, args []string) {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	toFlag := fs.String("to", "", "Backend to move the list to: "+backendNames())
+	fs.Parse(args)
+
+	from, err := detectBackend()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	to, ok := backends[*toFlag]
+	if !ok {
+		fmt.Println("Error: Unknown or missing backend:", *toFlag)
+		fmt.Println("Usage: todo migrate --to " + backendNames())
+		os.Exit(1)
+	}
+	if *toFlag == from {
+		fmt.Printf("Already using the %s store\n", from)
+		return
+	}
+	for _, file := range to.files {
+		if _, err := os.Stat(file); err == nil {
+			fmt.Printf("Error: %s already exists; move it away first\n", file)
+			os.Exit(1)
+		}
+	}
+
+	target, err := to.open()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	if err := target.Save(list); err != nil {
+		fmt.Fprintln(os.Stderr, "Error saving todos:", err)
+		os.Exit(1)
+	}
+	for _, file := range backends[from].files {
+		if err := os.Rename(file, file+".bak"); err != nil && !errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+	}
+	fmt.Printf("Migrated %d task(s) from %s to %s (old files kept as .bak)\n", len(list.Items), backends[from].path, to.path)
+	if env := os.Getenv("TODO_STORE"); env != "" {
+		fmt.Printf("Note: TODO_STORE=%s still selects the old store; set it to %s\n", env, *toFlag)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/rahul4507/todo/internal/remind"
+)
+
+const (
+	remindersFile = "reminders.json"
+)
+
+// runRemind implements `todo remind`, a daemon that sends reminders before due dates
+func runRemind(args []string) {
+	fs := flag.NewFlagSet("remind", flag.ExitOnError)
+	offsetsFlag := fs.String("offsets", "1d,1h", "When to remind before the due date (e.g. 1d,2h,30m)")
+	notifyFlag := fs.String("notify", "stdout", "Comma separated notifiers: stdout, command, smtp")
+	intervalFlag := fs.Duration("interval", time.Minute, "How often to check for reminders")
+	maxLateFlag := fs.Duration("max-late", 24*time.Hour, "Still send reminders missed by up to this long")
+	onceFlag := fs.Bool("once", false, "Check once and exit instead of running as a daemon")
+	commandFlag := fs.String("command", "", "Command to run for the command notifier")
+	commandTimeoutFlag := fs.Duration("command-timeout", 30*time.Second, "Timeout for the reminder command")
+	smtpAddrFlag := fs.String("smtp-addr", "", "SMTP server host:port for the smtp notifier")
+	smtpFromFlag := fs.String("smtp-from", "", "Sender address for reminder emails")
+	smtpToFlag := fs.String("smtp-to", "", "Comma separated recipients of reminder emails")
+	smtpUserFlag := fs.String("smtp-user", "", "SMTP username (password is read from TODO_SMTP_PASSWORD)")
+	fs.Parse(args)
+	requirePositive("interval", *intervalFlag)
+
+	offsets, err := remind.ParseOffsets(*offsetsFlag)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
+	var notifiers []remind.Notifier
+	for _, name := range strings.Split(*notifyFlag, ",") {
+		switch strings.TrimSpace(name) {
+		case "stdout":
+			notifiers = append(notifiers, remind.WriterNotifier{W: os.Stdout})
+
+		case "command":
+			fields := strings.Fields(*commandFlag)
+			if len(fields) == 0 {
+				fmt.Println("Error: The command notifier needs -command")
+				os.Exit(1)
+			}
+			notifiers = append(notifiers, remind.CommandNotifier{
+				Command: fields[0],
+				Args:    fields[1:],
+				Timeout: *commandTimeoutFlag,
+			})
+
+		case "smtp":
+			if *smtpAddrFlag == "" || *smtpFromFlag == "" || *smtpToFlag == "" {
+				fmt.Println("Error: The smtp notifier needs -smtp-addr, -smtp-from and -smtp-to")
+				os.Exit(1)
+			}
+			n := remind.SMTPNotifier{
+				Addr: *smtpAddrFlag,
+				From: *smtpFromFlag,
+				To:   strings.Split(*smtpToFlag, ","),
+			}
+			if *smtpUserFlag != "" {
+				host, _, err := net.SplitHostPort(*smtpAddrFlag)
+				if err != nil {
+					fmt.Println("Error: Invalid SMTP address:", *smtpAddrFlag)
+					os.Exit(1)
+				}
+				n.Auth = smtp.PlainAuth("", *smtpUserFlag, os.Getenv("TODO_SMTP_PASSWORD"), host)
+			}
+			notifiers = append(notifiers, n)
+
+		default:
+			fmt.Println("Error: Unknown notifier:", name)
+			os.Exit(1)
+		}
+	}
+
+	state, err := remind.LoadState(remindersFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+
+	daemon := &remind.Daemon{
+		Load:      store.Load,
+		Offsets:   offsets,
+		Notifiers: notifiers,
+		State:     state,
+		MaxLate:   *maxLateFlag,
+		Interval:  *intervalFlag,
+	}
+
+	if *onceFlag {
+		sent, err := daemon.Check(time.Now())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Sent %d reminder(s)\n", sent)
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Printf("Watching %s for reminders (every %s, Ctrl+C to stop)\n", storeFile, *intervalFlag)
+	daemon.Run(ctx, func(err error) {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+	})
+}
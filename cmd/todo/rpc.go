@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rahul4507/todo/internal/jsonrpc"
+	"github.com/rahul4507/todo/internal/rpc"
+)
+
+// runRPC implements `todo rpc`, serving JSON-RPC over stdin and stdout
+func runRPC(args []string) {
+	fs := flag.NewFlagSet("rpc", flag.ExitOnError)
+	watchFlag := fs.Duration("watch", time.Second, "How often to check the todo file for changes made elsewhere")
+	fs.Parse(args)
+	requirePositive("watch", *watchFlag)
+
+	service := &rpc.Service{Store: store, User: currentUser()}
+	srv := jsonrpc.NewServer()
+	service.Register(srv)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go service.Watch(ctx, storeFile, *watchFlag, func(version string) {
+		srv.Notify(rpc.ChangedMethod, rpc.ChangedParams{Version: version})
+	}, func(err error) {
+		fmt.Fprintln(os.Stderr, "Error watching todos:", err)
+	})
+
+	// Stdout carries the protocol, so errors go to stderr
+	if err := srv.Serve(ctx, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rahul4507/todo/internal/auth"
+	"github.com/rahul4507/todo/internal/web"
+)
+
+// runServe implements `todo serve`, serving the web UI and its JSON API
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addrFlag := fs.String("addr", "localhost:8080", "Address to listen on")
+	watchFlag := fs.Duration("watch", time.Second, "How often to check the todo file for changes made elsewhere")
+	listFlag := fs.String("list", defaultListName(), "Name of the list, for tokens limited to some lists")
+	fs.Parse(args)
+	requirePositive("watch", *watchFlag)
+
+	handler := web.NewHandler(store)
+	go handler.Watch(context.Background(), storeFile, *watchFlag, func(err error) {
+		fmt.Fprintln(os.Stderr, "Error watching todos:", err)
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle(auth.AdminPrefix, auth.Handler(tokenFile()))
+	mux.Handle(auth.AdminPrefix+"/", auth.Handler(tokenFile()))
+	mux.Handle("/", handler)
+	guard := &auth.Guard{
+		Path: tokenFile(),
+		List: *listFlag,
+		// The UI itself holds no tasks; it asks for a token when the API needs one
+		Public: func(r *http.Request) bool {
+			return !strings.HasPrefix(r.URL.Path, "/api/")
+		},
+	}
+
+	warnWithoutTokens()
+
+	fmt.Printf("Serving web UI for list %q on http://%s/ (Ctrl+C to stop)\n", *listFlag, *addrFlag)
+	if err := http.ListenAndServe(*addrFlag, guard.Wrap(mux)); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+// warnWithoutTokens warns that a server is open to anyone until an API
+// token is created
+func warnWithoutTokens() {
+	if tokens, err := auth.Load(tokenFile()); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	} else if len(tokens.Tokens) == 0 {
+		fmt.Fprintln(os.Stderr, "Warning: No API tokens, so anyone who can reach the server can change the list; create one with 'todo token create'")
+	}
+}
+
+// defaultListName names the list after the directory it is in
+func defaultListName() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "todo"
+	}
+	return filepath.Base(dir)
+}
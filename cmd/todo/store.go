@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rahul4507/todo/internal/hooks"
+	"github.com/rahul4507/todo/internal/sqlstore"
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+// backend is a way of storing the todo list
+type backend struct {
+	// path is the main file; serve watches it for changes
+	path  string
+	open  func() (todo.Store, error)
+	files []string
+}
+
+var backends = map[string]backend{
+	"json": {
+		path:  todoFile,
+		open:  openJSON,
+		files: []string{todoFile},
+	},
+	"log": {
+		path:  logFile,
+		open:  func() (todo.Store, error) { return todo.LogStore{Path: logFile}, nil },
+		files: []string{logFile, todo.LogStore{Path: logFile}.SnapshotPath()},
+	},
+	"sqlite": {
+		path:  dbFile,
+		open:  func() (todo.Store, error) { return sqlstore.Open(dbFile) },
+		files: []string{dbFile, dbFile + "-journal"},
+	},
+}
+
+// storeFile is the main file of the store in use
+var storeFile = todoFile
+
+// detectBackend picks the backend from $TODO_STORE, or from the files present
+func detectBackend() (string, error) {
+	if name := os.Getenv("TODO_STORE"); name != "" {
+		if _, ok := backends[name]; !ok {
+			return "", fmt.Errorf("Unknown store %q in TODO_STORE (use %s)", name, backendNames())
+		}
+		return name, nil
+	}
+	if _, err := os.Stat(dbFile); err == nil {
+		return "sqlite", nil
+	}
+	if _, err := os.Stat(logFile); err == nil {
+		return "log", nil
+	}
+	return "json", nil
+}
+
+// openStore sets store and storeFile to the backend in use
+func openStore() error {
+	name, err := detectBackend()
+	if err != nil {
+		return err
+	}
+	baseStore, err = backends[name].open()
+	if err != nil {
+		return err
+	}
+	store = todo.Observe(baseStore,
+		hooks.Observer{Runner: hooks.Runner{
+			Dir:      filepath.Join(configDir(), "hooks"),
+			Feedback: os.Stderr,
+		}},
+		webhookNotifier(),
+	)
+	storeFile = backends[name].path
+	return nil
+}
+
+func backendNames() string {
+	var names []string
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Sprint(names)
+}
+
+// runQuery answers search, overdue and stats straight from a store that
+// supports queries, without loading the whole list. It reports whether the
+// command was handled.
+func runQuery(q todo.Querier, args []string) bool {
+	var err error
+	switch args[0] {
+	case "search":
+		search := parseSearch(args[1:])
+		// The store answers one part of the search; the rest narrows its results
+		var results []todo.Item
+		rest := search.filter
+		switch {
+		case rest.Query != "":
+			results, err = q.Search(rest.Query)
+			rest.Query = ""
+		case rest.Tag != "":
+			results, err = q.FilterByTag(rest.Tag)
+			rest.Tag = ""
+		default:
+			results, err = q.FilterByPriority(*rest.Priority)
+			rest.Priority = nil
+		}
+		if err == nil {
+			results = (&todo.List{Items: results}).Filter(rest)
+			if search.assigned != nil {
+				results = search.assigned(results)
+			}
+			printSearchResults(results)
+		}
+	case "overdue":
+		_, filter := parseAssigneeFlags("overdue", args[1:])
+		var results []todo.Item
+		if results, err = q.Overdue(time.Now()); err == nil {
+			if filter != nil {
+				results = filter(results)
+			}
+			printOverdue(results)
+		}
+	case "stats":
+		// Per-assignee statistics need the whole list
+		if len(args) > 1 {
+			return false
+		}
+		var stats todo.Stats
+		if stats, err = q.Stats(); err == nil {
+			printStats(stats)
+		}
+	default:
+		return false
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	return true
+}
+
+// runMutation adds and completes items straight in a store that changes single
+// items, without loading and saving the whole list. It reports whether the
+// command was handled.
+func runMutation(m todo.Mutator, args []string) bool {
+	if len(args) < 2 {
+		return false
+	}
+	switch args[0] {
+	case "add":
+		text := strings.Join(args[1:], " ")
+		changes, err := recordChanges(nil, func(list *todo.List) error {
+			list.User = currentUser()
+			return list.Add(text)
+		})
+		if err == nil {
+			err = m.Apply(changes)
+		}
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		fmt.Println("Added:", text)
+
+	case "complete":
+		num, err := strconv.Atoi(args[1])
+		if err != nil {
+			return false
+		}
+		item, err := m.ItemAt(num - 1)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error completing todo:", err)
+			os.Exit(1)
+		}
+		changes, err := recordChanges([]todo.Item{item}, func(list *todo.List) error {
+			return list.Complete(0)
+		})
+		if err == nil {
+			err = m.Apply(changes)
+		}
+		if err != nil {
+			reportSaveError(err)
+			os.Exit(1)
+		}
+		fmt.Println("Marked item as completed")
+
+	default:
+		return false
+	}
+	return true
+}
+
+// recordChanges makes change to a list of only items, returning the events
+// it emits, so the change is made just as the list would make it
+func recordChanges(items []todo.Item, change func(*todo.List) error) ([]todo.Event, error) {
+	list := todo.NewList()
+	list.Items = items
+	var changes []todo.Event
+	list.Subscribe(func(e todo.Event) { changes = append(changes, e) })
+	if err := change(list); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
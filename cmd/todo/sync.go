@@ -0,0 +1,206 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rahul4507/todo/internal/auth"
+	"github.com/rahul4507/todo/internal/crdt"
+	"github.com/rahul4507/todo/internal/cryptstore"
+	"github.com/rahul4507/todo/internal/gitsync"
+	"github.com/rahul4507/todo/internal/syncserver"
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+// syncDir is the git repository `todo sync` keeps the list in
+const syncDir = "todo-sync"
+
+// replicaFile holds the replica state for `todo sync -peer`
+const replicaFile = "replica.json"
+
+// syncClientFile holds the server, token and last synced state for
+// `todo sync -server`
+const syncClientFile = "syncclient.json"
+
+// runSync implements `todo sync`, syncing the list through a git remote
+func runSync(list *todo.List, args []string) {
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	remoteFlag := fs.String("remote", "", "Set the git remote to sync with (e.g. a bare repository)")
+	dirFlag := fs.String("dir", syncDir, "Local git repository holding the list")
+	peerFlag := fs.String("peer", "", "Exchange changes with a peer running 'todo sync -listen' at this URL")
+	listenFlag := fs.String("listen", "", "Serve peer exchanges on this address (e.g. localhost:8090)")
+	serverFlag := fs.String("server", "", "Sync through a 'todo sync-server' at this URL")
+	tokenFlag := fs.String("token", os.Getenv("TODO_SYNC_TOKEN"), "Device token for the sync server, or API token for a peer")
+	listFlag := fs.String("list", defaultListName(), "Name of the list served with -listen, for tokens limited to some lists")
+	fs.Parse(args)
+
+	if _, ok := baseStore.(*cryptstore.Store); ok {
+		fmt.Println("Error: Syncing an encrypted list would send it unencrypted; run 'todo decrypt' first")
+		os.Exit(1)
+	}
+
+	switch {
+	case *listenFlag != "":
+		runSyncListen(*listenFlag, *listFlag)
+		return
+	case *peerFlag != "":
+		runSyncPeer(list, *peerFlag, *tokenFlag)
+		return
+	}
+
+	client, err := syncserver.LoadClient(syncClientFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	// Once a sync server is set up it is used unless a git remote is given
+	if *serverFlag != "" || (client.Server != "" && *remoteFlag == "") {
+		runSyncServerClient(list, client, *serverFlag, *tokenFlag)
+		return
+	}
+
+	repo := gitsync.Repo{Dir: *dirFlag}
+	if *remoteFlag != "" {
+		if err := repo.SetRemote(*remoteFlag); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		fmt.Println("Syncing with", *remoteFlag)
+	}
+
+	host, _ := os.Hostname()
+	synced, result, err := repo.Sync(list, "Update todos from "+host)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+
+	if result.Committed {
+		fmt.Println("Committed local changes")
+	}
+	if result.Merged {
+		fmt.Println("Merged remote changes")
+	} else if result.Pulled {
+		fmt.Println("Pulled remote changes")
+	}
+	for _, c := range result.Conflicts {
+		fmt.Println("Conflict:", c)
+	}
+	if result.Pushed {
+		fmt.Println("Pushed to", repo.Remote())
+	}
+	if repo.Remote() == "" {
+		fmt.Println("No remote configured; set one with 'todo sync -remote <url>'")
+	}
+
+	if result.Pulled {
+		list.Items = synced.Items
+		saveTodos(list)
+	}
+	if !result.Committed && !result.Pulled && !result.Pushed {
+		fmt.Println("Already up to date")
+	}
+}
+
+// runSyncPeer exchanges changes with a peer. Both sides keep a replica of the
+// list that merges without conflicts, whatever order changes arrive in.
+func runSyncPeer(list *todo.List, url, token string) {
+	replica, err := crdt.LoadState(replicaFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	replica.Update(list, time.Now())
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	if err := crdt.Exchange(client, url, token, replica); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+
+	list.Items = replica.List().Items
+	saveTodos(list)
+	if err := replica.Save(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Synced with %s: %d tasks\n", url, len(list.Items))
+}
+
+// runSyncListen serves peer exchanges, merging each peer's changes into the
+// list. Once API tokens exist peers need a write token.
+func runSyncListen(addr, listName string) {
+	var mu sync.Mutex
+	handler := crdt.Handler(func(peer *crdt.State) (*crdt.State, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// Reload both so edits made while serving are picked up
+		list, err := store.Load()
+		if err != nil {
+			return nil, err
+		}
+		replica, err := crdt.LoadState(replicaFile)
+		if err != nil {
+			return nil, err
+		}
+		replica.Update(list, time.Now())
+		replica.Merge(peer)
+
+		list.Items = replica.List().Items
+		if err := store.Save(list); err != nil {
+			return nil, err
+		}
+		if err := replica.Save(); err != nil {
+			return nil, err
+		}
+		return replica, nil
+	})
+
+	guard := &auth.Guard{Path: tokenFile(), List: listName}
+	warnWithoutTokens()
+
+	fmt.Printf("Listening for peers on http://%s/ for list %q (Ctrl+C to stop)\n", addr, listName)
+	if err := http.ListenAndServe(addr, guard.Wrap(handler)); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+// runSyncServerClient exchanges changes with a sync server
+func runSyncServerClient(list *todo.List, client *syncserver.Client, server, token string) {
+	if server != "" {
+		client.Server = server
+	}
+	if token != "" {
+		client.Token = token
+	}
+	if client.Token == "" {
+		fmt.Println("Error: Missing device token; get one with 'todo sync-server add-device <name>' and pass it with -token")
+		os.Exit(1)
+	}
+
+	result, err := client.Sync(list, time.Now())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	if result.Pulled > 0 || result.Deleted > 0 {
+		saveTodos(list)
+	}
+	if err := client.Save(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+
+	if result == (syncserver.Result{}) {
+		fmt.Println("Already up to date")
+		return
+	}
+	fmt.Printf("Synced with %s as %s: %d sent, %d received, %d deleted\n",
+		client.Server, client.Device, result.Pushed, result.Pulled, result.Deleted)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rahul4507/todo/internal/markdown"
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+const (
+	mdSyncFile = "mdsync.json"
+)
+
+// runSyncMD implements `todo sync-md`, syncing the checklist in a Markdown file
+// with the list in both directions
+func runSyncMD(list *todo.List, args []string) {
+	fs := flag.NewFlagSet("sync-md", flag.ExitOnError)
+	dryRunFlag := fs.Bool("dry-run", false, "Show what would change without writing anything")
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		fmt.Println("Error: Missing Markdown file")
+		fmt.Println("Usage: todo sync-md [-dry-run] <file>")
+		os.Exit(1)
+	}
+
+	path, err := filepath.Abs(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+
+	state, err := markdown.LoadSyncState(mdSyncFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+
+	doc := markdown.ParseDocument(data)
+	result, base := markdown.Sync(doc, list, state.Files[path])
+
+	printSyncChanges("Added to list", result.Added)
+	printSyncChanges("Completed in list", result.Completed)
+	printSyncChanges("Reopened in list", result.Reopened)
+	printSyncChanges("Ticked in file", result.Ticked)
+	printSyncChanges("Unticked in file", result.Unticked)
+	printSyncChanges("Skipped (deleted from list)", result.Deleted)
+
+	if !result.FileDirty && !result.StoreDirty {
+		fmt.Println("Already in sync")
+	}
+	if *dryRunFlag {
+		fmt.Println("Dry run: nothing was written")
+		return
+	}
+
+	if result.FileDirty {
+		if err := os.WriteFile(path, doc.Bytes(), info.Mode().Perm()); err != nil {
+			fmt.Fprintln(os.Stderr, "Error writing", path+":", err)
+			os.Exit(1)
+		}
+	}
+	if result.StoreDirty {
+		saveTodos(list)
+	}
+
+	state.Files[path] = base
+	if err := state.Save(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error saving sync state:", err)
+		os.Exit(1)
+	}
+}
+
+func printSyncChanges(label string, texts []string) {
+	if len(texts) == 0 {
+		return
+	}
+	fmt.Printf("%s (%d):\n", label, len(texts))
+	for _, text := range texts {
+		fmt.Println("  -", text)
+	}
+}
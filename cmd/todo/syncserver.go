@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/rahul4507/todo/internal/syncserver"
+)
+
+// serverFile holds the changes and device tokens of `todo sync-server`
+const serverFile = "syncserver.json"
+
+// runSyncServer implements `todo sync-server`, serving delta sync to devices
+// and managing their tokens
+func runSyncServer(args []string) {
+	fs := flag.NewFlagSet("sync-server", flag.ExitOnError)
+	addrFlag := fs.String("addr", "localhost:8095", "Address to listen on")
+	dataFlag := fs.String("data", serverFile, "File the server keeps its state in")
+	fs.Parse(args)
+
+	server, err := syncserver.Open(*dataFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+
+	rest := fs.Args()
+	if len(rest) == 0 {
+		fmt.Printf("Serving sync on http://%s/ (Ctrl+C to stop)\n", *addrFlag)
+		if len(server.DeviceNames()) == 0 {
+			fmt.Println("No devices yet; add one with 'todo sync-server add-device <name>'")
+		}
+		if err := http.ListenAndServe(*addrFlag, server); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	switch rest[0] {
+	case "add-device":
+		if len(rest) < 2 {
+			fmt.Println("Error: Missing device name")
+			os.Exit(1)
+		}
+		token, err := server.AddDevice(rest[1])
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		fmt.Println("Added device", rest[1])
+		fmt.Println("Token:", token)
+		fmt.Println("On the device run: todo sync -server <url> -token", token)
+
+	case "remove-device":
+		if len(rest) < 2 {
+			fmt.Println("Error: Missing device name")
+			os.Exit(1)
+		}
+		if err := server.RemoveDevice(rest[1]); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		fmt.Println("Removed device", rest[1])
+
+	case "devices":
+		names := server.DeviceNames()
+		if len(names) == 0 {
+			fmt.Println("No devices")
+		}
+		for _, name := range names {
+			fmt.Println(name)
+		}
+
+	default:
+		fmt.Println("Error: Unknown sync-server command:", rest[0])
+		os.Exit(1)
+	}
+}
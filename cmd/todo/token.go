@@ -0,0 +1,103 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rahul4507/todo/internal/auth"
+)
+
+// tokenFile holds the hashed API tokens for `todo serve`
+func tokenFile() string {
+	return filepath.Join(configDir(), "tokens.json")
+}
+
+// runToken implements `todo token`, managing API tokens
+func runToken(args []string) {
+	if len(args) == 0 {
+		args = []string{"list"}
+	}
+	tokens, err := auth.Load(tokenFile())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "create":
+		fs := flag.NewFlagSet("token create", flag.ExitOnError)
+		nameFlag := fs.String("name", "", "Name to recognise the token by")
+		scopeFlag := fs.String("scope", "read", "What the token may do: read, write or admin")
+		listFlag := fs.String("list", "", "Comma separated lists the token may use (default all)")
+		fs.Parse(args[1:])
+
+		scope, err := auth.ParseScope(*scopeFlag)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		var lists []string
+		for _, l := range strings.Split(*listFlag, ",") {
+			if l = strings.TrimSpace(l); l != "" {
+				lists = append(lists, l)
+			}
+		}
+		token, value, err := tokens.Create(*nameFlag, scope, lists)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		if err := os.MkdirAll(configDir(), 0700); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		if err := tokens.Save(); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Created %s token %s\n", token.Scope, token.ID)
+		fmt.Println("Token:", value)
+		fmt.Println("It is shown only once; send it as 'Authorization: Bearer <token>'")
+
+	case "list":
+		if len(tokens.Tokens) == 0 {
+			fmt.Println("No API tokens; the API is open to anyone who can reach it")
+			return
+		}
+		for _, t := range tokens.Tokens {
+			lists := "all lists"
+			if len(t.Lists) > 0 {
+				lists = strings.Join(t.Lists, ", ")
+			}
+			name := t.Name
+			if name == "" {
+				name = "-"
+			}
+			fmt.Printf("%s  %-12s %-6s %s (created %s)\n", t.ID, name, t.Scope, lists, t.Created.Format("2006-01-02"))
+		}
+
+	case "revoke":
+		if len(args) < 2 {
+			fmt.Println("Error: Missing token ID or name")
+			os.Exit(1)
+		}
+		token, err := tokens.Revoke(args[1])
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		if err := tokens.Save(); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		fmt.Println("Revoked token", token.ID)
+
+	default:
+		fmt.Println("Error: Unknown token command:", args[0])
+		fmt.Println("Usage: todo token [create|list|revoke <id>]")
+		os.Exit(1)
+	}
+}
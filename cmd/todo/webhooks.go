@@ -0,0 +1,227 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rahul4507/todo/internal/webhook"
+)
+
+const (
+	webhookQueueFile = "webhook-queue.json"
+	webhookTimeout   = 5 * time.Second
+)
+
+func webhookConfigPath() string {
+	return filepath.Join(configDir(), "webhooks.json")
+}
+
+// loadDispatcher reads the webhook targets and the retry queue
+func loadDispatcher() (*webhook.Dispatcher, *webhook.Config, error) {
+	config, err := webhook.LoadConfig(webhookConfigPath())
+	if err != nil {
+		return nil, nil, err
+	}
+	queue, err := webhook.LoadQueue(webhookQueueFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &webhook.Dispatcher{
+		Targets: config.Targets,
+		Queue:   queue,
+		Client:  &http.Client{Timeout: webhookTimeout},
+	}, config, nil
+}
+
+// webhookNotifier sends every saved change to the webhook targets. Failures
+// are queued for retry and only reported, as the changes are already saved.
+func webhookNotifier() webhook.Notifier {
+	return webhook.Notifier{
+		Load: func() (*webhook.Dispatcher, error) {
+			d, _, err := loadDispatcher()
+			return d, err
+		},
+		Report: func(result webhook.FlushResult) {
+			reportFlush(result, os.Stderr)
+		},
+		OnError: func(err error) {
+			fmt.Fprintln(os.Stderr, "Warning: webhooks:", err)
+		},
+	}
+}
+
+func reportFlush(result webhook.FlushResult, w *os.File) {
+	if result.Retrying > 0 {
+		fmt.Fprintf(w, "Warning: %d webhook delivery(s) failed and will be retried (see 'todo webhooks list')\n", result.Retrying)
+	}
+	for _, d := range result.Dropped {
+		fmt.Fprintf(w, "Warning: gave up delivering %s event to %s: %s\n", d.Event, d.URL, d.LastError)
+	}
+}
+
+// runWebhooks implements `todo webhooks`, managing webhook targets and deliveries
+func runWebhooks(args []string) {
+	if len(args) == 0 {
+		args = []string{"list"}
+	}
+
+	switch args[0] {
+	case "list":
+		d, _, err := loadDispatcher()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		if len(d.Targets) == 0 {
+			fmt.Println("No webhooks configured")
+		}
+		for _, t := range d.Targets {
+			events := "all events"
+			if len(t.Events) > 0 {
+				events = strings.Join(t.Events, ", ")
+			}
+			signed := ""
+			if t.Secret != "" {
+				signed = ", signed"
+			}
+			fmt.Printf("%s (%s%s)\n", t.URL, events, signed)
+		}
+		if n := len(d.Queue.Deliveries); n > 0 {
+			fmt.Printf("\nQueued deliveries (%d):\n", n)
+			for _, q := range d.Queue.Deliveries {
+				fmt.Printf("  %s → %s, %d attempt(s), next %s", q.Event, q.URL, q.Attempts, q.NextAttempt.Format("2006-01-02 15:04:05"))
+				if q.LastError != "" {
+					fmt.Printf(": %s", q.LastError)
+				}
+				fmt.Println()
+			}
+		}
+
+	case "add":
+		fs := flag.NewFlagSet("webhooks add", flag.ExitOnError)
+		secretFlag := fs.String("secret", "", "Secret used to sign payloads (HMAC-SHA256)")
+		eventsFlag := fs.String("events", "", "Comma separated events to send (e.g. added,completed); all when empty")
+		fs.Parse(args[1:])
+		if fs.NArg() < 1 {
+			fmt.Println("Error: Missing webhook URL")
+			fmt.Println("Usage: todo webhooks add [-secret s] [-events added,completed] <url>")
+			os.Exit(1)
+		}
+
+		_, config, err := loadDispatcher()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		url := fs.Arg(0)
+		if _, exists := config.Target(url); exists {
+			fmt.Println("Error: Webhook already exists:", url)
+			os.Exit(1)
+		}
+		target := webhook.Target{URL: url, Secret: *secretFlag}
+		for _, e := range strings.Split(*eventsFlag, ",") {
+			if e = strings.TrimSpace(e); e != "" {
+				target.Events = append(target.Events, e)
+			}
+		}
+		config.Targets = append(config.Targets, target)
+		saveWebhookConfig(config)
+		fmt.Println("Added webhook:", url)
+
+	case "remove":
+		if len(args) < 2 {
+			fmt.Println("Error: Missing webhook URL")
+			os.Exit(1)
+		}
+		_, config, err := loadDispatcher()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		var kept []webhook.Target
+		for _, t := range config.Targets {
+			if t.URL != args[1] {
+				kept = append(kept, t)
+			}
+		}
+		if len(kept) == len(config.Targets) {
+			fmt.Println("Error: No such webhook:", args[1])
+			os.Exit(1)
+		}
+		config.Targets = kept
+		saveWebhookConfig(config)
+		fmt.Println("Removed webhook:", args[1])
+
+	case "test":
+		d, _, err := loadDispatcher()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		targets := d.Targets
+		if len(args) > 1 {
+			targets = []webhook.Target{{URL: args[1]}}
+			for _, t := range d.Targets {
+				if t.URL == args[1] {
+					targets[0] = t
+				}
+			}
+		}
+		if len(targets) == 0 {
+			fmt.Println("No webhooks configured")
+			return
+		}
+		failed := false
+		for _, t := range targets {
+			if err := d.Test(t); err != nil {
+				fmt.Printf("✗ %s: %v\n", t.URL, err)
+				failed = true
+				continue
+			}
+			fmt.Printf("✓ %s\n", t.URL)
+		}
+		if failed {
+			os.Exit(1)
+		}
+
+	case "flush":
+		d, _, err := loadDispatcher()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		// Retry everything now, regardless of backoff
+		now := time.Now()
+		for i := range d.Queue.Deliveries {
+			d.Queue.Deliveries[i].NextAttempt = now
+		}
+		result := d.Flush(now)
+		if err := d.Queue.Save(); err != nil {
+			fmt.Fprintln(os.Stderr, "Error saving webhook queue:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Sent %d queued delivery(s)\n", result.Sent)
+		reportFlush(result, os.Stdout)
+
+	default:
+		fmt.Println("Error: Unknown webhooks command:", args[0])
+		fmt.Println("Usage: todo webhooks [list|add|remove|test|flush]")
+		os.Exit(1)
+	}
+}
+
+func saveWebhookConfig(config *webhook.Config) {
+	if err := os.MkdirAll(configDir(), 0700); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	if err := config.Save(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error saving webhooks:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,347 @@
+// Package auth protects the API server with tokens. Tokens are stored hashed
+// and carry a scope (read, write or admin) and optionally the lists they may
+// access; Guard checks them on every request.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Scope is what a token may do. Each scope includes the ones below it.
+type Scope int
+
+const (
+	// ScopeRead allows reading tasks
+	ScopeRead Scope = iota + 1
+	// ScopeWrite also allows adding, changing and deleting tasks
+	ScopeWrite
+	// ScopeAdmin also allows managing tokens
+	ScopeAdmin
+)
+
+func (s Scope) String() string {
+	switch s {
+	case ScopeRead:
+		return "read"
+	case ScopeWrite:
+		return "write"
+	case ScopeAdmin:
+		return "admin"
+	}
+	return "none"
+}
+
+// ParseScope parses "read", "write" or "admin"
+func ParseScope(s string) (Scope, error) {
+	switch strings.ToLower(s) {
+	case "read", "read-only", "readonly":
+		return ScopeRead, nil
+	case "write":
+		return ScopeWrite, nil
+	case "admin":
+		return ScopeAdmin, nil
+	}
+	return 0, fmt.Errorf("Invalid scope %q (use read, write or admin)", s)
+}
+
+func (s Scope) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Scope) UnmarshalText(text []byte) error {
+	scope, err := ParseScope(string(text))
+	if err != nil {
+		return err
+	}
+	*s = scope
+	return nil
+}
+
+// Token is a stored API token. The token itself is only shown when it is
+// created; the file keeps its hash.
+type Token struct {
+	// ID identifies the token; it is also the start of the token itself
+	ID      string    `json:"id"`
+	Name    string    `json:"name,omitempty"`
+	Hash    string    `json:"hash"`
+	Scope   Scope     `json:"scope"`
+	Lists   []string  `json:"lists,omitempty"`
+	Created time.Time `json:"created"`
+}
+
+// CanAccess reports whether the token may use list; a token without lists
+// may use all of them
+func (t Token) CanAccess(list string) bool {
+	if len(t.Lists) == 0 {
+		return true
+	}
+	for _, l := range t.Lists {
+		if l == list {
+			return true
+		}
+	}
+	return false
+}
+
+const tokenPrefix = "todo_"
+
+// Tokens is the set of tokens, kept in a JSON file
+type Tokens struct {
+	path   string
+	Tokens []Token
+}
+
+// Load reads the tokens from path. A missing file yields no tokens.
+func Load(path string) (*Tokens, error) {
+	t := &Tokens{path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return t, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &t.Tokens); err != nil {
+		return nil, fmt.Errorf("Invalid token file %s: %w", path, err)
+	}
+	return t, nil
+}
+
+// Save writes the tokens back to the file they were loaded from
+func (t *Tokens) Save() error {
+	data, err := json.MarshalIndent(t.Tokens, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(t.path, data, 0600)
+}
+
+// Create adds a token and returns it along with the secret to hand out
+func (t *Tokens) Create(name string, scope Scope, lists []string) (Token, string, error) {
+	if scope < ScopeRead || scope > ScopeAdmin {
+		return Token{}, "", errors.New("Invalid scope")
+	}
+	for _, existing := range t.Tokens {
+		if name != "" && existing.Name == name {
+			return Token{}, "", fmt.Errorf("A token named %s already exists", name)
+		}
+	}
+	id, err := randomHex(4)
+	if err != nil {
+		return Token{}, "", err
+	}
+	secret, err := randomHex(20)
+	if err != nil {
+		return Token{}, "", err
+	}
+	value := tokenPrefix + id + "_" + secret
+	token := Token{ID: id, Name: name, Hash: hash(value), Scope: scope, Lists: lists, Created: time.Now()}
+	t.Tokens = append(t.Tokens, token)
+	return token, value, nil
+}
+
+// Revoke removes the token with the given ID or name
+func (t *Tokens) Revoke(idOrName string) (Token, error) {
+	for i, token := range t.Tokens {
+		if token.ID == idOrName || (token.Name != "" && token.Name == idOrName) {
+			t.Tokens = append(t.Tokens[:i], t.Tokens[i+1:]...)
+			return token, nil
+		}
+	}
+	return Token{}, fmt.Errorf("No token %s", idOrName)
+}
+
+// Verify returns the token a secret belongs to
+func (t *Tokens) Verify(value string) (Token, bool) {
+	rest, ok := strings.CutPrefix(value, tokenPrefix)
+	if !ok {
+		return Token{}, false
+	}
+	id, _, ok := strings.Cut(rest, "_")
+	if !ok {
+		return Token{}, false
+	}
+	h := hash(value)
+	for _, token := range t.Tokens {
+		if token.ID == id && subtle.ConstantTimeCompare([]byte(token.Hash), []byte(h)) == 1 {
+			return token, true
+		}
+	}
+	return Token{}, false
+}
+
+func hash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Guard is middleware that requires a token for every request it doesn't
+// consider public. GET and HEAD requests need the read scope, token
+// management needs admin, and everything else needs write.
+type Guard struct {
+	// Path is the token file. It is read on every request, so new and revoked
+	// tokens take effect at once. Without any tokens every request is allowed.
+	Path string
+	// List is the name of the list being served
+	List string
+	// Public reports requests that need no token, such as static assets
+	Public func(r *http.Request) bool
+	// Basic asks for the token as a Basic auth password, for clients such
+	// as calendar apps that can't send bearer tokens
+	Basic bool
+}
+
+// AdminPrefix is where token management lives; it needs the admin scope
+const AdminPrefix = "/api/tokens"
+
+// Wrap returns next guarded by g
+func (g *Guard) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if g.Public != nil && g.Public(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		tokens, err := Load(g.Path)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if len(tokens.Tokens) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		value := bearer(r)
+		if value == "" {
+			w.Header().Set("WWW-Authenticate", g.challenge(""))
+			writeError(w, http.StatusUnauthorized, "Missing API token")
+			return
+		}
+		token, ok := tokens.Verify(value)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", g.challenge(`error="invalid_token"`))
+			writeError(w, http.StatusUnauthorized, "Invalid API token")
+			return
+		}
+		if !token.CanAccess(g.List) {
+			writeError(w, http.StatusForbidden, "Token has no access to list "+g.List)
+			return
+		}
+		if required := requiredScope(r); token.Scope < required {
+			writeError(w, http.StatusForbidden, "Token needs the "+required.String()+" scope")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// challenge is the WWW-Authenticate header asking for a token
+func (g *Guard) challenge(params string) string {
+	if g.Basic {
+		return `Basic realm="todo"`
+	}
+	if params == "" {
+		return "Bearer"
+	}
+	return "Bearer " + params
+}
+
+// bearer returns the token sent with the request, either as a bearer token
+// or as a Basic auth password with any user name. EventSource can't send
+// headers, so GET requests may pass it as the access_token query parameter.
+func bearer(r *http.Request) string {
+	if value, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(value)
+	}
+	if _, password, ok := r.BasicAuth(); ok {
+		return password
+	}
+	if r.Method == http.MethodGet {
+		return r.URL.Query().Get("access_token")
+	}
+	return ""
+}
+
+func requiredScope(r *http.Request) Scope {
+	switch {
+	case r.URL.Path == AdminPrefix || strings.HasPrefix(r.URL.Path, AdminPrefix+"/"):
+		return ScopeAdmin
+	case r.Method == http.MethodGet || r.Method == http.MethodHead,
+		// CalDAV clients read with these
+		r.Method == http.MethodOptions || r.Method == "PROPFIND" || r.Method == "REPORT":
+		return ScopeRead
+	}
+	return ScopeWrite
+}
+
+// tokenView is a token as the API shows it, without its hash
+type tokenView struct {
+	ID      string    `json:"id"`
+	Name    string    `json:"name,omitempty"`
+	Scope   Scope     `json:"scope"`
+	Lists   []string  `json:"lists,omitempty"`
+	Created time.Time `json:"created"`
+}
+
+// Handler serves token management under AdminPrefix: GET lists the tokens
+// and DELETE AdminPrefix/{id} revokes one. Serve it behind a Guard.
+func Handler(path string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+AdminPrefix, func(w http.ResponseWriter, r *http.Request) {
+		tokens, err := Load(path)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		views := []tokenView{}
+		for _, t := range tokens.Tokens {
+			views = append(views, tokenView{t.ID, t.Name, t.Scope, t.Lists, t.Created})
+		}
+		writeJSON(w, http.StatusOK, views)
+	})
+	mux.HandleFunc("DELETE "+AdminPrefix+"/{id}", func(w http.ResponseWriter, r *http.Request) {
+		tokens, err := Load(path)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if _, err := tokens.Revoke(r.PathValue("id")); err != nil {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if err := tokens.Save(); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTokens(t *testing.T) *Tokens {
+	t.Helper()
+	tokens, err := Load(filepath.Join(t.TempDir(), "tokens.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tokens
+}
+
+func mustCreate(t *testing.T, tokens *Tokens, name string, scope Scope, lists ...string) string {
+	t.Helper()
+	_, value, err := tokens.Create(name, scope, lists)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tokens.Save(); err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+func TestCreateVerifyRevoke(t *testing.T) {
+	tokens := newTokens(t)
+	value := mustCreate(t, tokens, "laptop", ScopeWrite)
+
+	reloaded, err := Load(tokens.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, ok := reloaded.Verify(value)
+	if !ok || token.Name != "laptop" || token.Scope != ScopeWrite {
+		t.Fatalf("Expected the token to verify after a reload, got %+v, %v", token, ok)
+	}
+	if strings.Contains(token.Hash, value) || token.Hash == value {
+		t.Error("Expected only a hash of the token to be stored")
+	}
+	if _, ok := reloaded.Verify(value + "x"); ok {
+		t.Error("Expected a wrong token to fail")
+	}
+	if _, _, err := reloaded.Create("laptop", ScopeRead, nil); err == nil {
+		t.Error("Expected an error for a duplicate name")
+	}
+
+	if _, err := reloaded.Revoke("laptop"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := reloaded.Verify(value); ok {
+		t.Error("Expected a revoked token to fail")
+	}
+	if _, err := reloaded.Revoke(token.ID); err == nil {
+		t.Error("Expected an error revoking an unknown token")
+	}
+}
+
+func TestParseScope(t *testing.T) {
+	for input, expected := range map[string]Scope{"read": ScopeRead, "Read-Only": ScopeRead, "write": ScopeWrite, "ADMIN": ScopeAdmin} {
+		if scope, err := ParseScope(input); err != nil || scope != expected {
+			t.Errorf("ParseScope(%q) = %v, %v; expected %v", input, scope, err, expected)
+		}
+	}
+	if _, err := ParseScope("root"); err == nil {
+		t.Error("Expected an error for an unknown scope")
+	}
+}
+
+// newGuarded serves a handler that answers 200 behind a guard for list "home"
+func newGuarded(t *testing.T, tokens *Tokens) *httptest.Server {
+	t.Helper()
+	guard := &Guard{
+		Path: tokens.path,
+		List: "home",
+		Public: func(r *http.Request) bool {
+			return !strings.HasPrefix(r.URL.Path, "/api/")
+		},
+	}
+	mux := http.NewServeMux()
+	mux.Handle(AdminPrefix+"/", Handler(tokens.path))
+	mux.Handle(AdminPrefix, Handler(tokens.path))
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+	server := httptest.NewServer(guard.Wrap(mux))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func status(t *testing.T, method, url, token string) int {
+	t.Helper()
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestGuard(t *testing.T) {
+	tokens := newTokens(t)
+	server := newGuarded(t, tokens)
+	items := server.URL + "/api/items"
+
+	// Without tokens the API stays open
+	if got := status(t, "DELETE", items+"/1", ""); got != http.StatusOK {
+		t.Errorf("Expected an open API without tokens, got %d", got)
+	}
+
+	reader := mustCreate(t, tokens, "reader", ScopeRead)
+	writer := mustCreate(t, tokens, "writer", ScopeWrite)
+	admin := mustCreate(t, tokens, "admin", ScopeAdmin)
+	work := mustCreate(t, tokens, "work", ScopeWrite, "work")
+
+	tests := []struct {
+		method, url, token string
+		expected           int
+	}{
+		{"GET", server.URL + "/app.js", "", http.StatusOK},
+		{"GET", items, "", http.StatusUnauthorized},
+		{"GET", items, "todo_0000_bogus", http.StatusUnauthorized},
+		{"GET", items, reader, http.StatusOK},
+		{"POST", items, reader, http.StatusForbidden},
+		{"POST", items, writer, http.StatusOK},
+		{"DELETE", items + "/1", writer, http.StatusOK},
+		{"GET", items, work, http.StatusForbidden},
+		{"GET", server.URL + AdminPrefix, writer, http.StatusForbidden},
+		{"GET", server.URL + AdminPrefix, admin, http.StatusOK},
+		{"GET", items + "?access_token=" + reader, "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		if got := status(t, tt.method, tt.url, tt.token); got != tt.expected {
+			t.Errorf("%s %s: expected %d, got %d", tt.method, tt.url, tt.expected, got)
+		}
+	}
+
+	// Revoking through the API takes effect at once
+	id := strings.SplitN(strings.TrimPrefix(writer, tokenPrefix), "_", 2)[0]
+	if got := status(t, "DELETE", server.URL+AdminPrefix+"/"+id, admin); got != http.StatusNoContent {
+		t.Fatalf("Expected the token to be revoked, got %d", got)
+	}
+	if got := status(t, "GET", items, writer); got != http.StatusUnauthorized {
+		t.Errorf("Expected a revoked token to be refused, got %d", got)
+	}
+}
+
+func TestGuardBasic(t *testing.T) {
+	tokens := newTokens(t)
+	reader := mustCreate(t, tokens, "calendar", ScopeRead)
+	guard := &Guard{Path: tokens.path, List: "home", Basic: true}
+	server := httptest.NewServer(guard.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		method, password string
+		expected         int
+	}{
+		{"PROPFIND", "", http.StatusUnauthorized},
+		{"PROPFIND", reader, http.StatusOK},
+		{"REPORT", reader, http.StatusOK},
+		{"PUT", reader, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req, err := http.NewRequest(tt.method, server.URL+"/todos/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tt.password != "" {
+			req.SetBasicAuth("me", tt.password)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.expected {
+			t.Errorf("%s with password %q: expected %d, got %d", tt.method, tt.password, tt.expected, resp.StatusCode)
+		}
+		if resp.StatusCode == http.StatusUnauthorized && !strings.HasPrefix(resp.Header.Get("WWW-Authenticate"), "Basic") {
+			t.Errorf("Expected a Basic challenge, got %q", resp.Header.Get("WWW-Authenticate"))
+		}
+	}
+}
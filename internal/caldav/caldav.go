@@ -0,0 +1,301 @@
+// Package caldav serves a todo list as a CalDAV (RFC 4791) VTODO collection so
+// calendar and task apps can sync with it directly.
+//
+// The server exposes a single calendar collection at CollectionPath. Each item is
+// a resource named after its ID, e.g. /todos/3f2a9c1e0b7d4a55.ics.
+package caldav
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/rahul4507/todo/internal/ical"
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+const (
+	// CollectionPath is the URL of the VTODO collection
+	CollectionPath = "/todos/"
+
+	nsDAV    = "DAV:"
+	nsCalDAV = "urn:ietf:params:xml:ns:caldav"
+	nsCS     = "http://calendarserver.org/ns/"
+
+	icsContentType = "text/calendar; charset=utf-8"
+	xmlContentType = "application/xml; charset=utf-8"
+
+	maxBodySize = 1 << 20
+)
+
+// Handler is an http.Handler implementing the CalDAV endpoints on top of a Store
+type Handler struct {
+	Store       todo.Store
+	DisplayName string
+
+	// mu serializes load-modify-save cycles on the store
+	mu sync.Mutex
+}
+
+// NewHandler returns a CalDAV handler for the store
+func NewHandler(store todo.Store) *Handler {
+	return &Handler{Store: store, DisplayName: "Todo"}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("DAV", "1, 3, calendar-access")
+
+	if r.URL.Path == "/.well-known/caldav" {
+		http.Redirect(w, r, "/", http.StatusMovedPermanently)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodOptions:
+		w.Header().Set("Allow", "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, REPORT")
+		w.WriteHeader(http.StatusOK)
+	case "PROPFIND":
+		h.propfind(w, r)
+	case "REPORT":
+		h.report(w, r)
+	case http.MethodGet, http.MethodHead:
+		h.get(w, r)
+	case http.MethodPut:
+		h.put(w, r)
+	case http.MethodDelete:
+		h.delete(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// resourceID returns the item ID addressed by a resource path, or "" if the
+// path is not an item resource
+func resourceID(p string) string {
+	dir, name := path.Split(p)
+	if dir != CollectionPath || !strings.HasSuffix(name, ".ics") {
+		return ""
+	}
+	return strings.TrimSuffix(name, ".ics")
+}
+
+func resourcePath(item todo.Item) string {
+	return CollectionPath + item.ID + ".ics"
+}
+
+// ETag returns the entity tag of an item; it changes whenever any field changes
+func ETag(item todo.Item) string {
+	data, _ := json.Marshal(item)
+	sum := sha1.Sum(data)
+	return `"` + hex.EncodeToString(sum[:10]) + `"`
+}
+
+// ctag changes whenever anything in the collection changes
+func ctag(list *todo.List) string {
+	data, _ := json.Marshal(list.Items)
+	sum := sha1.Sum(data)
+	return hex.EncodeToString(sum[:10])
+}
+
+func encodeItem(item todo.Item) string {
+	var buf bytes.Buffer
+	ical.EncodeItem(&buf, item)
+	return buf.String()
+}
+
+func (h *Handler) load(w http.ResponseWriter) (*todo.List, bool) {
+	list, err := h.Store.Load()
+	if err != nil {
+		http.Error(w, "Loading todos failed: "+err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	return list, true
+}
+
+func (h *Handler) get(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	list, ok := h.load(w)
+	if !ok {
+		return
+	}
+
+	if r.URL.Path == CollectionPath {
+		w.Header().Set("Content-Type", icsContentType)
+		ical.Encode(w, list.Items)
+		return
+	}
+
+	index := list.IndexOf(resourceID(r.URL.Path))
+	if index < 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	item := list.Items[index]
+	w.Header().Set("Content-Type", icsContentType)
+	w.Header().Set("ETag", ETag(item))
+	if r.Method == http.MethodHead {
+		return
+	}
+	io.WriteString(w, encodeItem(item))
+}
+
+func (h *Handler) put(w http.ResponseWriter, r *http.Request) {
+	id := resourceID(r.URL.Path)
+	if id == "" {
+		http.Error(w, "Items can only be stored in "+CollectionPath, http.StatusForbidden)
+		return
+	}
+
+	items, err := ical.Decode(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(items) != 1 {
+		http.Error(w, "Expected exactly one VTODO", http.StatusUnsupportedMediaType)
+		return
+	}
+	incoming := items[0]
+	// The resource name is the item's identity in this store
+	incoming.ID = id
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	list, ok := h.load(w)
+	if !ok {
+		return
+	}
+
+	index := list.IndexOf(id)
+	if !checkPreconditions(w, r, list, index) {
+		return
+	}
+
+	status := http.StatusNoContent
+	if index < 0 {
+		if err := list.AddItem(incoming); err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		list.Sort()
+		status = http.StatusCreated
+	} else {
+		existing := list.Items[index]
+		// Clients may drop our extension properties; keep what we know
+		if incoming.Estimate == nil {
+			incoming.Estimate = existing.Estimate
+		}
+		if incoming.Tracked == 0 {
+			incoming.Tracked = existing.Tracked
+		}
+		if err := list.Update(index, incoming); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := h.Store.Save(list); err != nil {
+		http.Error(w, "Saving todos failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", ETag(list.Items[list.IndexOf(id)]))
+	w.WriteHeader(status)
+}
+
+func (h *Handler) delete(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	list, ok := h.load(w)
+	if !ok {
+		return
+	}
+
+	index := list.IndexOf(resourceID(r.URL.Path))
+	if index < 0 {
+		http.NotFound(w, r)
+		return
+	}
+	if !checkPreconditions(w, r, list, index) {
+		return
+	}
+
+	if err := list.Delete(index); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := h.Store.Save(list); err != nil {
+		http.Error(w, "Saving todos failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checkPreconditions enforces If-Match and If-None-Match so clients never
+// overwrite changes they have not seen
+func checkPreconditions(w http.ResponseWriter, r *http.Request, list *todo.List, index int) bool {
+	current := ""
+	if index >= 0 {
+		current = ETag(list.Items[index])
+	}
+
+	if match := r.Header.Get("If-Match"); match != "" {
+		if current == "" || (match != "*" && !etagListContains(match, current)) {
+			http.Error(w, "Resource has changed", http.StatusPreconditionFailed)
+			return false
+		}
+	}
+	if noneMatch := r.Header.Get("If-None-Match"); noneMatch != "" && current != "" {
+		if noneMatch == "*" || etagListContains(noneMatch, current) {
+			http.Error(w, "Resource already exists", http.StatusPreconditionFailed)
+			return false
+		}
+	}
+	return true
+}
+
+func etagListContains(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// readXML decodes an optional XML request body
+func readXML(r *http.Request, v any) error {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	return xml.Unmarshal(body, v)
+}
+
+func writeMultistatus(w http.ResponseWriter, responses []string) {
+	w.Header().Set("Content-Type", xmlContentType)
+	w.WriteHeader(http.StatusMultiStatus)
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="utf-8"?>`+"\n"+
+		`<D:multistatus xmlns:D="%s" xmlns:C="%s" xmlns:CS="%s">`, nsDAV, nsCalDAV, nsCS)
+	for _, resp := range responses {
+		io.WriteString(w, resp)
+	}
+	io.WriteString(w, "</D:multistatus>\n")
+}
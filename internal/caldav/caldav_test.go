@@ -0,0 +1,288 @@
+package caldav
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+func newTestServer(t *testing.T) (*httptest.Server, todo.Store) {
+	t.Helper()
+	store := todo.FileStore{Path: filepath.Join(t.TempDir(), "todos.json")}
+
+	list := todo.NewList()
+	if err := list.Add("Existing task"); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.AddTag(0, "work"); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.Add("Done task"); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.Complete(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(list); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(NewHandler(store))
+	t.Cleanup(server.Close)
+	return server, store
+}
+
+func do(t *testing.T, method, url, body string, headers map[string]string) (*http.Response, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, string(data)
+}
+
+const newTodo = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Test//Client//EN\r\n" +
+	"BEGIN:VTODO\r\n" +
+	"UID:phone-1\r\n" +
+	"SUMMARY:Added from phone\r\n" +
+	"PRIORITY:1\r\n" +
+	"DUE;VALUE=DATE:20300101\r\n" +
+	"CATEGORIES:mobile\r\n" +
+	"STATUS:NEEDS-ACTION\r\n" +
+	"END:VTODO\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestOptionsAndWellKnown(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	resp, _ := do(t, http.MethodOptions, server.URL+CollectionPath, "", nil)
+	if !strings.Contains(resp.Header.Get("DAV"), "calendar-access") {
+		t.Errorf("Expected calendar-access in DAV header, got %q", resp.Header.Get("DAV"))
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	r, err := client.Get(server.URL + "/.well-known/caldav")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Body.Close()
+	if r.StatusCode != http.StatusMovedPermanently || r.Header.Get("Location") != "/" {
+		t.Errorf("Expected redirect to /, got %d %s", r.StatusCode, r.Header.Get("Location"))
+	}
+}
+
+func TestPropfind(t *testing.T) {
+	server, store := newTestServer(t)
+	list, _ := store.Load()
+
+	body := `<?xml version="1.0"?>
+<D:propfind xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav" xmlns:CS="http://calendarserver.org/ns/">
+  <D:prop><D:resourcetype/><D:getetag/><CS:getctag/><C:supported-calendar-component-set/><D:unknown-prop/></D:prop>
+</D:propfind>`
+
+	resp, out := do(t, "PROPFIND", server.URL+CollectionPath, body, map[string]string{"Depth": "1"})
+	if resp.StatusCode != http.StatusMultiStatus {
+		t.Fatalf("Expected 207, got %d: %s", resp.StatusCode, out)
+	}
+
+	for _, s := range []string{
+		"<D:href>/todos/</D:href>",
+		"<C:calendar/>",
+		`<C:comp name="VTODO"/>`,
+		"<CS:getctag>",
+		"<D:href>" + resourcePath(list.Items[0]) + "</D:href>",
+		"<D:href>" + resourcePath(list.Items[1]) + "</D:href>",
+		"<D:unknown-prop/>",
+		"404 Not Found",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("PROPFIND response should contain %q, got:\n%s", s, out)
+		}
+	}
+
+	// Depth 0 only describes the collection itself
+	_, out = do(t, "PROPFIND", server.URL+CollectionPath, body, map[string]string{"Depth": "0"})
+	if strings.Contains(out, resourcePath(list.Items[0])) {
+		t.Errorf("Depth 0 PROPFIND should not list items:\n%s", out)
+	}
+
+	// The root points clients at the calendar home
+	_, out = do(t, "PROPFIND", server.URL+"/", "", map[string]string{"Depth": "0"})
+	if !strings.Contains(out, "<C:calendar-home-set><D:href>/</D:href></C:calendar-home-set>") {
+		t.Errorf("Root PROPFIND should contain the calendar home:\n%s", out)
+	}
+}
+
+func TestPutGetDelete(t *testing.T) {
+	server, store := newTestServer(t)
+	url := server.URL + CollectionPath + "phone-1.ics"
+
+	// Create
+	resp, out := do(t, http.MethodPut, url, newTodo, map[string]string{"If-None-Match": "*"})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", resp.StatusCode, out)
+	}
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		t.Fatal("Expected an ETag on create")
+	}
+
+	list, _ := store.Load()
+	index := list.IndexOf("phone-1")
+	if index < 0 {
+		t.Fatal("Created item was not stored")
+	}
+	if item := list.Items[index]; item.Text != "Added from phone" || item.Priority != todo.PriorityHigh || item.Tags[0] != "mobile" {
+		t.Errorf("Unexpected stored item: %+v", item)
+	}
+
+	// Creating again with If-None-Match fails
+	resp, _ = do(t, http.MethodPut, url, newTodo, map[string]string{"If-None-Match": "*"})
+	if resp.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("Expected 412 for existing resource, got %d", resp.StatusCode)
+	}
+
+	// Get
+	resp, out = do(t, http.MethodGet, url, "", nil)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("ETag") != etag {
+		t.Errorf("Expected 200 with ETag %s, got %d %s", etag, resp.StatusCode, resp.Header.Get("ETag"))
+	}
+	if !strings.Contains(out, "SUMMARY:Added from phone") {
+		t.Errorf("Unexpected GET body:\n%s", out)
+	}
+
+	// Complete it from the client
+	completed := strings.Replace(newTodo, "STATUS:NEEDS-ACTION", "STATUS:COMPLETED", 1)
+	resp, _ = do(t, http.MethodPut, url, completed, map[string]string{"If-Match": `"stale"`})
+	if resp.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("Expected 412 for stale ETag, got %d", resp.StatusCode)
+	}
+	resp, out = do(t, http.MethodPut, url, completed, map[string]string{"If-Match": etag})
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d: %s", resp.StatusCode, out)
+	}
+	if resp.Header.Get("ETag") == etag {
+		t.Error("Expected the ETag to change after an update")
+	}
+
+	list, _ = store.Load()
+	item := list.Items[list.IndexOf("phone-1")]
+	if !item.Done || item.CompletedAt == nil {
+		t.Errorf("Expected item to be completed in the store: %+v", item)
+	}
+
+	// Delete
+	resp, _ = do(t, http.MethodDelete, url, "", nil)
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected 204, got %d", resp.StatusCode)
+	}
+	list, _ = store.Load()
+	if list.IndexOf("phone-1") >= 0 {
+		t.Error("Item should have been deleted")
+	}
+
+	resp, _ = do(t, http.MethodGet, url, "", nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 after delete, got %d", resp.StatusCode)
+	}
+}
+
+func TestPutRejectsInvalid(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	resp, _ := do(t, http.MethodPut, server.URL+CollectionPath+"x.ics", "not ical", nil)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid body, got %d", resp.StatusCode)
+	}
+
+	resp, _ = do(t, http.MethodPut, server.URL+"/elsewhere.ics", newTodo, nil)
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected 403 outside the collection, got %d", resp.StatusCode)
+	}
+
+	duplicate := strings.Replace(newTodo, "Added from phone", "Existing task", 1)
+	resp, _ = do(t, http.MethodPut, server.URL+CollectionPath+"dup.ics", duplicate, nil)
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("Expected 409 for duplicate task, got %d", resp.StatusCode)
+	}
+}
+
+func TestCalendarQuery(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	pending := `<?xml version="1.0"?>
+<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:prop><D:getetag/><C:calendar-data/></D:prop>
+  <C:filter>
+    <C:comp-filter name="VCALENDAR">
+      <C:comp-filter name="VTODO">
+        <C:prop-filter name="COMPLETED"><C:is-not-defined/></C:prop-filter>
+      </C:comp-filter>
+    </C:comp-filter>
+  </C:filter>
+</C:calendar-query>`
+
+	resp, out := do(t, "REPORT", server.URL+CollectionPath, pending, map[string]string{"Depth": "1"})
+	if resp.StatusCode != http.StatusMultiStatus {
+		t.Fatalf("Expected 207, got %d: %s", resp.StatusCode, out)
+	}
+	if !strings.Contains(out, "SUMMARY:Existing task") {
+		t.Errorf("Expected pending task in result:\n%s", out)
+	}
+	if strings.Contains(out, "SUMMARY:Done task") {
+		t.Errorf("Completed task should be filtered out:\n%s", out)
+	}
+
+	byCategory := strings.Replace(pending,
+		`<C:prop-filter name="COMPLETED"><C:is-not-defined/></C:prop-filter>`,
+		`<C:prop-filter name="CATEGORIES"><C:text-match>WORK</C:text-match></C:prop-filter>`, 1)
+	_, out = do(t, "REPORT", server.URL+CollectionPath, byCategory, nil)
+	if !strings.Contains(out, "SUMMARY:Existing task") || strings.Contains(out, "SUMMARY:Done task") {
+		t.Errorf("Expected only the tagged task:\n%s", out)
+	}
+
+	events := strings.Replace(pending, `name="VTODO"`, `name="VEVENT"`, 1)
+	_, out = do(t, "REPORT", server.URL+CollectionPath, events, nil)
+	if strings.Contains(out, "<D:response>") {
+		t.Errorf("A VEVENT query should not match todos:\n%s", out)
+	}
+}
+
+func TestCalendarMultiget(t *testing.T) {
+	server, store := newTestServer(t)
+	list, _ := store.Load()
+
+	body := `<?xml version="1.0"?>
+<C:calendar-multiget xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:prop><D:getetag/><C:calendar-data/></D:prop>
+  <D:href>` + resourcePath(list.Items[0]) + `</D:href>
+  <D:href>/todos/missing.ics</D:href>
+</C:calendar-multiget>`
+
+	_, out := do(t, "REPORT", server.URL+CollectionPath, body, nil)
+	if !strings.Contains(out, "SUMMARY:Existing task") {
+		t.Errorf("Expected requested item in result:\n%s", out)
+	}
+	if !strings.Contains(out, "<D:href>/todos/missing.ics</D:href><D:status>HTTP/1.1 404 Not Found</D:status>") {
+		t.Errorf("Expected 404 response for missing href:\n%s", out)
+	}
+}
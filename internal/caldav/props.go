@@ -0,0 +1,393 @@
+package caldav
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rahul4507/todo/internal/ical"
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+// anyElement captures the name of an arbitrary XML element
+type anyElement struct {
+	XMLName xml.Name
+}
+
+type propList struct {
+	Names []anyElement `xml:",any"`
+}
+
+type propfindRequest struct {
+	XMLName xml.Name  `xml:"DAV: propfind"`
+	AllProp *struct{} `xml:"DAV: allprop"`
+	Prop    *propList `xml:"DAV: prop"`
+}
+
+type reportRequest struct {
+	XMLName xml.Name
+	Prop    *propList   `xml:"DAV: prop"`
+	Filter  *compFilter `xml:"urn:ietf:params:xml:ns:caldav filter>comp-filter"`
+	Hrefs   []string    `xml:"DAV: href"`
+}
+
+type compFilter struct {
+	Name         string       `xml:"name,attr"`
+	IsNotDefined *struct{}    `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	TimeRange    *timeRange   `xml:"urn:ietf:params:xml:ns:caldav time-range"`
+	PropFilters  []propFilter `xml:"urn:ietf:params:xml:ns:caldav prop-filter"`
+	CompFilters  []compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+type propFilter struct {
+	Name         string     `xml:"name,attr"`
+	IsNotDefined *struct{}  `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	TextMatch    *textMatch `xml:"urn:ietf:params:xml:ns:caldav text-match"`
+}
+
+type textMatch struct {
+	Value           string `xml:",chardata"`
+	NegateCondition string `xml:"negate-condition,attr"`
+}
+
+type timeRange struct {
+	Start string `xml:"start,attr"`
+	End   string `xml:"end,attr"`
+}
+
+var (
+	propResourceType      = xml.Name{Space: nsDAV, Local: "resourcetype"}
+	propDisplayName       = xml.Name{Space: nsDAV, Local: "displayname"}
+	propGetETag           = xml.Name{Space: nsDAV, Local: "getetag"}
+	propGetContentType    = xml.Name{Space: nsDAV, Local: "getcontenttype"}
+	propCurrentUser       = xml.Name{Space: nsDAV, Local: "current-user-principal"}
+	propPrincipalURL      = xml.Name{Space: nsDAV, Local: "principal-URL"}
+	propPrivilegeSet      = xml.Name{Space: nsDAV, Local: "current-user-privilege-set"}
+	propCalendarHome      = xml.Name{Space: nsCalDAV, Local: "calendar-home-set"}
+	propSupportedComps    = xml.Name{Space: nsCalDAV, Local: "supported-calendar-component-set"}
+	propCalendarData      = xml.Name{Space: nsCalDAV, Local: "calendar-data"}
+	propGetCTag           = xml.Name{Space: nsCS, Local: "getctag"}
+	principalHref         = "<D:href>/</D:href>"
+	allPrivileges         = "<D:privilege><D:all/></D:privilege>"
+	supportedComponentSet = `<C:comp name="VTODO"/>`
+)
+
+// resource is something that can answer PROPFIND and REPORT requests
+type resource struct {
+	href  string
+	props map[xml.Name]string // property name to inner XML
+	// extra holds properties only returned when asked for by name
+	extra map[xml.Name]string
+}
+
+func rootResource(displayName string) resource {
+	return resource{
+		href: "/",
+		props: map[xml.Name]string{
+			propResourceType: "<D:collection/>",
+			propDisplayName:  xmlText(displayName),
+			propCurrentUser:  principalHref,
+			propPrincipalURL: principalHref,
+			propCalendarHome: principalHref,
+		},
+	}
+}
+
+func collectionResource(displayName string, list *todo.List) resource {
+	tag := ctag(list)
+	return resource{
+		href: CollectionPath,
+		props: map[xml.Name]string{
+			propResourceType:   "<D:collection/><C:calendar/>",
+			propDisplayName:    xmlText(displayName),
+			propCurrentUser:    principalHref,
+			propPrivilegeSet:   allPrivileges,
+			propSupportedComps: supportedComponentSet,
+			propGetCTag:        xmlText(tag),
+			propGetETag:        xmlText(`"` + tag + `"`),
+		},
+	}
+}
+
+func itemResource(item todo.Item) resource {
+	return resource{
+		href: resourcePath(item),
+		props: map[xml.Name]string{
+			propResourceType:   "",
+			propGetETag:        xmlText(ETag(item)),
+			propGetContentType: "text/calendar; charset=utf-8; component=VTODO",
+			propPrivilegeSet:   allPrivileges,
+		},
+		extra: map[xml.Name]string{
+			propCalendarData: xmlText(encodeItem(item)),
+		},
+	}
+}
+
+// render returns the D:response element for the requested properties, or for
+// all properties when requested is nil
+func (res resource) render(requested []xml.Name) string {
+	var found, missing strings.Builder
+
+	if requested == nil {
+		for name, value := range res.props {
+			found.WriteString(element(name, value))
+		}
+	}
+	for _, name := range requested {
+		value, ok := res.props[name]
+		if !ok {
+			value, ok = res.extra[name]
+		}
+		if ok {
+			found.WriteString(element(name, value))
+		} else {
+			missing.WriteString(element(name, ""))
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("<D:response><D:href>" + xmlText(res.href) + "</D:href>")
+	if found.Len() > 0 {
+		b.WriteString("<D:propstat><D:prop>" + found.String() + "</D:prop><D:status>HTTP/1.1 200 OK</D:status></D:propstat>")
+	}
+	if missing.Len() > 0 {
+		b.WriteString("<D:propstat><D:prop>" + missing.String() + "</D:prop><D:status>HTTP/1.1 404 Not Found</D:status></D:propstat>")
+	}
+	b.WriteString("</D:response>")
+	return b.String()
+}
+
+func notFoundResponse(href string) string {
+	return "<D:response><D:href>" + xmlText(href) + "</D:href><D:status>HTTP/1.1 404 Not Found</D:status></D:response>"
+}
+
+// element renders a property with the prefix declared in writeMultistatus,
+// declaring unknown namespaces inline
+func element(name xml.Name, inner string) string {
+	var tag, decl string
+	switch name.Space {
+	case nsDAV:
+		tag = "D:" + name.Local
+	case nsCalDAV:
+		tag = "C:" + name.Local
+	case nsCS:
+		tag = "CS:" + name.Local
+	default:
+		tag = "X:" + name.Local
+		decl = fmt.Sprintf(` xmlns:X="%s"`, xmlText(name.Space))
+	}
+	if inner == "" {
+		return "<" + tag + decl + "/>"
+	}
+	return "<" + tag + decl + ">" + inner + "</" + tag + ">"
+}
+
+func xmlText(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func requestedNames(p *propList) []xml.Name {
+	if p == nil {
+		return nil
+	}
+	names := make([]xml.Name, 0, len(p.Names))
+	for _, n := range p.Names {
+		names = append(names, n.XMLName)
+	}
+	return names
+}
+
+func (h *Handler) propfind(w http.ResponseWriter, r *http.Request) {
+	var req propfindRequest
+	if err := readXML(r, &req); err != nil {
+		http.Error(w, "Invalid PROPFIND body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	requested := requestedNames(req.Prop)
+	depth := r.Header.Get("Depth")
+
+	h.mu.Lock()
+	list, ok := h.load(w)
+	h.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	var responses []string
+	switch r.URL.Path {
+	case "/":
+		responses = append(responses, rootResource(h.DisplayName).render(requested))
+		if depth != "0" {
+			responses = append(responses, collectionResource(h.DisplayName, list).render(requested))
+		}
+	case CollectionPath:
+		responses = append(responses, collectionResource(h.DisplayName, list).render(requested))
+		if depth != "0" {
+			for _, item := range list.Items {
+				responses = append(responses, itemResource(item).render(requested))
+			}
+		}
+	default:
+		index := list.IndexOf(resourceID(r.URL.Path))
+		if index < 0 {
+			http.NotFound(w, r)
+			return
+		}
+		responses = append(responses, itemResource(list.Items[index]).render(requested))
+	}
+
+	writeMultistatus(w, responses)
+}
+
+func (h *Handler) report(w http.ResponseWriter, r *http.Request) {
+	var req reportRequest
+	if err := readXML(r, &req); err != nil {
+		http.Error(w, "Invalid REPORT body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	requested := requestedNames(req.Prop)
+	if requested == nil {
+		requested = []xml.Name{propGetETag, propCalendarData}
+	}
+
+	h.mu.Lock()
+	list, ok := h.load(w)
+	h.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	var responses []string
+	switch req.XMLName {
+	case xml.Name{Space: nsCalDAV, Local: "calendar-query"}:
+		for _, item := range list.Items {
+			if req.Filter == nil || matchCalendar(*req.Filter, item) {
+				responses = append(responses, itemResource(item).render(requested))
+			}
+		}
+
+	case xml.Name{Space: nsCalDAV, Local: "calendar-multiget"}:
+		for _, href := range req.Hrefs {
+			p := strings.TrimSpace(href)
+			if u, err := url.Parse(p); err == nil {
+				p = u.Path
+			}
+			index := list.IndexOf(resourceID(p))
+			if index < 0 {
+				responses = append(responses, notFoundResponse(href))
+				continue
+			}
+			responses = append(responses, itemResource(list.Items[index]).render(requested))
+		}
+
+	default:
+		http.Error(w, "Unsupported report: "+req.XMLName.Local, http.StatusForbidden)
+		return
+	}
+
+	writeMultistatus(w, responses)
+}
+
+// matchCalendar evaluates a calendar-query filter, whose top level must be VCALENDAR
+func matchCalendar(f compFilter, item todo.Item) bool {
+	if !strings.EqualFold(f.Name, "VCALENDAR") {
+		return false
+	}
+	for _, child := range f.CompFilters {
+		if !matchComponent(child, item) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchComponent(f compFilter, item todo.Item) bool {
+	if !strings.EqualFold(f.Name, "VTODO") {
+		// The only component we store is a VTODO
+		return f.IsNotDefined != nil
+	}
+	if f.IsNotDefined != nil {
+		return false
+	}
+	if f.TimeRange != nil && !matchTimeRange(*f.TimeRange, item) {
+		return false
+	}
+
+	props := itemProperties(item)
+	for _, pf := range f.PropFilters {
+		if !matchProperty(pf, props[strings.ToUpper(pf.Name)]) {
+			return false
+		}
+	}
+	// Nested components (e.g. VALARM) are never stored
+	for _, child := range f.CompFilters {
+		if child.IsNotDefined == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func matchProperty(f propFilter, values []string) bool {
+	if f.IsNotDefined != nil {
+		return len(values) == 0
+	}
+	if len(values) == 0 {
+		return false
+	}
+	if f.TextMatch == nil {
+		return true
+	}
+
+	needle := strings.ToLower(f.TextMatch.Value)
+	matched := false
+	for _, v := range values {
+		if strings.Contains(strings.ToLower(v), needle) {
+			matched = true
+			break
+		}
+	}
+	if f.TextMatch.NegateCondition == "yes" {
+		return !matched
+	}
+	return matched
+}
+
+// matchTimeRange checks the item's due date against the range. Items without a
+// due date match any range.
+func matchTimeRange(tr timeRange, item todo.Item) bool {
+	if item.DueDate == nil {
+		return true
+	}
+	if tr.Start != "" {
+		if start, err := time.Parse("20060102T150405Z", tr.Start); err == nil && item.DueDate.Before(start) {
+			return false
+		}
+	}
+	if tr.End != "" {
+		if end, err := time.Parse("20060102T150405Z", tr.End); err == nil && !item.DueDate.Before(end) {
+			return false
+		}
+	}
+	return true
+}
+
+// itemProperties returns the unescaped iCalendar property values of an item, keyed by name
+func itemProperties(item todo.Item) map[string][]string {
+	props := make(map[string][]string)
+	lines, _ := ical.ReadLines(strings.NewReader(encodeItem(item)))
+	for _, line := range lines {
+		p, err := ical.ParseProperty(line)
+		if err != nil || p.Name == "BEGIN" || p.Name == "END" {
+			continue
+		}
+		props[p.Name] = append(props[p.Name], ical.UnescapeText(p.Value))
+	}
+	return props
+}
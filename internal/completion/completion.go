@@ -0,0 +1,236 @@
+// Package completion writes shell completion scripts for bash, zsh and fish.
+// Fixed words such as command names are written into the scripts; values
+// that depend on the list, such as item numbers and tags, are fetched when
+// completing by running the program with the hidden Command argument.
+package completion
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// Command is the hidden command scripts run to fetch values; it is
+// followed by a source name and prints one value per line, optionally
+// followed by a tab and a description
+const Command = "__complete"
+
+// Sources of values fetched when completing
+const (
+	SourceItems = "items"
+	SourceTags  = "tags"
+)
+
+// Arg is what one argument of a command completes to: fixed words, or the
+// values from a source
+type Arg struct {
+	Words  []string
+	Source string
+}
+
+// Cmd is a command and its arguments
+type Cmd struct {
+	Name        string
+	Description string
+	Args        []Arg
+}
+
+// Shells are the shells scripts can be written for
+var Shells = []string{"bash", "zsh", "fish"}
+
+// Write writes the completion script for shell, completing program's cmds
+func Write(w io.Writer, shell, program string, cmds []Cmd) error {
+	t, ok := templates[shell]
+	if !ok {
+		return fmt.Errorf("Unknown shell %q (use %s)", shell, strings.Join(Shells, ", "))
+	}
+	return t.Execute(w, spec{Program: program, Cmds: cmds})
+}
+
+// spec is what the templates are executed with
+type spec struct {
+	Program string
+	Cmds    []Cmd
+}
+
+// Cases groups the arguments by what they complete to, for case statements
+func (s spec) Cases() []argCase {
+	var cases []argCase
+	index := map[string]int{}
+	for _, cmd := range s.Cmds {
+		for i, arg := range cmd.Args {
+			key := arg.Source + "\x00" + strings.Join(arg.Words, " ")
+			n, ok := index[key]
+			if !ok {
+				n = len(cases)
+				index[key] = n
+				cases = append(cases, argCase{Arg: arg})
+			}
+			cases[n].Positions = append(cases[n].Positions, position{Cmd: cmd.Name, Index: i + 1})
+		}
+	}
+	return cases
+}
+
+type argCase struct {
+	Arg
+	Positions []position
+}
+
+// position is the Index'th argument of Cmd, counting from 1
+type position struct {
+	Cmd   string
+	Index int
+}
+
+// Func is the name of the shell function doing the completion
+func (s spec) Func() string {
+	return "_" + strings.NewReplacer("-", "_", ".", "_").Replace(s.Program)
+}
+
+var funcs = template.FuncMap{
+	// quote quotes s for any of the shells
+	"quote": func(s string) string {
+		return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+	},
+	// fishQuote quotes s for fish, which escapes quotes in single quotes
+	// with a backslash
+	"fishQuote": func(s string) string {
+		return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+	},
+	// zshDescribe quotes a word and its description for _describe
+	"zshDescribe": func(word, description string) string {
+		s := strings.ReplaceAll(word, ":", `\:`) + ":" + description
+		return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+	},
+	"join": strings.Join,
+}
+
+var templates = map[string]*template.Template{
+	"bash": template.Must(template.New("bash").Funcs(funcs).Parse(bashScript)),
+	"zsh":  template.Must(template.New("zsh").Funcs(funcs).Parse(zshScript)),
+	"fish": template.Must(template.New("fish").Funcs(funcs).Parse(fishScript)),
+}
+
+const bashScript = `# bash completion for {{.Program}}
+# Load it with: source <({{.Program}} completion bash)
+
+{{.Func}}_values() {
+	# stdin is closed so an encrypted list can't prompt for its passphrase
+	"${COMP_WORDS[0]}" ` + Command + ` "$1" </dev/null 2>/dev/null
+}
+
+{{.Func}}() {
+	local cur=${COMP_WORDS[COMP_CWORD]} values line
+	COMPREPLY=()
+	if (( COMP_CWORD == 1 )); then
+		COMPREPLY=($(compgen -W "{{range $i, $c := .Cmds}}{{if $i}} {{end}}{{$c.Name}}{{end}}" -- "$cur"))
+		return
+	fi
+
+	case "${COMP_WORDS[1]}:$((COMP_CWORD - 1))" in
+{{- range .Cases}}
+	{{range $i, $p := .Positions}}{{if $i}}|{{end}}{{$p.Cmd}}:{{$p.Index}}{{end}})
+		{{if .Source}}values=$({{$.Func}}_values {{.Source}}){{else}}values={{join .Words "\n" | quote}}{{end}} ;;
+{{- end}}
+	*)
+		return ;;
+	esac
+
+	local IFS=$'\n' matches=()
+	for line in $values; do
+		[[ ${line%%$'\t'*} == "$cur"* ]] && matches+=("$line")
+	done
+	if (( ${#matches[@]} == 1 )); then
+		COMPREPLY=("${matches[0]%%$'\t'*}")
+		return
+	fi
+	# Show descriptions, such as the text of items, when there is a choice
+	for line in "${matches[@]}"; do
+		if [[ $line == *$'\t'* ]]; then
+			COMPREPLY+=("${line%%$'\t'*}  (${line#*$'\t'})")
+		else
+			COMPREPLY+=("$line")
+		fi
+	done
+}
+
+complete -o default -F {{.Func}} {{.Program}}
+`
+
+const zshScript = `#compdef {{.Program}}
+# zsh completion for {{.Program}}
+# Load it with: source <({{.Program}} completion zsh)
+# or save it as _{{.Program}} in a directory on $fpath
+
+# {{.Func}}_values adds the values from source $1 as completions
+{{.Func}}_values() {
+	local -a values
+	local line
+	# stdin is closed so an encrypted list can't prompt for its passphrase
+	for line in ${(f)"$($words[1] ` + Command + ` $1 </dev/null 2>/dev/null)"}; do
+		line=${line//:/\\:}
+		values+=("${line/$'\t'/:}")
+	done
+	_describe $1 values
+}
+
+{{.Func}}() {
+	local -a values
+	if (( CURRENT == 2 )); then
+		values=(
+{{- range .Cmds}}
+			{{zshDescribe .Name .Description}}
+{{- end}}
+		)
+		_describe -t commands command values
+		return
+	fi
+
+	case "$words[2]:$((CURRENT - 2))" in
+{{- range .Cases}}
+	({{range $i, $p := .Positions}}{{if $i}}|{{end}}{{$p.Cmd}}:{{$p.Index}}{{end}})
+{{- if .Source}}
+		{{$.Func}}_values {{.Source}} ;;
+{{- else}}
+		values=({{range $i, $w := .Words}}{{if $i}} {{end}}{{quote $w}}{{end}})
+		_describe value values ;;
+{{- end}}
+{{- end}}
+	(*)
+		_default ;;
+	esac
+}
+
+if [[ $funcstack[1] == {{.Func}} ]]; then
+	{{.Func}} "$@"
+else
+	compdef {{.Func}} {{.Program}}
+fi
+`
+
+const fishScript = `# fish completion for {{.Program}}
+# Load it with: {{.Program}} completion fish | source
+# or save it as ~/.config/fish/completions/{{.Program}}.fish
+
+# {{.Func}}_arg reports whether argument $argv[2] of command $argv[1] is being completed
+function {{.Func}}_arg
+    set -l tokens (commandline -opc)
+    test (count $tokens) -eq (math $argv[2] + 1); and test "$tokens[2]" = $argv[1]
+end
+
+# stdin is closed so an encrypted list can't prompt for its passphrase
+function {{.Func}}_values
+    {{.Program}} ` + Command + ` $argv[1] </dev/null 2>/dev/null
+end
+{{range .Cmds}}
+complete -c {{$.Program}} -f -n __fish_use_subcommand -a {{fishQuote .Name}} -d {{fishQuote .Description}}
+{{- end}}
+{{range .Cases}}
+{{- $arg := .Arg}}
+{{- range .Positions}}
+complete -c {{$.Program}} -f -n '{{$.Func}}_arg {{.Cmd}} {{.Index}}' -a {{if $arg.Source}}'({{$.Func}}_values {{$arg.Source}})'{{else}}{{join $arg.Words " " | fishQuote}}{{end}}
+{{- end}}
+{{- end}}
+`
@@ -0,0 +1,135 @@
+package completion
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var testCmds = []Cmd{
+	{Name: "add", Description: "Add a new task"},
+	{Name: "complete", Description: "Mark item n as completed", Args: []Arg{{Source: SourceItems}}},
+	{Name: "priority", Description: "Set priority: high, medium or low", Args: []Arg{{Source: SourceItems}, {Words: []string{"high", "h", "low"}}}},
+	{Name: "tag", Description: "Add a tag to item n", Args: []Arg{{Source: SourceItems}, {Source: SourceTags}}},
+	{Name: "completion", Description: "Print a completion script", Args: []Arg{{Words: Shells}}},
+}
+
+func script(t *testing.T, shell string) string {
+	t.Helper()
+	var b bytes.Buffer
+	if err := Write(&b, shell, "todo", testCmds); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func TestWriteUnknownShell(t *testing.T) {
+	if err := Write(&bytes.Buffer{}, "tcsh", "todo", testCmds); err == nil {
+		t.Error("Expected an error for an unknown shell")
+	}
+}
+
+func TestBash(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash is not installed")
+	}
+
+	// todo stands in for the program, printing values as the hidden
+	// command would
+	test := script(t, "bash") + `
+todo() {
+	case $2 in
+	items) printf '1\tBuy milk\n2\tWrite report\n12\tCall mum\n' ;;
+	tags) printf 'home\nwork\n' ;;
+	esac
+}
+complete_line() {
+	COMP_WORDS=("$@")
+	COMP_CWORD=$(( $# - 1 ))
+	_todo
+	printf '%s\n' "${COMPREPLY[@]}" --
+}
+complete_line todo pri
+complete_line todo priority 1 h
+complete_line todo complete 1
+complete_line todo complete 2
+complete_line todo tag 1 w
+complete_line todo add ''
+`
+	path := filepath.Join(t.TempDir(), "test.bash")
+	if err := os.WriteFile(path, []byte(test), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command(bash, path).CombinedOutput()
+	if err != nil {
+		t.Fatalf("bash failed: %v\n%s", err, out)
+	}
+
+	expected := []string{
+		"priority",
+		"high\nh",
+		"1  (Buy milk)\n12  (Call mum)",
+		"2",
+		"work",
+		"",
+	}
+	got := strings.Split(strings.TrimSuffix(string(out), "--\n"), "--\n")
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d completions, got:\n%s", len(expected), out)
+	}
+	for i, want := range expected {
+		if strings.TrimSuffix(got[i], "\n") != want {
+			t.Errorf("Completion %d: expected %q, got %q", i, want, got[i])
+		}
+	}
+}
+
+func TestZsh(t *testing.T) {
+	s := script(t, "zsh")
+	for _, want := range []string{
+		"#compdef todo",
+		"'add:Add a new task'",
+		"(complete:1|priority:1|tag:1)",
+		"(priority:2)\n\t\tvalues=('high' 'h' 'low')",
+		"(tag:2)\n\t\t_todo_values tags ;;",
+		"compdef _todo todo",
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("Expected the zsh script to contain %q:\n%s", want, s)
+		}
+	}
+	syntaxCheck(t, "zsh", s)
+}
+
+func TestFish(t *testing.T) {
+	s := script(t, "fish")
+	for _, want := range []string{
+		"complete -c todo -f -n __fish_use_subcommand -a 'add' -d 'Add a new task'",
+		"complete -c todo -f -n '_todo_arg complete 1' -a '(_todo_values items)'",
+		"complete -c todo -f -n '_todo_arg priority 2' -a 'high h low'",
+		"complete -c todo -f -n '_todo_arg completion 1' -a 'bash zsh fish'",
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("Expected the fish script to contain %q:\n%s", want, s)
+		}
+	}
+	syntaxCheck(t, "fish", s)
+}
+
+// syntaxCheck parses the script with shell when it is installed
+func syntaxCheck(t *testing.T, shell, s string) {
+	t.Helper()
+	path, err := exec.LookPath(shell)
+	if err != nil {
+		return
+	}
+	cmd := exec.Command(path, "-n")
+	cmd.Stdin = strings.NewReader(s)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("%s rejected the script: %v\n%s", shell, err, out)
+	}
+}
@@ -0,0 +1,373 @@
+// Package contexts defines GTD-style contexts, such as office or phone, that
+// say where and when tasks can be done. A context may be limited to hours
+// and days of the week and may belong to a location; the current setting
+// says where you are, and tasks whose context isn't active can be hidden.
+package contexts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+// Anywhere is the location of contexts, such as phone, that go wherever you
+// do; they stay active whatever the current setting
+const Anywhere = "anywhere"
+
+// Context is a defined context. Tasks may also name contexts that aren't
+// defined; those behave like a context with no limits and no location.
+type Context struct {
+	Name string `json:"name"`
+	// Location is the place the context belongs to; several contexts may
+	// share one, e.g. office and printer at work. Without one the context
+	// is its own place.
+	Location string `json:"location,omitempty"`
+	// Hours limits the context to a time of day
+	Hours *Hours `json:"hours,omitempty"`
+	// Days limits the context to days of the week
+	Days Days `json:"days,omitempty"`
+}
+
+// Open reports whether now is within the context's hours and days
+func (c Context) Open(now time.Time) bool {
+	day := now.Weekday()
+	if c.Hours != nil {
+		minute := now.Hour()*60 + now.Minute()
+		if !c.Hours.contains(minute) {
+			return false
+		}
+		// After midnight, a window that started the evening before
+		// belongs to that day
+		if c.Hours.Start > c.Hours.End && minute < c.Hours.End {
+			day = (day + 6) % 7
+		}
+	}
+	return len(c.Days) == 0 || c.Days.contains(day)
+}
+
+// String describes the context's limits, e.g. "09:00-17:00 mon-fri at work"
+func (c Context) String() string {
+	var parts []string
+	if c.Hours != nil {
+		parts = append(parts, c.Hours.String())
+	}
+	if len(c.Days) > 0 {
+		parts = append(parts, c.Days.String())
+	}
+	switch c.Location {
+	case "":
+	case Anywhere:
+		parts = append(parts, Anywhere)
+	default:
+		parts = append(parts, "at "+c.Location)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Hours is a time of day, in minutes since midnight. A window past
+// midnight, such as 22:00-02:00, has Start after End.
+type Hours struct {
+	Start, End int
+}
+
+// ParseHours parses a window such as "09:00-17:00"; 24:00 ends at midnight
+func ParseHours(s string) (*Hours, error) {
+	start, end, ok := strings.Cut(s, "-")
+	if !ok {
+		return nil, fmt.Errorf("Invalid hours %q (use e.g. 09:00-17:00)", s)
+	}
+	h := &Hours{}
+	var err error
+	if h.Start, err = parseClock(start); err != nil {
+		return nil, err
+	}
+	if h.End, err = parseClock(end); err != nil {
+		return nil, err
+	}
+	if h.Start == 24*60 {
+		h.Start = 0
+	}
+	if h.End == 0 {
+		h.End = 24 * 60
+	}
+	if h.Start == h.End {
+		return nil, fmt.Errorf("Invalid hours %q: start and end are the same", s)
+	}
+	return h, nil
+}
+
+func parseClock(s string) (int, error) {
+	hour, minute, _ := strings.Cut(strings.TrimSpace(s), ":")
+	h, err := strconv.Atoi(hour)
+	if err != nil || h < 0 || h > 24 {
+		return 0, fmt.Errorf("Invalid time %q (use HH:MM)", s)
+	}
+	m := 0
+	if minute != "" {
+		if m, err = strconv.Atoi(minute); err != nil || m < 0 || m > 59 || (h == 24 && m > 0) {
+			return 0, fmt.Errorf("Invalid time %q (use HH:MM)", s)
+		}
+	}
+	return h*60 + m, nil
+}
+
+func (h Hours) contains(minute int) bool {
+	if h.Start < h.End {
+		return minute >= h.Start && minute < h.End
+	}
+	return minute >= h.Start || minute < h.End
+}
+
+func (h Hours) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", h.Start/60, h.Start%60, h.End/60, h.End%60)
+}
+
+func (h Hours) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+func (h *Hours) UnmarshalText(text []byte) error {
+	parsed, err := ParseHours(string(text))
+	if err != nil {
+		return err
+	}
+	*h = *parsed
+	return nil
+}
+
+// Days are days of the week, kept from Monday to Sunday
+type Days []time.Weekday
+
+// weekOrder is the order days are listed in, from Monday
+var weekOrder = []time.Weekday{
+	time.Monday, time.Tuesday, time.Wednesday, time.Thursday,
+	time.Friday, time.Saturday, time.Sunday,
+}
+
+// ParseDays parses days such as "mon-fri", "sat,sun", "mon,wed-fri",
+// "weekdays" or "weekends"
+func ParseDays(s string) (Days, error) {
+	set := map[time.Weekday]bool{}
+	for _, part := range strings.Split(strings.ToLower(s), ",") {
+		part = strings.TrimSpace(part)
+		switch part {
+		case "weekdays":
+			part = "mon-fri"
+		case "weekends", "weekend":
+			part = "sat-sun"
+		}
+		from, to, isRange := strings.Cut(part, "-")
+		first, err := parseDay(from)
+		if err != nil {
+			return nil, err
+		}
+		last := first
+		if isRange {
+			if last, err = parseDay(to); err != nil {
+				return nil, err
+			}
+		}
+		for day := first; ; day = (day + 1) % 7 {
+			set[day] = true
+			if day == last {
+				break
+			}
+		}
+	}
+	var days Days
+	for _, day := range weekOrder {
+		if set[day] {
+			days = append(days, day)
+		}
+	}
+	return days, nil
+}
+
+func parseDay(s string) (time.Weekday, error) {
+	if len(s) >= 3 {
+		for _, day := range weekOrder {
+			if strings.HasPrefix(strings.ToLower(day.String()), s) {
+				return day, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("Invalid day %q (use e.g. mon, tue or mon-fri)", s)
+}
+
+func (d Days) contains(day time.Weekday) bool {
+	for _, d := range d {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// String lists the days, joining three or more in a row into a range
+func (d Days) String() string {
+	var parts []string
+	for i := 0; i < len(weekOrder); i++ {
+		if !d.contains(weekOrder[i]) {
+			continue
+		}
+		j := i
+		for j+1 < len(weekOrder) && d.contains(weekOrder[j+1]) {
+			j++
+		}
+		switch {
+		case j-i >= 2:
+			parts = append(parts, dayName(weekOrder[i])+"-"+dayName(weekOrder[j]))
+		case j > i:
+			parts = append(parts, dayName(weekOrder[i]), dayName(weekOrder[j]))
+		default:
+			parts = append(parts, dayName(weekOrder[i]))
+		}
+		i = j
+	}
+	return strings.Join(parts, ",")
+}
+
+func dayName(day time.Weekday) string {
+	return strings.ToLower(day.String()[:3])
+}
+
+func (d Days) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Days) UnmarshalText(text []byte) error {
+	days, err := ParseDays(string(text))
+	if err != nil {
+		return err
+	}
+	*d = days
+	return nil
+}
+
+// Config is the defined contexts and the current setting, kept in a JSON
+// file
+type Config struct {
+	path string
+	// Current names the contexts or locations you are in; when it is empty
+	// every context is active within its hours
+	Current  []string  `json:"current,omitempty"`
+	Contexts []Context `json:"contexts,omitempty"`
+}
+
+// Load reads the configuration from path. A missing file yields no
+// contexts.
+func Load(path string) (*Config, error) {
+	c := &Config{path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("Invalid contexts file %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Save writes the configuration back to the file it was loaded from
+func (c *Config) Save() error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0644)
+}
+
+// Lookup returns the context called name, reporting whether it is defined
+func (c *Config) Lookup(name string) (Context, bool) {
+	for _, ctx := range c.Contexts {
+		if ctx.Name == name {
+			return ctx, true
+		}
+	}
+	return Context{Name: name}, false
+}
+
+// Define adds a context, or replaces the one with the same name
+func (c *Config) Define(ctx Context) error {
+	name, err := todo.ContextName(ctx.Name)
+	if err != nil {
+		return err
+	}
+	ctx.Name = name
+	// Locations are chosen with the same names as contexts, so they follow
+	// the same rules
+	if location := strings.TrimSpace(ctx.Location); location != "" {
+		if ctx.Location, err = todo.ContextName(location); err != nil {
+			return fmt.Errorf("Invalid location %q: use a single word", location)
+		}
+	} else {
+		ctx.Location = ""
+	}
+	for i, existing := range c.Contexts {
+		if existing.Name == name {
+			c.Contexts[i] = ctx
+			return nil
+		}
+	}
+	c.Contexts = append(c.Contexts, ctx)
+	sort.Slice(c.Contexts, func(i, j int) bool { return c.Contexts[i].Name < c.Contexts[j].Name })
+	return nil
+}
+
+// Remove removes the context called name
+func (c *Config) Remove(name string) error {
+	for i, ctx := range c.Contexts {
+		if ctx.Name == name {
+			c.Contexts = append(c.Contexts[:i], c.Contexts[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("No context named %s", name)
+}
+
+// IsLocation reports whether name is the location of a defined context
+func (c *Config) IsLocation(name string) bool {
+	for _, ctx := range c.Contexts {
+		if ctx.Location == name {
+			return true
+		}
+	}
+	return false
+}
+
+// current reports whether name is in the current setting
+func (c *Config) current(name string) bool {
+	for _, current := range c.Current {
+		if current == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Active reports whether tasks in context name can be done now: the
+// context is open and, when a current setting is made, it is current, at a
+// current location or anywhere. Tasks without a context are always active.
+func (c *Config) Active(name string, now time.Time) bool {
+	if name == "" {
+		return true
+	}
+	ctx, _ := c.Lookup(name)
+	if !ctx.Open(now) {
+		return false
+	}
+	if len(c.Current) == 0 || ctx.Location == Anywhere {
+		return true
+	}
+	return c.current(ctx.Name) || (ctx.Location != "" && c.current(ctx.Location))
+}
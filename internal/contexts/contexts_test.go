@@ -0,0 +1,197 @@
+package contexts
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Monday 2 June 2025
+func at(day, hour, minute int) time.Time {
+	return time.Date(2025, 6, 2+day, hour, minute, 0, 0, time.Local)
+}
+
+func TestParseHours(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		valid    bool
+	}{
+		{"09:00-17:00", "09:00-17:00", true},
+		{"9-17:30", "09:00-17:30", true},
+		{"22:00-02:00", "22:00-02:00", true},
+		{"18:00-00:00", "18:00-24:00", true},
+		{"09:00", "", false},
+		{"09:00-09:00", "", false},
+		{"25:00-26:00", "", false},
+		{"09:60-10:00", "", false},
+	}
+	for _, tt := range tests {
+		h, err := ParseHours(tt.input)
+		if (err == nil) != tt.valid {
+			t.Errorf("ParseHours(%q): unexpected error %v", tt.input, err)
+			continue
+		}
+		if err == nil && h.String() != tt.expected {
+			t.Errorf("ParseHours(%q) = %s, expected %s", tt.input, h, tt.expected)
+		}
+	}
+}
+
+func TestParseDays(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		valid    bool
+	}{
+		{"mon-fri", "mon-fri", true},
+		{"weekdays", "mon-fri", true},
+		{"Saturday,sun", "sat,sun", true},
+		{"mon,wed-fri", "mon,wed-fri", true},
+		{"fri-mon", "mon,fri-sun", true},
+		{"mon,tue", "mon,tue", true},
+		{"", "", false},
+		{"mo", "", false},
+		{"mon-xyz", "", false},
+	}
+	for _, tt := range tests {
+		days, err := ParseDays(tt.input)
+		if (err == nil) != tt.valid {
+			t.Errorf("ParseDays(%q): unexpected error %v", tt.input, err)
+			continue
+		}
+		if err == nil && days.String() != tt.expected {
+			t.Errorf("ParseDays(%q) = %s, expected %s", tt.input, days, tt.expected)
+		}
+	}
+}
+
+func TestOpen(t *testing.T) {
+	workHours, _ := ParseHours("09:00-17:00")
+	lateHours, _ := ParseHours("22:00-02:00")
+	weekdays, _ := ParseDays("mon-fri")
+
+	office := Context{Name: "office", Hours: workHours, Days: weekdays}
+	late := Context{Name: "gaming", Hours: lateHours, Days: Days{time.Friday}}
+
+	tests := []struct {
+		ctx      Context
+		now      time.Time
+		expected bool
+	}{
+		{office, at(0, 9, 0), true},
+		{office, at(0, 17, 0), false},
+		{office, at(0, 8, 59), false},
+		{office, at(5, 12, 0), false}, // Saturday
+		{late, at(4, 23, 0), true},    // Friday night
+		{late, at(5, 1, 30), true},    // still Friday's window
+		{late, at(4, 1, 30), false},   // Thursday's window
+		{Context{Name: "phone"}, at(6, 3, 0), true},
+	}
+	for _, tt := range tests {
+		if got := tt.ctx.Open(tt.now); got != tt.expected {
+			t.Errorf("%s open at %s: expected %v", tt.ctx.Name, tt.now.Format("Mon 15:04"), tt.expected)
+		}
+	}
+}
+
+func TestActive(t *testing.T) {
+	workHours, _ := ParseHours("09:00-17:00")
+	c := &Config{}
+	for _, ctx := range []Context{
+		{Name: "office", Location: "work", Hours: workHours},
+		{Name: "printer", Location: "work"},
+		{Name: "phone", Location: Anywhere},
+		{Name: "@Home"},
+	} {
+		if err := c.Define(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	noon, evening := at(0, 12, 0), at(0, 20, 0)
+
+	tests := []struct {
+		current  []string
+		context  string
+		now      time.Time
+		expected bool
+	}{
+		{nil, "", noon, true},
+		{nil, "office", noon, true},
+		{nil, "office", evening, false},
+		{nil, "errands", noon, true},
+		{[]string{"home"}, "home", noon, true},
+		{[]string{"home"}, "office", noon, false},
+		{[]string{"home"}, "phone", evening, true},
+		{[]string{"home"}, "errands", noon, false},
+		{[]string{"home"}, "", noon, true},
+		{[]string{"work"}, "office", noon, true},
+		{[]string{"work"}, "printer", evening, true},
+		{[]string{"office"}, "printer", noon, false},
+		{[]string{"office"}, "office", evening, false},
+		{[]string{"home", "errands"}, "errands", noon, true},
+	}
+	for _, tt := range tests {
+		c.Current = tt.current
+		if got := c.Active(tt.context, tt.now); got != tt.expected {
+			t.Errorf("Current %v, context %q at %s: expected active %v", tt.current, tt.context, tt.now.Format("15:04"), tt.expected)
+		}
+	}
+}
+
+func TestConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "contexts.json")
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hours, _ := ParseHours("09:00-17:00")
+	days, _ := ParseDays("mon-fri")
+	if err := c.Define(Context{Name: "office", Location: "Work", Hours: hours, Days: days}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Define(Context{Name: "home"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Define(Context{Name: "two words"}); err == nil {
+		t.Error("Expected an error for an invalid name")
+	}
+	if err := c.Define(Context{Name: "study", Location: "home office"}); err == nil {
+		t.Error("Expected an error for a location that can't be used")
+	}
+	if err := c.Define(Context{Name: "desk", Location: " @Home "}); err != nil {
+		t.Fatal(err)
+	}
+	if desk, _ := c.Lookup("desk"); desk.Location != "home" {
+		t.Errorf("Expected the location to be normalized to home, got %q", desk.Location)
+	}
+	if err := c.Remove("desk"); err != nil {
+		t.Fatal(err)
+	}
+	c.Current = []string{"work"}
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	office, ok := reloaded.Lookup("office")
+	if !ok || office.String() != "09:00-17:00 mon-fri at work" {
+		t.Errorf("Expected office to survive a reload, got %+v", office)
+	}
+	if len(reloaded.Contexts) != 2 || reloaded.Contexts[0].Name != "home" {
+		t.Errorf("Expected contexts sorted by name, got %+v", reloaded.Contexts)
+	}
+	if !reloaded.IsLocation("work") || len(reloaded.Current) != 1 {
+		t.Errorf("Expected the current location work, got %v", reloaded.Current)
+	}
+
+	if err := reloaded.Remove("home"); err != nil {
+		t.Fatal(err)
+	}
+	if err := reloaded.Remove("home"); err == nil {
+		t.Error("Expected an error removing a missing context")
+	}
+}
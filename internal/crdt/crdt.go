@@ -0,0 +1,476 @@
+// Package crdt replicates a todo list between peers that edit it offline.
+// The list is modelled as a conflict-free replicated data type: items and
+// tags are observed-remove sets, the other fields are last-writer-wins
+// registers and tracked time is a counter, so replicas that have seen the
+// same changes hold the same list whatever order they merged them in.
+package crdt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+// Stamp orders writes. Clock is a hybrid logical clock: it never goes back
+// and follows wall time in milliseconds, so later edits usually win. Replica
+// breaks ties.
+type Stamp struct {
+	Clock   uint64 `json:"c"`
+	Replica string `json:"r"`
+}
+
+func (s Stamp) after(o Stamp) bool {
+	if s.Clock != o.Clock {
+		return s.Clock > o.Clock
+	}
+	return s.Replica > o.Replica
+}
+
+// Register holds the value of the latest write
+type Register[T any] struct {
+	Value T     `json:"v"`
+	Stamp Stamp `json:"s"`
+}
+
+func (r *Register[T]) merge(o Register[T]) {
+	if o.Stamp.after(r.Stamp) {
+		*r = o
+	}
+}
+
+// ORSet is an observed-remove set. Every add is tagged with a unique stamp;
+// a remove covers the adds it has seen, so an add concurrent with a remove wins.
+type ORSet struct {
+	Added   map[string][]Stamp `json:"added,omitempty"`
+	Removed map[string][]Stamp `json:"removed,omitempty"`
+}
+
+func (s *ORSet) add(elem string, stamp Stamp) {
+	if s.Added == nil {
+		s.Added = make(map[string][]Stamp)
+	}
+	s.Added[elem] = append(s.Added[elem], stamp)
+}
+
+func (s *ORSet) remove(elem string) {
+	live := s.live(elem)
+	if len(live) == 0 {
+		return
+	}
+	if s.Removed == nil {
+		s.Removed = make(map[string][]Stamp)
+	}
+	s.Removed[elem] = append(s.Removed[elem], live...)
+}
+
+// live returns the adds of elem that have not been removed
+func (s *ORSet) live(elem string) []Stamp {
+	var live []Stamp
+	for _, stamp := range s.Added[elem] {
+		if !containsStamp(s.Removed[elem], stamp) {
+			live = append(live, stamp)
+		}
+	}
+	return live
+}
+
+func (s *ORSet) contains(elem string) bool {
+	return len(s.live(elem)) > 0
+}
+
+// elements returns the elements in the set, oldest first
+func (s *ORSet) elements() []string {
+	first := make(map[string]Stamp)
+	var elems []string
+	for elem := range s.Added {
+		live := s.live(elem)
+		if len(live) == 0 {
+			continue
+		}
+		oldest := live[0]
+		for _, stamp := range live[1:] {
+			if oldest.after(stamp) {
+				oldest = stamp
+			}
+		}
+		first[elem] = oldest
+		elems = append(elems, elem)
+	}
+	sort.Slice(elems, func(i, j int) bool {
+		return first[elems[j]].after(first[elems[i]])
+	})
+	return elems
+}
+
+func (s *ORSet) merge(o ORSet) {
+	for elem, stamps := range o.Added {
+		for _, stamp := range stamps {
+			if !containsStamp(s.Added[elem], stamp) {
+				s.add(elem, stamp)
+			}
+		}
+	}
+	for elem, stamps := range o.Removed {
+		for _, stamp := range stamps {
+			if !containsStamp(s.Removed[elem], stamp) {
+				if s.Removed == nil {
+					s.Removed = make(map[string][]Stamp)
+				}
+				s.Removed[elem] = append(s.Removed[elem], stamp)
+			}
+		}
+	}
+}
+
+func containsStamp(stamps []Stamp, stamp Stamp) bool {
+	for _, s := range stamps {
+		if s == stamp {
+			return true
+		}
+	}
+	return false
+}
+
+// Counter is a PN-counter: every replica only grows its own totals
+type Counter struct {
+	Inc map[string]time.Duration `json:"inc,omitempty"`
+	Dec map[string]time.Duration `json:"dec,omitempty"`
+}
+
+// Value returns the counter's total
+func (c Counter) Value() time.Duration {
+	var total time.Duration
+	for _, d := range c.Inc {
+		total += d
+	}
+	for _, d := range c.Dec {
+		total -= d
+	}
+	return total
+}
+
+func (c *Counter) add(replica string, d time.Duration) {
+	if d > 0 {
+		if c.Inc == nil {
+			c.Inc = make(map[string]time.Duration)
+		}
+		c.Inc[replica] += d
+	} else if d < 0 {
+		if c.Dec == nil {
+			c.Dec = make(map[string]time.Duration)
+		}
+		c.Dec[replica] -= d
+	}
+}
+
+func (c *Counter) merge(o Counter) {
+	for replica, d := range o.Inc {
+		if d > c.Inc[replica] {
+			c.add(replica, d-c.Inc[replica])
+		}
+	}
+	for replica, d := range o.Dec {
+		if d > c.Dec[replica] {
+			c.add(replica, -(d - c.Dec[replica]))
+		}
+	}
+}
+
+// Completion is the done flag with the time it was set
+type Completion struct {
+	Done        bool       `json:"done"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// ItemState is the replicated state of one item
+type ItemState struct {
+	CreatedAt time.Time                `json:"created"`
+	Text      Register[string]         `json:"text"`
+	Done      Register[Completion]     `json:"done"`
+	Priority  Register[todo.Priority]  `json:"priority"`
+	DueDate   Register[*time.Time]     `json:"due"`
+	Estimate  Register[*todo.Estimate] `json:"estimate"`
+	Tracked   Counter                  `json:"tracked"`
+	Tags      ORSet                    `json:"tags"`
+	Assignee  Register[string]         `json:"assignee"`
+	Context   Register[string]         `json:"context"`
+	// Creator is set when the item is added and never changes
+	Creator string `json:"creator,omitempty"`
+}
+
+func (s *ItemState) merge(o *ItemState) {
+	if s.CreatedAt.IsZero() || (!o.CreatedAt.IsZero() && o.CreatedAt.Before(s.CreatedAt)) {
+		s.CreatedAt = o.CreatedAt
+	}
+	s.Text.merge(o.Text)
+	s.Done.merge(o.Done)
+	s.Priority.merge(o.Priority)
+	s.DueDate.merge(o.DueDate)
+	s.Estimate.merge(o.Estimate)
+	s.Tracked.merge(o.Tracked)
+	s.Tags.merge(o.Tags)
+	s.Assignee.merge(o.Assignee)
+	s.Context.merge(o.Context)
+	if s.Creator == "" {
+		s.Creator = o.Creator
+	}
+}
+
+func (s *ItemState) item(id string) todo.Item {
+	item := todo.Item{
+		ID:          id,
+		Text:        s.Text.Value,
+		Done:        s.Done.Value.Done,
+		CompletedAt: s.Done.Value.CompletedAt,
+		Priority:    s.Priority.Value,
+		DueDate:     s.DueDate.Value,
+		Estimate:    s.Estimate.Value,
+		Tracked:     s.Tracked.Value(),
+		Tags:        s.Tags.elements(),
+		CreatedAt:   s.CreatedAt,
+		Assignee:    s.Assignee.Value,
+		Context:     s.Context.Value,
+		Creator:     s.Creator,
+	}
+	if item.Tags == nil {
+		item.Tags = []string{}
+	}
+	return item
+}
+
+// State is one replica of the list
+type State struct {
+	path string
+
+	Replica string                `json:"replica"`
+	Clock   uint64                `json:"clock"`
+	IDs     ORSet                 `json:"ids"`
+	Items   map[string]*ItemState `json:"items"`
+}
+
+// NewState returns an empty replica with a new random ID
+func NewState() *State {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return &State{Replica: hex.EncodeToString(b), Items: make(map[string]*ItemState)}
+}
+
+// LoadState reads a replica from path. A missing file yields a new replica.
+func LoadState(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		s := NewState()
+		s.path = path
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	s := &State{path: path}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("Invalid replica state %s: %w", path, err)
+	}
+	if s.Items == nil {
+		s.Items = make(map[string]*ItemState)
+	}
+	return s, nil
+}
+
+// Save writes the replica back to the file it was loaded from
+func (s *State) Save() error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0644)
+}
+
+// tick returns a new stamp for a local write
+func (s *State) tick(now time.Time) Stamp {
+	s.Clock++
+	if ms := uint64(now.UnixMilli()); ms > s.Clock {
+		s.Clock = ms
+	}
+	return Stamp{Clock: s.Clock, Replica: s.Replica}
+}
+
+// Update records the changes between the replica and list as local writes
+func (s *State) Update(list *todo.List, now time.Time) {
+	current := make(map[string]bool, len(list.Items))
+	for _, item := range list.Items {
+		current[item.ID] = true
+		st, known := s.Items[item.ID]
+		if !known {
+			st = &ItemState{CreatedAt: item.CreatedAt, Creator: item.Creator}
+			s.Items[item.ID] = st
+		}
+		if !s.IDs.contains(item.ID) {
+			s.IDs.add(item.ID, s.tick(now))
+		}
+		s.updateItem(st, item, !known, now)
+	}
+	for _, id := range s.IDs.elements() {
+		if !current[id] {
+			s.IDs.remove(id)
+		}
+	}
+}
+
+// updateItem writes the fields of item that differ from st. A new item has
+// every field written.
+func (s *State) updateItem(st *ItemState, item todo.Item, isNew bool, now time.Time) {
+	old := st.item(item.ID)
+	if isNew || old.Text != item.Text {
+		st.Text = Register[string]{item.Text, s.tick(now)}
+	}
+	if isNew || old.Done != item.Done || !sameTime(old.CompletedAt, item.CompletedAt) {
+		st.Done = Register[Completion]{Completion{item.Done, item.CompletedAt}, s.tick(now)}
+	}
+	if isNew || old.Priority != item.Priority {
+		st.Priority = Register[todo.Priority]{item.Priority, s.tick(now)}
+	}
+	if isNew || !sameTime(old.DueDate, item.DueDate) {
+		st.DueDate = Register[*time.Time]{item.DueDate, s.tick(now)}
+	}
+	if isNew || !sameEstimate(old.Estimate, item.Estimate) {
+		st.Estimate = Register[*todo.Estimate]{item.Estimate, s.tick(now)}
+	}
+	if isNew || old.Assignee != item.Assignee {
+		st.Assignee = Register[string]{item.Assignee, s.tick(now)}
+	}
+	if isNew || old.Context != item.Context {
+		st.Context = Register[string]{item.Context, s.tick(now)}
+	}
+	st.Tracked.add(s.Replica, item.Tracked-old.Tracked)
+
+	want := make(map[string]bool, len(item.Tags))
+	for _, tag := range item.Tags {
+		want[tag] = true
+		if !st.Tags.contains(tag) {
+			st.Tags.add(tag, s.tick(now))
+		}
+	}
+	for _, tag := range old.Tags {
+		if !want[tag] {
+			st.Tags.remove(tag)
+		}
+	}
+}
+
+// Merge brings in the changes known to another replica
+func (s *State) Merge(o *State) {
+	if o.Clock > s.Clock {
+		s.Clock = o.Clock
+	}
+	s.IDs.merge(o.IDs)
+	for id, theirs := range o.Items {
+		ours, ok := s.Items[id]
+		if !ok {
+			ours = &ItemState{}
+			s.Items[id] = ours
+		}
+		ours.merge(theirs)
+	}
+}
+
+// List returns the list the replica holds: items in order of creation, with
+// completed items last
+func (s *State) List() *todo.List {
+	list := todo.NewList()
+	for _, id := range s.IDs.elements() {
+		if st, ok := s.Items[id]; ok {
+			list.Items = append(list.Items, st.item(id))
+		}
+	}
+	sort.SliceStable(list.Items, func(i, j int) bool {
+		a, b := list.Items[i], list.Items[j]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+	list.Sort()
+	return list
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func sameEstimate(a, b *todo.Estimate) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// Exchange sends the replica to a peer serving Handler and merges the state
+// the peer sends back, so both end up with the same list. A token, if given,
+// is sent as a bearer token.
+func Exchange(client *http.Client, url, token string, s *State) error {
+	body, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return fmt.Errorf("Peer returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	var peer State
+	if err := json.NewDecoder(resp.Body).Decode(&peer); err != nil {
+		return fmt.Errorf("Invalid response from peer: %w", err)
+	}
+	s.Merge(&peer)
+	return nil
+}
+
+// Handler serves Exchange requests. merge is called with the requesting
+// peer's replica and returns the merged local replica to send back.
+func Handler(merge func(peer *State) (*State, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Use POST to exchange replicas", http.StatusMethodNotAllowed)
+			return
+		}
+		var peer State
+		if err := json.NewDecoder(r.Body).Decode(&peer); err != nil {
+			http.Error(w, "Invalid replica: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		merged, err := merge(&peer)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(merged)
+	})
+}
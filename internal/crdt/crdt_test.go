@@ -0,0 +1,262 @@
+package crdt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http/httptest"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+var start = time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+
+// clone deep copies a replica, as if it had been sent over the wire
+func clone(t *testing.T, s *State) *State {
+	t.Helper()
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &State{}
+	if err := json.Unmarshal(data, c); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func listJSON(t *testing.T, s *State) string {
+	t.Helper()
+	data, err := json.Marshal(s.List().Items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// edit applies a random edit to the replica's list and records it
+func edit(r *rand.Rand, s *State, now time.Time) {
+	list := s.List()
+	n := len(list.Items)
+	i := 0
+	if n > 0 {
+		i = r.Intn(n)
+	}
+	switch op := r.Intn(9); {
+	case op == 0 || n == 0:
+		list.Add(fmt.Sprintf("task %d from %s", r.Intn(1000), s.Replica))
+	case op == 1:
+		list.Complete(i)
+	case op == 2:
+		list.Uncomplete(i)
+	case op == 3:
+		list.Edit(i, fmt.Sprintf("edited %d by %s", r.Intn(1000), s.Replica))
+	case op == 4:
+		list.Delete(i)
+	case op == 5:
+		list.SetPriority(i, todo.Priority(r.Intn(3)))
+	case op == 6:
+		list.AddTag(i, fmt.Sprintf("tag%d", r.Intn(4)))
+	case op == 7:
+		if tags := list.Items[i].Tags; len(tags) > 0 {
+			list.RemoveTag(i, tags[r.Intn(len(tags))])
+		}
+	case op == 8:
+		list.LogTime(i, time.Duration(r.Intn(60)+1)*time.Minute)
+	}
+	s.Update(list, now)
+}
+
+// TestConvergence checks that replicas editing concurrently end up with the
+// same list once each has merged the others, in any order
+func TestConvergence(t *testing.T) {
+	property := func(seed int64) bool {
+		r := rand.New(rand.NewSource(seed))
+
+		// Replicas start from a shared list
+		origin := NewState()
+		for i := 0; i < 4; i++ {
+			edit(r, origin, start)
+		}
+		replicas := make([]*State, 3)
+		for i := range replicas {
+			replicas[i] = clone(t, origin)
+			replicas[i].Replica = fmt.Sprintf("replica-%d", i)
+		}
+
+		// Concurrent edits, sometimes with clocks that tie
+		for round := 0; round < 3; round++ {
+			for _, s := range replicas {
+				for k := r.Intn(5); k > 0; k-- {
+					edit(r, s, start.Add(time.Duration(r.Intn(3))*time.Millisecond))
+				}
+			}
+			// A random pair meets
+			a, b := replicas[r.Intn(3)], replicas[r.Intn(3)]
+			a.Merge(clone(t, b))
+		}
+
+		// Everyone merges everyone else's final state, in a random order
+		snapshots := make([]*State, len(replicas))
+		for i, s := range replicas {
+			snapshots[i] = clone(t, s)
+		}
+		var expected string
+		for i, s := range replicas {
+			for _, j := range r.Perm(len(snapshots)) {
+				if j != i {
+					s.Merge(clone(t, snapshots[j]))
+				}
+			}
+			got := listJSON(t, s)
+			if i == 0 {
+				expected = got
+			} else if got != expected {
+				t.Logf("seed %d: replica %d has\n%s\nexpected\n%s", seed, i, got, expected)
+				return false
+			}
+
+			// Merging again changes nothing
+			s.Merge(clone(t, snapshots[(i+1)%len(snapshots)]))
+			if listJSON(t, s) != expected {
+				t.Logf("seed %d: merge is not idempotent", seed)
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(property, &quick.Config{MaxCount: 200}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMergeCommutes(t *testing.T) {
+	property := func(seed int64) bool {
+		r := rand.New(rand.NewSource(seed))
+		a, b := NewState(), NewState()
+		for i := 0; i < 10; i++ {
+			edit(r, a, start.Add(time.Duration(i)*time.Millisecond))
+			edit(r, b, start.Add(time.Duration(i)*time.Millisecond))
+			if r.Intn(4) == 0 {
+				a.Merge(clone(t, b))
+			}
+		}
+		ab, ba := clone(t, a), clone(t, b)
+		ab.Merge(clone(t, b))
+		ba.Merge(clone(t, a))
+		return listJSON(t, ab) == listJSON(t, ba)
+	}
+	if err := quick.Check(property, &quick.Config{MaxCount: 200}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestConcurrentChanges(t *testing.T) {
+	a := NewState()
+	list := todo.NewList()
+	if err := list.Add("Write report"); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.Add("Old task"); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.AddTag(0, "work"); err != nil {
+		t.Fatal(err)
+	}
+	a.Update(list, start)
+	b := clone(t, a)
+	b.Replica = "b"
+
+	// a renames and untags the report and deletes the old task
+	listA := a.List()
+	listA.Edit(0, "Write the report")
+	listA.RemoveTag(0, "work")
+	listA.Delete(1)
+	listA.LogTime(0, 30*time.Minute)
+	a.Update(listA, start.Add(time.Second))
+
+	// b renames the report later, tags it and completes the old task
+	listB := b.List()
+	listB.Edit(0, "Write the final report")
+	listB.AddTag(0, "urgent")
+	listB.LogTime(0, time.Hour)
+	listB.Complete(1)
+	b.Update(listB, start.Add(2*time.Second))
+
+	a.Merge(clone(t, b))
+	b.Merge(clone(t, a))
+	if listJSON(t, a) != listJSON(t, b) {
+		t.Fatalf("Replicas differ:\n%s\n%s", listJSON(t, a), listJSON(t, b))
+	}
+
+	merged := a.List()
+	if len(merged.Items) != 1 {
+		t.Fatalf("Expected the deleted task to stay deleted, got %+v", merged.Items)
+	}
+	report := merged.Items[0]
+	if report.Text != "Write the final report" {
+		t.Errorf("Expected the later rename to win, got %q", report.Text)
+	}
+	if len(report.Tags) != 1 || report.Tags[0] != "urgent" {
+		t.Errorf("Expected tag changes from both replicas, got %v", report.Tags)
+	}
+	if report.Tracked != 90*time.Minute {
+		t.Errorf("Expected time tracked on both replicas, got %s", report.Tracked)
+	}
+}
+
+func TestExchange(t *testing.T) {
+	server := NewState()
+	serverList := todo.NewList()
+	if err := serverList.Add("From server"); err != nil {
+		t.Fatal(err)
+	}
+	server.Update(serverList, start)
+
+	peer := httptest.NewServer(Handler(func(remote *State) (*State, error) {
+		server.Merge(remote)
+		return server, nil
+	}))
+	defer peer.Close()
+
+	client := NewState()
+	clientList := todo.NewList()
+	if err := clientList.Add("From client"); err != nil {
+		t.Fatal(err)
+	}
+	client.Update(clientList, start)
+
+	if err := Exchange(peer.Client(), peer.URL, "", client); err != nil {
+		t.Fatal(err)
+	}
+	if len(client.List().Items) != 2 || listJSON(t, client) != listJSON(t, server) {
+		t.Errorf("Expected both sides to hold both tasks:\n%s\n%s", listJSON(t, client), listJSON(t, server))
+	}
+}
+
+func TestLoadAndSaveState(t *testing.T) {
+	path := t.TempDir() + "/replica.json"
+	s, err := LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := todo.NewList()
+	if err := list.Add("Task"); err != nil {
+		t.Fatal(err)
+	}
+	s.Update(list, start)
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Replica != s.Replica || listJSON(t, loaded) != listJSON(t, s) {
+		t.Errorf("Replica did not survive a reload")
+	}
+}
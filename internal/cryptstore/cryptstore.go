@@ -0,0 +1,289 @@
+// Package cryptstore keeps the todo list in an encrypted file. The key is
+// derived from a passphrase with Argon2id and the list is sealed with
+// AES-256-GCM.
+package cryptstore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/crypto/argon2"
+
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+// Errors returned when a file can't be decrypted
+var (
+	ErrWrongPassphrase = errors.New("Wrong passphrase")
+	ErrCorrupted       = errors.New("Encrypted file is corrupted")
+	ErrNotEncrypted    = errors.New("File is not encrypted")
+)
+
+// magic starts every encrypted file; the last byte is the format version
+var magic = []byte("TODOENC\x01")
+
+const (
+	saltSize  = 16
+	nonceSize = 12
+	checkSize = 32
+	sumSize   = sha256.Size
+	keySize   = 32
+
+	// header: magic, KDF parameters, salt, nonce
+	headerSize = 8 + 4 + 4 + 1 + saltSize + nonceSize
+)
+
+// Params are the Argon2id parameters used to derive the key
+type Params struct {
+	Time    uint32
+	Memory  uint32 // in KiB
+	Threads uint8
+}
+
+// DefaultParams follow the recommendation in RFC 9106 for memory constrained
+// environments
+var DefaultParams = Params{Time: 3, Memory: 64 * 1024, Threads: 4}
+
+// The file layout is
+//
+//	header | check | ciphertext | sum
+//
+// check is an HMAC of the header with a key derived alongside the encryption
+// key, so a wrong passphrase is told apart from a damaged file. sum is a
+// SHA-256 of everything before it, so damage is found before any key is
+// derived.
+
+// Encrypt seals plaintext with a key derived from passphrase
+func Encrypt(plaintext, passphrase []byte, params Params) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return seal(plaintext, deriveKeys(passphrase, salt, params), salt, params)
+}
+
+// Decrypt opens data sealed by Encrypt
+func Decrypt(data, passphrase []byte) ([]byte, error) {
+	h, err := parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return h.open(deriveKeys(passphrase, h.salt, h.params))
+}
+
+// IsEncrypted reports whether data is in the encrypted format
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, magic)
+}
+
+// IsEncryptedFile reports whether the file at path is encrypted. A missing
+// file is not.
+func IsEncryptedFile(path string) (bool, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	prefix := make([]byte, len(magic))
+	if _, err := io.ReadFull(f, prefix); err != nil {
+		return false, nil
+	}
+	return IsEncrypted(prefix), nil
+}
+
+type keys struct {
+	encrypt []byte
+	check   []byte
+}
+
+func deriveKeys(passphrase, salt []byte, params Params) keys {
+	k := argon2.IDKey(passphrase, salt, params.Time, params.Memory, params.Threads, 2*keySize)
+	return keys{encrypt: k[:keySize], check: k[keySize:]}
+}
+
+func seal(plaintext []byte, k keys, salt []byte, params Params) ([]byte, error) {
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, headerSize+checkSize+len(plaintext)+16+sumSize)
+	out = append(out, magic...)
+	out = binary.BigEndian.AppendUint32(out, params.Time)
+	out = binary.BigEndian.AppendUint32(out, params.Memory)
+	out = append(out, params.Threads)
+	out = append(out, salt...)
+	out = append(out, nonce...)
+	out = append(out, checkMAC(k.check, out)...)
+
+	aead, err := newAEAD(k.encrypt)
+	if err != nil {
+		return nil, err
+	}
+	out = aead.Seal(out, nonce, plaintext, out[:headerSize+checkSize])
+	sum := sha256.Sum256(out)
+	return append(out, sum[:]...), nil
+}
+
+// header is the parsed, checksummed form of an encrypted file
+type header struct {
+	params     Params
+	salt       []byte
+	nonce      []byte
+	check      []byte
+	ad         []byte
+	ciphertext []byte
+}
+
+func parse(data []byte) (header, error) {
+	var h header
+	if !IsEncrypted(data) {
+		return h, ErrNotEncrypted
+	}
+	if len(data) < headerSize+checkSize+sumSize {
+		return h, ErrCorrupted
+	}
+	body, sum := data[:len(data)-sumSize], data[len(data)-sumSize:]
+	if expected := sha256.Sum256(body); !bytes.Equal(expected[:], sum) {
+		return h, ErrCorrupted
+	}
+
+	p := body[len(magic):]
+	h.params.Time = binary.BigEndian.Uint32(p)
+	h.params.Memory = binary.BigEndian.Uint32(p[4:])
+	h.params.Threads = p[8]
+	p = p[9:]
+	h.salt, p = p[:saltSize], p[saltSize:]
+	h.nonce, p = p[:nonceSize], p[nonceSize:]
+	h.check, h.ciphertext = p[:checkSize], p[checkSize:]
+	h.ad = body[:headerSize+checkSize]
+	if h.params.Time == 0 || h.params.Threads == 0 {
+		return h, ErrCorrupted
+	}
+	return h, nil
+}
+
+func (h header) open(k keys) ([]byte, error) {
+	if !hmac.Equal(checkMAC(k.check, h.ad[:headerSize]), h.check) {
+		return nil, ErrWrongPassphrase
+	}
+	aead, err := newAEAD(k.encrypt)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, h.nonce, h.ciphertext, h.ad)
+	if err != nil {
+		return nil, ErrCorrupted
+	}
+	return plaintext, nil
+}
+
+func checkMAC(key, header []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(header)
+	return mac.Sum(nil)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Store keeps the list encrypted in the file at Path. The passphrase is asked
+// for once, when the file is first read or written.
+type Store struct {
+	Path string
+	// Passphrase returns the passphrase, e.g. from the environment or a prompt
+	Passphrase func() ([]byte, error)
+	// Params are used for new files; existing files keep their own
+	Params Params
+
+	salt   []byte
+	params Params
+	keys   keys
+}
+
+// Load decrypts and reads the list. A missing file yields an empty list.
+func (s *Store) Load() (*todo.List, error) {
+	list := todo.NewList()
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return list, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s.Path, err)
+	}
+	if s.keys.encrypt == nil || !bytes.Equal(s.salt, h.salt) || s.params != h.params {
+		passphrase, err := s.Passphrase()
+		if err != nil {
+			return nil, err
+		}
+		s.salt, s.params, s.keys = h.salt, h.params, deriveKeys(passphrase, h.salt, h.params)
+	}
+	plaintext, err := h.open(s.keys)
+	if err != nil {
+		s.keys = keys{}
+		return nil, fmt.Errorf("%s: %w", s.Path, err)
+	}
+	if err := json.Unmarshal(plaintext, list); err != nil {
+		return nil, fmt.Errorf("%s: %w", s.Path, err)
+	}
+	return list, nil
+}
+
+// Save encrypts and writes the list, keeping the key of the file it was
+// loaded from
+func (s *Store) Save(list *todo.List) error {
+	if s.keys.encrypt == nil {
+		passphrase, err := s.Passphrase()
+		if err != nil {
+			return err
+		}
+		params := s.Params
+		if params == (Params{}) {
+			params = DefaultParams
+		}
+		salt := make([]byte, saltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return err
+		}
+		s.salt, s.params, s.keys = salt, params, deriveKeys(passphrase, salt, params)
+	}
+
+	plaintext, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+	data, err := seal(plaintext, s.keys, s.salt, s.params)
+	if err != nil {
+		return err
+	}
+	// Write a new file and rename it over the old one, so a crash never
+	// leaves a half written file that can't be decrypted
+	tmp := s.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.Path)
+}
@@ -0,0 +1,136 @@
+package cryptstore
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+// testParams keep key derivation fast in tests
+var testParams = Params{Time: 1, Memory: 8 * 1024, Threads: 1}
+
+func passphrase(p string) func() ([]byte, error) {
+	return func() ([]byte, error) { return []byte(p), nil }
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	plaintext := []byte(`{"Items":[{"Text":"Call ACME about renewal"}]}`)
+	data, err := Encrypt(plaintext, []byte("correct horse"), testParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(data) || bytes.Contains(data, []byte("ACME")) {
+		t.Fatal("Expected the data to be encrypted")
+	}
+
+	decrypted, err := Decrypt(data, []byte("correct horse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("Expected %s, got %s", plaintext, decrypted)
+	}
+
+	if _, err := Decrypt(data, []byte("wrong")); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Expected ErrWrongPassphrase, got %v", err)
+	}
+	if _, err := Decrypt(plaintext, []byte("correct horse")); !errors.Is(err, ErrNotEncrypted) {
+		t.Errorf("Expected ErrNotEncrypted, got %v", err)
+	}
+}
+
+func TestCorruptionIsNotAWrongPassphrase(t *testing.T) {
+	data, err := Encrypt([]byte("secret list"), []byte("pw"), testParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, offset := range []int{len(magic) + 2, headerSize + 1, len(data) - sumSize - 1, len(data) - 1} {
+		damaged := append([]byte(nil), data...)
+		damaged[offset] ^= 0x40
+		if _, err := Decrypt(damaged, []byte("pw")); !errors.Is(err, ErrCorrupted) {
+			t.Errorf("Flipping byte %d: expected ErrCorrupted, got %v", offset, err)
+		}
+	}
+	if _, err := Decrypt(data[:len(data)-10], []byte("pw")); !errors.Is(err, ErrCorrupted) {
+		t.Errorf("Truncated file: expected ErrCorrupted, got %v", err)
+	}
+}
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.json")
+	asked := 0
+	store := &Store{Path: path, Params: testParams, Passphrase: func() ([]byte, error) {
+		asked++
+		return []byte("pw"), nil
+	}}
+
+	list, err := store.Load()
+	if err != nil || len(list.Items) != 0 {
+		t.Fatalf("Expected an empty list for a missing file, got %v, %v", list, err)
+	}
+	if err := list.Add("Call ACME about renewal"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(list); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(list); err != nil {
+		t.Fatal(err)
+	}
+	if asked != 1 {
+		t.Errorf("Expected the passphrase to be asked for once, got %d", asked)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("ACME")) {
+		t.Error("Task text is stored in the clear")
+	}
+	if encrypted, err := IsEncryptedFile(path); err != nil || !encrypted {
+		t.Errorf("Expected an encrypted file, got %v, %v", encrypted, err)
+	}
+
+	loaded, err := (&Store{Path: path, Passphrase: passphrase("pw")}).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Items) != 1 || loaded.Items[0].ID != list.Items[0].ID {
+		t.Errorf("Unexpected items: %+v", loaded.Items)
+	}
+
+	if _, err := (&Store{Path: path, Passphrase: passphrase("nope")}).Load(); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Expected ErrWrongPassphrase, got %v", err)
+	}
+
+	data[len(data)/2] ^= 1
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&Store{Path: path, Passphrase: passphrase("pw")}).Load(); !errors.Is(err, ErrCorrupted) {
+		t.Errorf("Expected ErrCorrupted, got %v", err)
+	}
+}
+
+func TestStoreReadsPlainListFormat(t *testing.T) {
+	list := todo.NewList()
+	if err := list.Add("Task"); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "todos.json")
+	if err := list.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if encrypted, _ := IsEncryptedFile(path); encrypted {
+		t.Error("A plain list should not look encrypted")
+	}
+	if _, err := (&Store{Path: path, Passphrase: passphrase("pw")}).Load(); !errors.Is(err, ErrNotEncrypted) {
+		t.Errorf("Expected ErrNotEncrypted, got %v", err)
+	}
+}
@@ -0,0 +1,406 @@
+// Package csvio reads and writes todo items as CSV with configurable columns,
+// delimiter, date format and tag separator.
+package csvio
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+// Field is an item attribute a CSV column can hold
+type Field string
+
+const (
+	FieldText     Field = "text"
+	FieldDone     Field = "done"
+	FieldPriority Field = "priority"
+	FieldDue      Field = "due"
+	FieldTags     Field = "tags"
+	FieldEstimate Field = "estimate"
+	FieldCreated  Field = "created"
+)
+
+var knownFields = []Field{FieldText, FieldDone, FieldPriority, FieldDue, FieldTags, FieldEstimate, FieldCreated}
+
+// Column maps a CSV header to an item field
+type Column struct {
+	Header string
+	Field  Field
+}
+
+// Options controls how items are written and read
+type Options struct {
+	// Columns to write, or to read by header. When empty, every field is
+	// written under its own name, and on import headers named like the
+	// fields (e.g. "text", "due") are used.
+	Columns      []Column
+	Delimiter    rune
+	DateFormat   string // Go layout or a pattern such as YYYY-MM-DD
+	TagSeparator string
+}
+
+// DefaultOptions uses commas, ISO dates and semicolon separated tags
+func DefaultOptions() Options {
+	return Options{
+		Delimiter:    ',',
+		DateFormat:   "2006-01-02",
+		TagSeparator: ";",
+	}
+}
+
+// ParseMapping parses a column mapping such as "Title=text,Due=due,Labels=tags"
+func ParseMapping(s string) ([]Column, error) {
+	var columns []Column
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		header, field, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(header) == "" {
+			return nil, fmt.Errorf("Invalid column mapping %q (use Header=field)", pair)
+		}
+		f := Field(strings.ToLower(strings.TrimSpace(field)))
+		if !isKnownField(f) {
+			return nil, fmt.Errorf("Unknown field %q in mapping (known: %s)", field, fieldNames())
+		}
+		columns = append(columns, Column{Header: strings.TrimSpace(header), Field: f})
+	}
+	if len(columns) == 0 {
+		return nil, errors.New("Empty column mapping")
+	}
+	return columns, nil
+}
+
+func isKnownField(f Field) bool {
+	for _, known := range knownFields {
+		if f == known {
+			return true
+		}
+	}
+	return false
+}
+
+func fieldNames() string {
+	names := make([]string, len(knownFields))
+	for i, f := range knownFields {
+		names[i] = string(f)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Layout converts a date pattern such as DD/MM/YYYY to a Go time layout.
+// Go layouts such as 2006-01-02 are returned unchanged.
+func Layout(format string) string {
+	r := strings.NewReplacer(
+		"YYYY", "2006", "YY", "06",
+		"MM", "01", "DD", "02",
+		"hh", "15", "mm", "04", "ss", "05",
+	)
+	return r.Replace(format)
+}
+
+// Export writes a header row followed by one row per item
+func Export(w io.Writer, items []todo.Item, opts Options) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = opts.Delimiter
+	layout := Layout(opts.DateFormat)
+
+	columns := opts.Columns
+	if len(columns) == 0 {
+		for _, f := range knownFields {
+			columns = append(columns, Column{Header: string(f), Field: f})
+		}
+	}
+
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = c.Header
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		record := make([]string, len(columns))
+		for i, c := range columns {
+			record[i] = formatField(item, c.Field, layout, opts.TagSeparator)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatField(item todo.Item, f Field, layout, tagSep string) string {
+	switch f {
+	case FieldText:
+		return item.Text
+	case FieldDone:
+		return strconv.FormatBool(item.Done)
+	case FieldPriority:
+		return strings.ToLower(item.Priority.String())
+	case FieldDue:
+		if item.DueDate == nil {
+			return ""
+		}
+		return item.DueDate.Format(layout)
+	case FieldTags:
+		return strings.Join(item.Tags, tagSep)
+	case FieldEstimate:
+		if item.Estimate == nil {
+			return ""
+		}
+		return item.Estimate.String()
+	case FieldCreated:
+		return item.CreatedAt.Format(layout)
+	}
+	return ""
+}
+
+// Row is an item read from a CSV line
+type Row struct {
+	Line int
+	Item todo.Item
+}
+
+// RowError reports a problem with one CSV line
+type RowError struct {
+	Line   int
+	Column string // empty when the error concerns the whole row
+	Err    error
+}
+
+func (e RowError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("line %d, column %q: %v", e.Line, e.Column, e.Err)
+}
+
+// Import reads items from CSV. Rows that fail validation are reported as
+// RowErrors and skipped; the returned error is only set when the file as a
+// whole cannot be read, e.g. when a mapped column is missing from the header.
+func Import(r io.Reader, opts Options) ([]Row, []RowError, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = opts.Delimiter
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	layout := Layout(opts.DateFormat)
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil, errors.New("CSV file is empty")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	columns, err := resolveColumns(header, opts.Columns)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rows []Row
+	var rowErrs []RowError
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				rowErrs = append(rowErrs, RowError{Line: parseErr.Line, Err: parseErr.Err})
+				continue
+			}
+			return nil, nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		if isBlank(record) {
+			continue
+		}
+
+		item := todo.Item{Priority: todo.PriorityMedium, Tags: []string{}}
+		valid := true
+		for _, c := range columns {
+			if c.index >= len(record) {
+				continue
+			}
+			if err := parseField(&item, c.Field, strings.TrimSpace(record[c.index]), layout, opts.TagSeparator); err != nil {
+				rowErrs = append(rowErrs, RowError{Line: line, Column: c.Header, Err: err})
+				valid = false
+			}
+		}
+		if valid && item.Text == "" {
+			rowErrs = append(rowErrs, RowError{Line: line, Err: errors.New("Task text cannot be empty")})
+			valid = false
+		}
+		if valid {
+			rows = append(rows, Row{Line: line, Item: item})
+		}
+	}
+	return rows, rowErrs, nil
+}
+
+// indexedColumn is a column together with its position in the CSV header
+type indexedColumn struct {
+	Column
+	index int
+}
+
+// resolveColumns finds the position of each column in the header, in header order
+func resolveColumns(header []string, mapping []Column) ([]indexedColumn, error) {
+	positions := make(map[string]int, len(header))
+	for i, h := range header {
+		positions[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+
+	var columns []indexedColumn
+	if len(mapping) == 0 {
+		for _, f := range knownFields {
+			if i, ok := positions[string(f)]; ok {
+				columns = append(columns, indexedColumn{Column{Header: header[i], Field: f}, i})
+			}
+		}
+	} else {
+		for _, c := range mapping {
+			i, ok := positions[strings.ToLower(c.Header)]
+			if !ok {
+				return nil, fmt.Errorf("Column %q not found in CSV header", c.Header)
+			}
+			columns = append(columns, indexedColumn{c, i})
+		}
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		return columns[i].index < columns[j].index
+	})
+
+	hasText := false
+	for _, c := range columns {
+		if c.Field == FieldText {
+			hasText = true
+		}
+	}
+	if !hasText {
+		return nil, errors.New("No column is mapped to the task text (e.g. --map \"Title=text\")")
+	}
+	return columns, nil
+}
+
+func isBlank(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func parseField(item *todo.Item, f Field, value, layout, tagSep string) error {
+	switch f {
+	case FieldText:
+		item.Text = value
+	case FieldDone:
+		done, err := parseBool(value)
+		if err != nil {
+			return err
+		}
+		item.Done = done
+	case FieldPriority:
+		p, err := parsePriority(value)
+		if err != nil {
+			return err
+		}
+		item.Priority = p
+	case FieldDue:
+		if value == "" {
+			return nil
+		}
+		due, err := time.Parse(layout, value)
+		if err != nil {
+			return fmt.Errorf("Invalid date %q (expected format %s)", value, layout)
+		}
+		item.DueDate = &due
+	case FieldTags:
+		for _, tag := range strings.Split(value, tagSep) {
+			tag = strings.TrimSpace(tag)
+			if tag != "" && !item.HasTag(tag) {
+				item.Tags = append(item.Tags, tag)
+			}
+		}
+	case FieldEstimate:
+		if value == "" {
+			return nil
+		}
+		estimate, err := todo.ParseEstimate(value)
+		if err != nil {
+			return err
+		}
+		item.Estimate = &estimate
+	case FieldCreated:
+		if value == "" {
+			return nil
+		}
+		created, err := time.Parse(layout, value)
+		if err != nil {
+			return fmt.Errorf("Invalid date %q (expected format %s)", value, layout)
+		}
+		item.CreatedAt = created
+	}
+	return nil
+}
+
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "", "false", "no", "n", "0", "todo", "open", "pending":
+		return false, nil
+	case "true", "yes", "y", "1", "x", "done", "completed":
+		return true, nil
+	}
+	return false, fmt.Errorf("Invalid done value %q", value)
+}
+
+// parsePriority accepts the values todo.ParsePriority understands, but rejects
+// anything else instead of silently falling back to medium
+func parsePriority(value string) (todo.Priority, error) {
+	if value == "" {
+		return todo.PriorityMedium, nil
+	}
+	if priority, ok := todo.LookupPriority(value); ok {
+		return priority, nil
+	}
+	return todo.PriorityMedium, fmt.Errorf("Invalid priority %q (use high, medium or low)", value)
+}
+
+// Add adds the rows to the list, reporting rows rejected by the list (such as
+// duplicates of existing tasks) as RowErrors. Completed rows get a completion time.
+func Add(list *todo.List, rows []Row) ([]Row, []RowError) {
+	var added []Row
+	var errs []RowError
+	for _, row := range rows {
+		item := row.Item
+		if item.Done && item.CompletedAt == nil {
+			now := time.Now()
+			item.CompletedAt = &now
+		}
+		if err := list.AddItem(item); err != nil {
+			errs = append(errs, RowError{Line: row.Line, Err: fmt.Errorf("%v: %q", err, item.Text)})
+			continue
+		}
+		added = append(added, row)
+	}
+	list.Sort()
+	return added, errs
+}
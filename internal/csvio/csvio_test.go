@@ -0,0 +1,224 @@
+package csvio
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+func TestParseMapping(t *testing.T) {
+	columns, err := ParseMapping("Title=text, Due=due,Labels=TAGS")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []Column{{"Title", FieldText}, {"Due", FieldDue}, {"Labels", FieldTags}}
+	if len(columns) != len(expected) {
+		t.Fatalf("Expected %d columns, got %d", len(expected), len(columns))
+	}
+	for i := range expected {
+		if columns[i] != expected[i] {
+			t.Errorf("Column %d = %+v, expected %+v", i, columns[i], expected[i])
+		}
+	}
+
+	for _, input := range []string{"", "Title", "=text", "Title=colour"} {
+		if _, err := ParseMapping(input); err == nil {
+			t.Errorf("Expected error for mapping %q", input)
+		}
+	}
+}
+
+func TestLayout(t *testing.T) {
+	tests := map[string]string{
+		"YYYY-MM-DD":       "2006-01-02",
+		"DD/MM/YYYY":       "02/01/2006",
+		"MM/DD/YY":         "01/02/06",
+		"YYYY-MM-DD hh:mm": "2006-01-02 15:04",
+		"2006-01-02":       "2006-01-02",
+	}
+	for input, expected := range tests {
+		if got := Layout(input); got != expected {
+			t.Errorf("Layout(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	list := todo.NewList()
+	for _, text := range []string{"Plan sprint", "Review, then merge", "Archive"} {
+		if err := list.Add(text); err != nil {
+			t.Fatal(err)
+		}
+	}
+	due := time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)
+	if err := list.SetDueDate(0, due); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.AddTag(0, "work"); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.AddTag(0, "q1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.SetPriority(1, todo.PriorityHigh); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.SetEstimate(1, todo.Estimate{Points: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.Complete(2); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DefaultOptions()
+	opts.Delimiter = ';'
+	opts.TagSeparator = "|"
+	opts.DateFormat = "DD.MM.YYYY"
+
+	var buf bytes.Buffer
+	if err := Export(&buf, list.Items, opts); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "14.03.2025") || !strings.Contains(buf.String(), "work|q1") {
+		t.Errorf("Unexpected export:\n%s", buf.String())
+	}
+
+	rows, rowErrs, err := Import(&buf, opts)
+	if err != nil || len(rowErrs) > 0 {
+		t.Fatalf("Import failed: %v %v", err, rowErrs)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("Expected 3 rows, got %d", len(rows))
+	}
+
+	first := rows[0].Item
+	if first.Text != "Plan sprint" || first.DueDate == nil || !first.DueDate.Equal(due) {
+		t.Errorf("Unexpected first row: %+v", first)
+	}
+	if strings.Join(first.Tags, ",") != "work,q1" {
+		t.Errorf("Unexpected tags: %v", first.Tags)
+	}
+	second := rows[1].Item
+	if second.Text != "Review, then merge" || second.Priority != todo.PriorityHigh || second.Estimate == nil || second.Estimate.Points != 3 {
+		t.Errorf("Unexpected second row: %+v", second)
+	}
+	if !rows[2].Item.Done {
+		t.Error("Expected third row to be done")
+	}
+}
+
+func TestImportWithMapping(t *testing.T) {
+	input := "Title,Owner,Due,Labels\n" +
+		"Send budget,Ann,2025-04-01,finance; q2\n" +
+		"Book venue,Bob,,events\n"
+
+	columns, err := ParseMapping("Title=text,Due=due,Labels=tags")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := DefaultOptions()
+	opts.Columns = columns
+
+	rows, rowErrs, err := Import(strings.NewReader(input), opts)
+	if err != nil || len(rowErrs) > 0 {
+		t.Fatalf("Import failed: %v %v", err, rowErrs)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 rows, got %d", len(rows))
+	}
+	if rows[0].Item.Text != "Send budget" || strings.Join(rows[0].Item.Tags, ",") != "finance,q2" {
+		t.Errorf("Unexpected first row: %+v", rows[0].Item)
+	}
+	if rows[1].Item.DueDate != nil {
+		t.Error("Empty due date should stay unset")
+	}
+	if rows[0].Line != 2 || rows[1].Line != 3 {
+		t.Errorf("Unexpected line numbers: %d, %d", rows[0].Line, rows[1].Line)
+	}
+}
+
+func TestImportRowErrors(t *testing.T) {
+	input := "text,priority,due,done,estimate\n" +
+		"Good row,high,2025-01-01,no,2h\n" +
+		"Bad priority,urgent,,,\n" +
+		"Bad date,low,01/02/2025,,\n" +
+		",low,,,\n" +
+		"\n" +
+		"Bad done,,,maybe,\n" +
+		"Bad estimate,,,,lots\n"
+
+	rows, rowErrs, err := Import(strings.NewReader(input), DefaultOptions())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(rows) != 1 || rows[0].Item.Text != "Good row" {
+		t.Errorf("Expected only the good row, got %+v", rows)
+	}
+	if len(rowErrs) != 5 {
+		t.Fatalf("Expected 5 row errors, got %d: %v", len(rowErrs), rowErrs)
+	}
+
+	if rowErrs[0].Line != 3 || rowErrs[0].Column != "priority" {
+		t.Errorf("Unexpected first error: %v", rowErrs[0])
+	}
+	if !strings.Contains(rowErrs[1].Error(), `line 4, column "due"`) {
+		t.Errorf("Unexpected error message: %v", rowErrs[1])
+	}
+	if rowErrs[2].Line != 5 || rowErrs[2].Column != "" {
+		t.Errorf("Expected missing text error on line 5, got %v", rowErrs[2])
+	}
+}
+
+func TestImportFatalErrors(t *testing.T) {
+	columns, _ := ParseMapping("Title=text")
+	withMapping := DefaultOptions()
+	withMapping.Columns = columns
+
+	tests := []struct {
+		input string
+		opts  Options
+	}{
+		{"", DefaultOptions()},
+		{"Name,Due\nx,y\n", DefaultOptions()}, // no text column
+		{"Name\nx\n", withMapping},            // mapped header missing
+	}
+	for _, tt := range tests {
+		if _, _, err := Import(strings.NewReader(tt.input), tt.opts); err == nil {
+			t.Errorf("Expected error importing %q", tt.input)
+		}
+	}
+}
+
+func TestAddRespectsDuplicates(t *testing.T) {
+	list := todo.NewList()
+	if err := list.Add("Existing"); err != nil {
+		t.Fatal(err)
+	}
+
+	rows := []Row{
+		{Line: 2, Item: todo.Item{Text: "New"}},
+		{Line: 3, Item: todo.Item{Text: "Existing"}},
+		{Line: 4, Item: todo.Item{Text: "New"}},
+		{Line: 5, Item: todo.Item{Text: "Finished", Done: true}},
+	}
+	added, errs := Add(list, rows)
+
+	if len(added) != 2 {
+		t.Errorf("Expected 2 rows added, got %d", len(added))
+	}
+	if len(errs) != 2 || errs[0].Line != 3 || errs[1].Line != 4 {
+		t.Errorf("Expected duplicate errors on lines 3 and 4, got %v", errs)
+	}
+	if len(list.Items) != 3 {
+		t.Errorf("Expected 3 items in list, got %d", len(list.Items))
+	}
+
+	last := list.Items[len(list.Items)-1]
+	if last.Text != "Finished" || last.CompletedAt == nil {
+		t.Errorf("Expected completed row at the bottom with a completion time, got %+v", last)
+	}
+}
@@ -0,0 +1,67 @@
+// Package filewatch notices when another program changes a file, such as
+// the todo list, by polling its size and modification time.
+package filewatch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Poll calls load once at the start and then whenever the file at path
+// changes, checking every interval until ctx is done. Without a path load
+// is called every interval.
+//
+// The file may be read while another program is writing it, so a load that
+// fails is tried again on the next check before the error is passed to
+// onError. An interval that isn't positive is reported to onError and
+// nothing is watched.
+func Poll(ctx context.Context, path string, interval time.Duration, load func() error, onError func(error)) {
+	if interval <= 0 {
+		onError(fmt.Errorf("Invalid interval %s: must be positive", interval))
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastMod time.Time
+	var lastSize int64 = -1
+	retrying := false
+	for {
+		changed := true
+		if path != "" && !retrying {
+			info, err := os.Stat(path)
+			switch {
+			case err == nil:
+				changed = !info.ModTime().Equal(lastMod) || info.Size() != lastSize
+				lastMod, lastSize = info.ModTime(), info.Size()
+			case os.IsNotExist(err):
+				changed = lastSize != -1
+				lastMod, lastSize = time.Time{}, -1
+			default:
+				onError(err)
+				changed = false
+			}
+		}
+
+		if changed {
+			err := load()
+			switch {
+			case err != nil && !retrying:
+				retrying = true
+			case err != nil:
+				retrying = false
+				onError(err)
+			default:
+				retrying = false
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package filewatch
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// watcher polls a file, counting loads and errors
+type watcher struct {
+	mu     sync.Mutex
+	loads  int
+	errors []error
+	// fail decides whether the nth load fails
+	fail func(n int) bool
+}
+
+func (w *watcher) load() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.loads++
+	if w.fail(w.loads) {
+		return errors.New("unexpected end of JSON input")
+	}
+	return nil
+}
+
+func (w *watcher) onError(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.errors = append(w.errors, err)
+}
+
+func (w *watcher) counts() (int, int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.loads, len(w.errors)
+}
+
+func poll(t *testing.T, path string, w *watcher) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		Poll(ctx, path, 5*time.Millisecond, w.load, w.onError)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+// waitFor waits until loads have happened, then a little longer for any
+// that shouldn't
+func waitFor(t *testing.T, w *watcher, loads int) (int, int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		n, _ := w.counts()
+		if n >= loads {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d loads, got %d", loads, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	return w.counts()
+}
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPollLoadsOnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.json")
+	writeFile(t, path, "[]")
+	w := &watcher{fail: func(int) bool { return false }}
+	poll(t, path, w)
+
+	if loads, _ := waitFor(t, w, 1); loads != 1 {
+		t.Errorf("Expected one load at the start, got %d", loads)
+	}
+	writeFile(t, path, `[{"Text":"Buy milk"}]`)
+	if loads, errs := waitFor(t, w, 2); loads != 2 || errs != 0 {
+		t.Errorf("Expected a load after the change, got %d loads and %d errors", loads, errs)
+	}
+}
+
+func TestPollRetriesPartialWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.json")
+	writeFile(t, path, `[{"Text":`)
+	// The first read catches the file half written; by the next one the
+	// writer has finished without changing the size or time we saw
+	w := &watcher{fail: func(n int) bool { return n == 1 }}
+	poll(t, path, w)
+
+	if loads, errs := waitFor(t, w, 2); loads != 2 || errs != 0 {
+		t.Errorf("Expected one silent retry, got %d loads and %d errors", loads, errs)
+	}
+}
+
+func TestPollReportsAfterRetry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.json")
+	writeFile(t, path, `[{"Text":`)
+	w := &watcher{fail: func(int) bool { return true }}
+	poll(t, path, w)
+
+	if loads, errs := waitFor(t, w, 2); loads != 2 || errs != 1 {
+		t.Errorf("Expected the error after one retry, got %d loads and %d errors", loads, errs)
+	}
+
+	// A later change gets its own retry
+	writeFile(t, path, `[{"Text":"Buy`)
+	if loads, errs := waitFor(t, w, 4); loads != 4 || errs != 2 {
+		t.Errorf("Expected a retry for the new change, got %d loads and %d errors", loads, errs)
+	}
+}
+
+func TestPollRejectsInterval(t *testing.T) {
+	w := &watcher{fail: func(int) bool { return false }}
+	Poll(context.Background(), "", 0, w.load, w.onError)
+	if loads, errs := w.counts(); loads != 0 || errs != 1 {
+		t.Errorf("Expected only an error, got %d loads and %d errors", loads, errs)
+	}
+}
@@ -0,0 +1,271 @@
+// Package gitsync syncs a todo list between machines through a git
+// repository. Each machine commits its list to a local repository and pulls
+// and pushes through a shared remote; divergent lists are merged with
+// todo.Merge rather than as text, so concurrent changes don't conflict.
+package gitsync
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+// FileName is the name of the list in the repository
+const FileName = "todos.json"
+
+const (
+	branch       = "main"
+	remote       = "origin"
+	remoteBranch = "refs/remotes/" + remote + "/" + branch
+)
+
+// Repo is a local git repository holding the list
+type Repo struct {
+	Dir string
+}
+
+// Result describes what a Sync did
+type Result struct {
+	// Committed is set when local changes were committed
+	Committed bool
+	// Pulled is set when changes from the remote were brought in
+	Pulled bool
+	// Merged is set when local and remote changes had to be merged
+	Merged bool
+	// Pushed is set when the remote was updated
+	Pushed bool
+	// Conflicts are changes made differently on both sides; the local
+	// version was kept
+	Conflicts []todo.Conflict
+}
+
+// Init creates the repository if it doesn't exist yet
+func (r Repo) Init() error {
+	if _, err := os.Stat(filepath.Join(r.Dir, ".git")); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(r.Dir, 0755); err != nil {
+		return err
+	}
+	if _, err := r.git("init", "-q"); err != nil {
+		return err
+	}
+	if _, err := r.git("symbolic-ref", "HEAD", "refs/heads/"+branch); err != nil {
+		return err
+	}
+	// Commits need an identity; fall back to a local one if git has none
+	if email, _ := r.git("config", "user.email"); email == "" {
+		host, _ := os.Hostname()
+		if _, err := r.git("config", "user.email", "todo@"+host); err != nil {
+			return err
+		}
+	}
+	if name, _ := r.git("config", "user.name"); name == "" {
+		if _, err := r.git("config", "user.name", "todo"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Remote returns the URL of the remote, or "" if none is configured
+func (r Repo) Remote() string {
+	url, _ := r.git("remote", "get-url", remote)
+	return url
+}
+
+// SetRemote sets the URL of the remote to sync with
+func (r Repo) SetRemote(url string) error {
+	if err := r.Init(); err != nil {
+		return err
+	}
+	if r.Remote() == "" {
+		_, err := r.git("remote", "add", remote, url)
+		return err
+	}
+	_, err := r.git("remote", "set-url", remote, url)
+	return err
+}
+
+// Sync commits list to the repository, merges in changes from the remote and
+// pushes the result. It returns the synced list.
+func (r Repo) Sync(list *todo.List, message string) (*todo.List, Result, error) {
+	var result Result
+	if err := r.Init(); err != nil {
+		return nil, result, err
+	}
+
+	committed, err := r.commit(list, message)
+	if err != nil {
+		return nil, result, err
+	}
+	result.Committed = committed
+
+	if r.Remote() != "" {
+		if err := r.pull(&result); err != nil {
+			return nil, result, err
+		}
+		if err := r.push(&result); err != nil {
+			return nil, result, err
+		}
+	}
+
+	synced, err := r.read("HEAD")
+	return synced, result, err
+}
+
+// commit writes list to the work tree and commits it if it changed
+func (r Repo) commit(list *todo.List, message string) (bool, error) {
+	if err := r.write(list); err != nil {
+		return false, err
+	}
+	if _, err := r.git("add", FileName); err != nil {
+		return false, err
+	}
+	status, err := r.git("status", "--porcelain", "--", FileName)
+	if err != nil || status == "" {
+		return false, err
+	}
+	_, err = r.git("commit", "-q", "-m", message)
+	return err == nil, err
+}
+
+// pull fetches the remote and brings its changes into the local branch
+func (r Repo) pull(result *Result) error {
+	if _, err := r.git("fetch", "-q", remote); err != nil {
+		return err
+	}
+	if !r.exists(remoteBranch) {
+		// Nothing has been pushed yet
+		return nil
+	}
+	if r.isAncestor(remoteBranch, "HEAD") {
+		return nil
+	}
+	result.Pulled = true
+	if r.isAncestor("HEAD", remoteBranch) {
+		_, err := r.git("merge", "-q", "--ff-only", remoteBranch)
+		return err
+	}
+
+	// Both sides have changes: merge the lists, not the text
+	base := todo.NewList()
+	if mergeBase, err := r.git("merge-base", "HEAD", remoteBranch); err == nil {
+		if base, err = r.read(mergeBase); err != nil {
+			return err
+		}
+	}
+	ours, err := r.read("HEAD")
+	if err != nil {
+		return err
+	}
+	theirs, err := r.read(remoteBranch)
+	if err != nil {
+		return err
+	}
+	merged, conflicts := todo.Merge(base, ours, theirs)
+	result.Merged, result.Conflicts = true, conflicts
+
+	if err := r.write(merged); err != nil {
+		return err
+	}
+	if _, err := r.git("add", FileName); err != nil {
+		return err
+	}
+	tree, err := r.git("write-tree")
+	if err != nil {
+		return err
+	}
+	commit, err := r.git("commit-tree", tree, "-p", "HEAD", "-p", remoteBranch, "-m", "Merge todos from "+remote)
+	if err != nil {
+		return err
+	}
+	_, err = r.git("update-ref", "HEAD", commit)
+	return err
+}
+
+func (r Repo) push(result *Result) error {
+	if r.exists(remoteBranch) {
+		head, err := r.git("rev-parse", "HEAD")
+		if err != nil {
+			return err
+		}
+		if remoteHead, _ := r.git("rev-parse", remoteBranch); head == remoteHead {
+			return nil
+		}
+	}
+	if _, err := r.git("push", "-q", remote, "HEAD:refs/heads/"+branch); err != nil {
+		return err
+	}
+	result.Pushed = true
+	return nil
+}
+
+// write saves list to the work tree, one item per line so git diffs stay readable
+func (r Repo) write(list *todo.List) error {
+	var buf bytes.Buffer
+	buf.WriteString("{\"Items\": [")
+	for i, item := range list.Items {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		data, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		buf.WriteString("\n  ")
+		buf.Write(data)
+	}
+	buf.WriteString("\n]}\n")
+	return os.WriteFile(filepath.Join(r.Dir, FileName), buf.Bytes(), 0644)
+}
+
+// read returns the list as of a revision; a revision without the file has an empty list
+func (r Repo) read(rev string) (*todo.List, error) {
+	list := todo.NewList()
+	if !r.exists(rev + ":" + FileName) {
+		return list, nil
+	}
+	data, err := r.git("show", rev+":"+FileName)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(data), list); err != nil {
+		return nil, fmt.Errorf("Invalid %s in %s: %w", FileName, rev, err)
+	}
+	return list, nil
+}
+
+func (r Repo) exists(rev string) bool {
+	_, err := r.git("rev-parse", "-q", "--verify", rev)
+	return err == nil
+}
+
+func (r Repo) isAncestor(a, b string) bool {
+	_, err := r.git("merge-base", "--is-ancestor", a, b)
+	return err == nil
+}
+
+// git runs a git command in the repository and returns its trimmed output
+func (r Repo) git(args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", r.Dir}, args...)...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && stderr.Len() > 0 {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(stderr.String()))
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
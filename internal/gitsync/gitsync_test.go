@@ -0,0 +1,141 @@
+package gitsync
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+// newMachines returns two repositories sharing a bare remote
+func newMachines(t *testing.T) (Repo, Repo) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	remoteDir := filepath.Join(dir, "remote.git")
+	if out, err := exec.Command("git", "init", "-q", "--bare", remoteDir).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, out)
+	}
+
+	a, b := Repo{Dir: filepath.Join(dir, "a")}, Repo{Dir: filepath.Join(dir, "b")}
+	for _, r := range []Repo{a, b} {
+		if err := r.SetRemote(remoteDir); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return a, b
+}
+
+func mustSync(t *testing.T, r Repo, list *todo.List) (*todo.List, Result) {
+	t.Helper()
+	synced, result, err := r.Sync(list, "Update todos")
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	return synced, result
+}
+
+func texts(list *todo.List) map[string]todo.Item {
+	items := make(map[string]todo.Item)
+	for _, item := range list.Items {
+		items[item.Text] = item
+	}
+	return items
+}
+
+func TestSyncBetweenMachines(t *testing.T) {
+	a, b := newMachines(t)
+
+	listA := todo.NewList()
+	for _, text := range []string{"Task 1", "Task 2", "Task 3"} {
+		if err := listA.Add(text); err != nil {
+			t.Fatal(err)
+		}
+	}
+	listA, result := mustSync(t, a, listA)
+	if !result.Committed || !result.Pushed || result.Pulled {
+		t.Errorf("Unexpected first sync: %+v", result)
+	}
+
+	// A new machine with an empty list picks everything up
+	listB, result := mustSync(t, b, todo.NewList())
+	if !result.Pulled || len(listB.Items) != 3 {
+		t.Fatalf("Expected B to pull 3 items, got %+v, %+v", result, listB.Items)
+	}
+
+	// Both machines change different items
+	if err := listA.Complete(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := listA.Delete(listA.IndexOf(texts(listA)["Task 3"].ID)); err != nil {
+		t.Fatal(err)
+	}
+	if err := listB.Edit(1, "Task 2 (edited on B)"); err != nil {
+		t.Fatal(err)
+	}
+	if err := listB.Add("Task 4"); err != nil {
+		t.Fatal(err)
+	}
+
+	listA, _ = mustSync(t, a, listA)
+	listB, result = mustSync(t, b, listB)
+	if !result.Merged || len(result.Conflicts) != 0 {
+		t.Errorf("Expected a clean merge, got %+v", result)
+	}
+	listA, _ = mustSync(t, a, listA)
+
+	for name, list := range map[string]*todo.List{"A": listA, "B": listB} {
+		items := texts(list)
+		if len(list.Items) != 3 || !items["Task 1"].Done || items["Task 2 (edited on B)"].ID == "" || items["Task 4"].ID == "" {
+			t.Errorf("%s: unexpected items after sync: %+v", name, list.Items)
+		}
+		if _, ok := items["Task 3"]; ok {
+			t.Errorf("%s: expected Task 3 to stay deleted", name)
+		}
+	}
+}
+
+func TestSyncConflictKeepsLocal(t *testing.T) {
+	a, b := newMachines(t)
+
+	listA := todo.NewList()
+	if err := listA.Add("Draft"); err != nil {
+		t.Fatal(err)
+	}
+	listA, _ = mustSync(t, a, listA)
+	listB, _ := mustSync(t, b, todo.NewList())
+
+	if err := listA.Edit(0, "Draft from A"); err != nil {
+		t.Fatal(err)
+	}
+	if err := listB.Edit(0, "Draft from B"); err != nil {
+		t.Fatal(err)
+	}
+	mustSync(t, a, listA)
+	listB, result := mustSync(t, b, listB)
+
+	if len(result.Conflicts) != 1 || listB.Items[0].Text != "Draft from B" {
+		t.Errorf("Expected a conflict resolved to the local version, got %+v, %+v", result, listB.Items)
+	}
+}
+
+func TestSyncWithoutRemote(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	r := Repo{Dir: filepath.Join(t.TempDir(), "sync")}
+	list := todo.NewList()
+	if err := list.Add("Local only"); err != nil {
+		t.Fatal(err)
+	}
+	synced, result := mustSync(t, r, list)
+	if !result.Committed || result.Pushed || len(synced.Items) != 1 {
+		t.Errorf("Unexpected result: %+v, %+v", result, synced.Items)
+	}
+	if _, result := mustSync(t, r, synced); result.Committed {
+		t.Error("Expected nothing to commit for an unchanged list")
+	}
+}
@@ -0,0 +1,86 @@
+package grpcserver
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/rahul4507/todo/api/todopb"
+	"github.com/rahul4507/todo/internal/auth"
+)
+
+// readMethods only read the list, so read tokens may call them
+var readMethods = map[string]bool{
+	todopb.TodoService_ListItems_FullMethodName: true,
+	todopb.TodoService_GetStats_FullMethodName:  true,
+	todopb.TodoService_Watch_FullMethodName:     true,
+}
+
+// Auth checks API tokens as auth.Guard does for the web API: tokens are
+// sent as "authorization: Bearer <token>" metadata, read methods need the
+// read scope and the rest need write.
+type Auth struct {
+	// Path is the token file, read on every call. Without any tokens every
+	// call is allowed.
+	Path string
+	// List is the name of the list being served
+	List string
+}
+
+func (a *Auth) check(ctx context.Context, method string) error {
+	tokens, err := auth.Load(a.Path)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	if len(tokens.Tokens) == 0 {
+		return nil
+	}
+
+	var value string
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get("authorization") {
+		if rest, ok := strings.CutPrefix(v, "Bearer "); ok {
+			value = strings.TrimSpace(rest)
+		}
+	}
+	if value == "" {
+		return status.Error(codes.Unauthenticated, "Missing API token")
+	}
+	token, ok := tokens.Verify(value)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "Invalid API token")
+	}
+	if !token.CanAccess(a.List) {
+		return status.Error(codes.PermissionDenied, "Token has no access to list "+a.List)
+	}
+	required := auth.ScopeWrite
+	if readMethods[method] {
+		required = auth.ScopeRead
+	}
+	if token.Scope < required {
+		return status.Error(codes.PermissionDenied, "Token needs the "+required.String()+" scope")
+	}
+	return nil
+}
+
+// ServerOptions returns the interceptors that apply a's checks
+func (a *Auth) ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := a.check(ctx, info.FullMethod); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := a.check(ss.Context(), info.FullMethod); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	}
+}
@@ -0,0 +1,90 @@
+package grpcserver
+
+import (
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/rahul4507/todo/api/todopb"
+	"github.com/rahul4507/todo/internal/rpc"
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+func toPriority(p todo.Priority) todopb.Priority {
+	switch p {
+	case todo.PriorityHigh:
+		return todopb.Priority_PRIORITY_HIGH
+	case todo.PriorityMedium:
+		return todopb.Priority_PRIORITY_MEDIUM
+	case todo.PriorityLow:
+		return todopb.Priority_PRIORITY_LOW
+	}
+	return todopb.Priority_PRIORITY_UNSPECIFIED
+}
+
+// priorityName returns p as rpc.Service expects it, or "" if unspecified
+func priorityName(p todopb.Priority) string {
+	switch p {
+	case todopb.Priority_PRIORITY_HIGH:
+		return "high"
+	case todopb.Priority_PRIORITY_MEDIUM:
+		return "medium"
+	case todopb.Priority_PRIORITY_LOW:
+		return "low"
+	case todopb.Priority_PRIORITY_UNSPECIFIED:
+		return ""
+	}
+	// Unknown values are passed on to be rejected
+	return p.String()
+}
+
+func toItem(item todo.Item) *todopb.Item {
+	pb := &todopb.Item{
+		Id:        item.ID,
+		Text:      item.Text,
+		Done:      item.Done,
+		Priority:  toPriority(item.Priority),
+		Tags:      item.Tags,
+		CreatedAt: timestamppb.New(item.CreatedAt),
+		Assignee:  item.Assignee,
+		Creator:   item.Creator,
+	}
+	if item.DueDate != nil {
+		pb.Due = timestamppb.New(*item.DueDate)
+	}
+	if item.CompletedAt != nil {
+		pb.CompletedAt = timestamppb.New(*item.CompletedAt)
+	}
+	if item.Tracked > 0 {
+		pb.Tracked = durationpb.New(item.Tracked)
+	}
+	if e := item.Estimate; e != nil && e.Points > 0 {
+		pb.Estimate = &todopb.Item_EstimatePoints{EstimatePoints: e.Points}
+	} else if e != nil && e.Duration > 0 {
+		pb.Estimate = &todopb.Item_EstimateTime{EstimateTime: durationpb.New(e.Duration)}
+	}
+	return pb
+}
+
+func toNumbered(r rpc.ItemResult) *todopb.NumberedItem {
+	return &todopb.NumberedItem{Number: int32(r.Number), Item: toItem(r.Item)}
+}
+
+func toRef(ref *todopb.ItemRef) rpc.Ref {
+	switch r := ref.GetRef().(type) {
+	case *todopb.ItemRef_Id:
+		return rpc.Ref{ID: r.Id}
+	case *todopb.ItemRef_Number:
+		return rpc.Ref{Number: int(r.Number)}
+	}
+	return rpc.Ref{}
+}
+
+func toStats(s todo.Stats) *todopb.Stats {
+	return &todopb.Stats{
+		Total:           int32(s.Total),
+		Completed:       int32(s.Completed),
+		Pending:         int32(s.Pending),
+		EstimatedTime:   durationpb.New(s.EstimatedTime),
+		EstimatedPoints: s.EstimatedPoints,
+	}
+}
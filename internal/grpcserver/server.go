@@ -0,0 +1,204 @@
+// Package grpcserver implements the todopb.TodoService gRPC API on top of
+// rpc.Service. Changes made through the API, and changes other programs
+// make to the list, are streamed to watchers.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/rahul4507/todo/api/todopb"
+	"github.com/rahul4507/todo/internal/jsonrpc"
+	"github.com/rahul4507/todo/internal/rpc"
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+// watchBuffer is how many changes a watcher may fall behind before it is
+// dropped
+const watchBuffer = 64
+
+// Server serves a list through rpc.Service
+type Server struct {
+	todopb.UnimplementedTodoServiceServer
+
+	service *rpc.Service
+
+	// mu guards watchers; each is closed when it falls too far behind
+	mu       sync.Mutex
+	watchers map[chan *todopb.Change]bool
+}
+
+// New returns a server for service
+func New(service *rpc.Service) *Server {
+	return &Server{service: service, watchers: make(map[chan *todopb.Change]bool)}
+}
+
+// WatchFile tells watchers when the list file at path is changed by another
+// program, checking every interval until ctx is done
+func (s *Server) WatchFile(ctx context.Context, path string, interval time.Duration, onError func(error)) {
+	s.service.Watch(ctx, path, interval, func(version string) {
+		s.publish(&todopb.Change{Kind: todopb.Change_KIND_RELOADED, Version: version})
+	}, onError)
+}
+
+func (s *Server) publish(change *todopb.Change) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.watchers {
+		select {
+		case ch <- change:
+		default:
+			delete(s.watchers, ch)
+			close(ch)
+		}
+	}
+}
+
+// statusError turns errors from rpc.Service into gRPC errors
+func statusError(err error) error {
+	var rpcErr *jsonrpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.Code == jsonrpc.CodeInvalidParams {
+		return status.Error(codes.InvalidArgument, rpcErr.Message)
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// changed returns the item after a change and tells watchers about it
+func (s *Server) changed(kind todopb.Change_Kind, result rpc.ItemResult, err error) (*todopb.NumberedItem, error) {
+	if err != nil {
+		return nil, statusError(err)
+	}
+	item := toNumbered(result)
+	s.publish(&todopb.Change{Kind: kind, Item: item, Version: s.service.Version()})
+	return item, nil
+}
+
+func (s *Server) ListItems(ctx context.Context, req *todopb.ListItemsRequest) (*todopb.ListItemsResponse, error) {
+	f := req.GetFilter()
+	if _, ok := todopb.Priority_name[int32(f.GetPriority())]; !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid priority %d", f.GetPriority())
+	}
+
+	list, err := s.service.List()
+	if err != nil {
+		return nil, statusError(err)
+	}
+	filter := todo.Filter{
+		Query:    f.GetQuery(),
+		Tag:      f.GetTag(),
+		Assignee: f.GetAssignee(),
+		Overdue:  f.GetOverdue(),
+		Now:      time.Now(),
+	}
+	if f.GetPriority() != todopb.Priority_PRIORITY_UNSPECIFIED {
+		priority := todo.ParsePriority(priorityName(f.GetPriority()))
+		filter.Priority = &priority
+	}
+	switch f.GetStatus() {
+	case todopb.Status_STATUS_PENDING:
+		filter.Status = "pending"
+	case todopb.Status_STATUS_DONE:
+		filter.Status = "done"
+	}
+	resp := &todopb.ListItemsResponse{Version: list.Version}
+	for _, r := range list.Items {
+		if filter.Match(r.Item) {
+			resp.Items = append(resp.Items, toNumbered(r))
+		}
+	}
+	return resp, nil
+}
+
+func (s *Server) AddItem(ctx context.Context, req *todopb.AddItemRequest) (*todopb.NumberedItem, error) {
+	p := rpc.AddParams{Text: req.GetText(), Priority: priorityName(req.GetPriority()), Tags: req.GetTags()}
+	if req.GetDue() != nil {
+		p.Due = req.GetDue().AsTime().Format("2006-01-02")
+	}
+	result, err := s.service.Add(p)
+	return s.changed(todopb.Change_KIND_ADDED, result, err)
+}
+
+func (s *Server) EditItem(ctx context.Context, req *todopb.EditItemRequest) (*todopb.NumberedItem, error) {
+	result, err := s.service.Edit(rpc.EditParams{Ref: toRef(req.GetRef()), Text: req.GetText()})
+	return s.changed(todopb.Change_KIND_UPDATED, result, err)
+}
+
+func (s *Server) CompleteItem(ctx context.Context, ref *todopb.ItemRef) (*todopb.NumberedItem, error) {
+	result, err := s.service.Complete(toRef(ref))
+	return s.changed(todopb.Change_KIND_COMPLETED, result, err)
+}
+
+func (s *Server) UncompleteItem(ctx context.Context, ref *todopb.ItemRef) (*todopb.NumberedItem, error) {
+	result, err := s.service.Uncomplete(toRef(ref))
+	return s.changed(todopb.Change_KIND_UPDATED, result, err)
+}
+
+func (s *Server) DeleteItem(ctx context.Context, ref *todopb.ItemRef) (*todopb.NumberedItem, error) {
+	result, err := s.service.Delete(toRef(ref))
+	return s.changed(todopb.Change_KIND_DELETED, result, err)
+}
+
+func (s *Server) SetPriority(ctx context.Context, req *todopb.SetPriorityRequest) (*todopb.NumberedItem, error) {
+	result, err := s.service.SetPriority(rpc.PriorityParams{Ref: toRef(req.GetRef()), Priority: priorityName(req.GetPriority())})
+	return s.changed(todopb.Change_KIND_UPDATED, result, err)
+}
+
+func (s *Server) SetDueDate(ctx context.Context, req *todopb.SetDueDateRequest) (*todopb.NumberedItem, error) {
+	p := rpc.DueParams{Ref: toRef(req.GetRef())}
+	if req.GetDue() != nil {
+		p.Due = req.GetDue().AsTime().Format("2006-01-02")
+	}
+	result, err := s.service.SetDueDate(p)
+	return s.changed(todopb.Change_KIND_UPDATED, result, err)
+}
+
+func (s *Server) TagItem(ctx context.Context, req *todopb.TagItemRequest) (*todopb.NumberedItem, error) {
+	result, err := s.service.Tag(rpc.TagParams{Ref: toRef(req.GetRef()), Tags: req.GetTags()})
+	return s.changed(todopb.Change_KIND_UPDATED, result, err)
+}
+
+func (s *Server) GetStats(ctx context.Context, req *todopb.GetStatsRequest) (*todopb.Stats, error) {
+	stats, err := s.service.Stats()
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toStats(stats), nil
+}
+
+func (s *Server) Watch(req *todopb.WatchRequest, stream todopb.TodoService_WatchServer) error {
+	ch := make(chan *todopb.Change, watchBuffer)
+	s.mu.Lock()
+	s.watchers[ch] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		if s.watchers[ch] {
+			delete(s.watchers, ch)
+			close(ch)
+		}
+		s.mu.Unlock()
+	}()
+
+	// Let the client know it is watching before any change comes along
+	if err := stream.SendHeader(nil); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case change, ok := <-ch:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "Watcher fell behind; reload and watch again")
+			}
+			if err := stream.Send(change); err != nil {
+				return err
+			}
+		}
+	}
+}
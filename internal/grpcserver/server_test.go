@@ -0,0 +1,269 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/rahul4507/todo/api/todopb"
+	"github.com/rahul4507/todo/internal/auth"
+	"github.com/rahul4507/todo/internal/rpc"
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+func newClient(t *testing.T, opts ...grpc.ServerOption) (todopb.TodoServiceClient, todo.Store) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "todos.json")
+	store := todo.FileStore{Path: path}
+	list := todo.NewList()
+	if err := list.Add("Write report"); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.SetDueDate(0, time.Now().AddDate(0, 0, -1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.Add("Buy milk"); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.AddTag(1, "home"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(list); err != nil {
+		t.Fatal(err)
+	}
+
+	server := New(&rpc.Service{Store: store, User: "alice"})
+	ctx, cancel := context.WithCancel(context.Background())
+	go server.WatchFile(ctx, path, 10*time.Millisecond, func(err error) { t.Error(err) })
+
+	listener := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(opts...)
+	todopb.RegisterTodoServiceServer(srv, server)
+	go srv.Serve(listener)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		srv.Stop()
+		cancel()
+	})
+	return todopb.NewTodoServiceClient(conn), store
+}
+
+func TestItems(t *testing.T) {
+	client, store := newClient(t)
+	ctx := context.Background()
+
+	due := time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC)
+	added, err := client.AddItem(ctx, &todopb.AddItemRequest{
+		Text:     "Fix bug",
+		Priority: todopb.Priority_PRIORITY_HIGH,
+		Due:      timestamppb.New(due),
+		Tags:     []string{"work"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added.Number != 3 || added.Item.Creator != "alice" || !added.Item.Due.AsTime().Equal(due) {
+		t.Errorf("Expected a third task created by alice, got %v", added)
+	}
+
+	_, err = client.AddItem(ctx, &todopb.AddItemRequest{Text: "Fix bug"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for a duplicate, got %v", err)
+	}
+	_, err = client.CompleteItem(ctx, &todopb.ItemRef{Ref: &todopb.ItemRef_Number{Number: 9}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for a missing task, got %v", err)
+	}
+
+	ref := &todopb.ItemRef{Ref: &todopb.ItemRef_Id{Id: added.Item.Id}}
+	if _, err := client.SetPriority(ctx, &todopb.SetPriorityRequest{Ref: ref, Priority: todopb.Priority_PRIORITY_LOW}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.TagItem(ctx, &todopb.TagItemRequest{Ref: ref, Tags: []string{"bugs"}}); err != nil {
+		t.Fatal(err)
+	}
+	edited, err := client.EditItem(ctx, &todopb.EditItemRequest{Ref: ref, Text: "Fix the bug"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edited.Item.Text != "Fix the bug" || edited.Item.Priority != todopb.Priority_PRIORITY_LOW || len(edited.Item.Tags) != 2 {
+		t.Errorf("Expected the edits to stick, got %v", edited)
+	}
+
+	completed, err := client.CompleteItem(ctx, &todopb.ItemRef{Ref: &todopb.ItemRef_Number{Number: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !completed.Item.Done || completed.Item.Text != "Buy milk" || completed.Number != 3 {
+		t.Errorf("Expected Buy milk to be done and last, got %v", completed)
+	}
+
+	list, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 3 || list.Items[1].Text != "Fix the bug" || !list.Items[2].Done {
+		t.Errorf("Expected the changes to be saved, got %+v", list.Items)
+	}
+
+	stats, err := client.GetStats(ctx, &todopb.GetStatsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Total != 3 || stats.Completed != 1 || stats.Pending != 2 {
+		t.Errorf("Expected 3 tasks with 1 done, got %v", stats)
+	}
+}
+
+func TestListItemsFilter(t *testing.T) {
+	client, _ := newClient(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		filter   *todopb.Filter
+		expected []string
+	}{
+		{"all", nil, []string{"Write report", "Buy milk"}},
+		{"query", &todopb.Filter{Query: "HOME"}, []string{"Buy milk"}},
+		{"tag", &todopb.Filter{Tag: "home"}, []string{"Buy milk"}},
+		{"overdue", &todopb.Filter{Overdue: true}, []string{"Write report"}},
+		{"priority", &todopb.Filter{Priority: todopb.Priority_PRIORITY_HIGH}, nil},
+		{"done", &todopb.Filter{Status: todopb.Status_STATUS_DONE}, nil},
+		{"pending", &todopb.Filter{Status: todopb.Status_STATUS_PENDING, Query: "milk"}, []string{"Buy milk"}},
+	}
+	for _, tt := range tests {
+		resp, err := client.ListItems(ctx, &todopb.ListItemsRequest{Filter: tt.filter})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var texts []string
+		for _, item := range resp.Items {
+			texts = append(texts, item.Item.Text)
+		}
+		if len(texts) != len(tt.expected) || (len(texts) > 0 && texts[0] != tt.expected[0]) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, texts)
+		}
+	}
+
+	_, err := client.ListItems(ctx, &todopb.ListItemsRequest{Filter: &todopb.Filter{Priority: 9}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an unknown priority, got %v", err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	client, store := newClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.Watch(ctx, &todopb.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Header(); err != nil {
+		t.Fatal(err)
+	}
+
+	added, err := client.AddItem(ctx, &todopb.AddItemRequest{Text: "Call mum"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	change, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if change.Kind != todopb.Change_KIND_ADDED || change.Item.Item.Id != added.Item.Id || change.Version == "" {
+		t.Errorf("Expected the added task, got %v", change)
+	}
+
+	// Another program changes the file
+	list, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := list.Complete(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(list); err != nil {
+		t.Fatal(err)
+	}
+	change, err = stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if change.Kind != todopb.Change_KIND_RELOADED {
+		t.Errorf("Expected a reload, got %v", change)
+	}
+	resp, err := client.ListItems(ctx, &todopb.ListItemsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Version != change.Version {
+		t.Errorf("Expected version %s, got %s", change.Version, resp.Version)
+	}
+}
+
+func TestAuth(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	tokens, err := auth.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, reader, err := tokens.Create("dashboard", auth.ScopeRead, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, other, err := tokens.Create("other", auth.ScopeWrite, []string{"work"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tokens.Save(); err != nil {
+		t.Fatal(err)
+	}
+	a := &Auth{Path: path, List: "home"}
+	client, _ := newClient(t, a.ServerOptions()...)
+
+	withToken := func(token string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	}
+	if _, err := client.ListItems(context.Background(), &todopb.ListItemsRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated without a token, got %v", err)
+	}
+	if _, err := client.ListItems(withToken(reader), &todopb.ListItemsRequest{}); err != nil {
+		t.Errorf("Expected a read token to list, got %v", err)
+	}
+	if _, err := client.AddItem(withToken(reader), &todopb.AddItemRequest{Text: "Sneaky"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Expected PermissionDenied adding with a read token, got %v", err)
+	}
+	if _, err := client.GetStats(withToken(other), &todopb.GetStatsRequest{}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Expected PermissionDenied for another list's token, got %v", err)
+	}
+
+	stream, err := client.Watch(withToken("todo_bad_token"), &todopb.WatchRequest{})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated watching with a bad token, got %v", err)
+	}
+}
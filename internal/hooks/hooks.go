@@ -0,0 +1,245 @@
+// Package hooks runs user scripts when tasks change, in the spirit of
+// Taskwarrior hooks.
+//
+// Hooks are executables in a hooks directory whose names start with the event
+// they handle, e.g. on-add, on-add-notify or on-complete.log. Scripts for the
+// same event run in name order, each receiving the output of the previous one.
+//
+// A hook reads the item as a JSON object on stdin: on-add gets the new item,
+// on-modify and on-complete get the item before and after the change on two
+// lines. It may print a (modified) item as a JSON line to change it; any
+// other output is shown to the user. Exiting non-zero rejects the change.
+package hooks
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+// Hook events, named after the executables that handle them
+const (
+	OnAdd      = "on-add"
+	OnModify   = "on-modify"
+	OnComplete = "on-complete"
+)
+
+// DefaultTimeout is how long a hook may run when Runner.Timeout is not set
+const DefaultTimeout = 10 * time.Second
+
+// VetoError is returned when a hook rejects a change
+type VetoError struct {
+	Hook    string
+	Message string
+}
+
+func (e *VetoError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("Hook %s rejected the change", e.Hook)
+	}
+	return fmt.Sprintf("Hook %s rejected the change: %s", e.Hook, e.Message)
+}
+
+// Runner finds and runs the hooks in a directory
+type Runner struct {
+	Dir     string
+	Timeout time.Duration
+	// Feedback receives the messages hooks print; nil discards them
+	Feedback io.Writer
+}
+
+// Scripts returns the executables handling event, in the order they run. A
+// missing hooks directory means there are none.
+func (r Runner) Scripts(event string) ([]string, error) {
+	entries, err := os.ReadDir(r.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var scripts []string
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), event) || entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		if info.Mode()&0111 == 0 {
+			continue
+		}
+		scripts = append(scripts, filepath.Join(r.Dir, entry.Name()))
+	}
+	sort.Strings(scripts)
+	return scripts, nil
+}
+
+// Run passes an item through the hooks for event and returns the item as
+// modified by them. before is the item before the change, or nil for on-add.
+func (r Runner) Run(event string, before *todo.Item, after todo.Item) (todo.Item, error) {
+	scripts, err := r.Scripts(event)
+	if err != nil {
+		return after, err
+	}
+	for _, script := range scripts {
+		if after, err = r.runScript(script, event, before, after); err != nil {
+			return after, err
+		}
+	}
+	return after, nil
+}
+
+func (r Runner) runScript(script, event string, before *todo.Item, after todo.Item) (todo.Item, error) {
+	name := filepath.Base(script)
+
+	var stdin bytes.Buffer
+	enc := json.NewEncoder(&stdin)
+	if before != nil {
+		enc.Encode(before)
+	}
+	enc.Encode(after)
+
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, script)
+	cmd.Dir = r.Dir
+	cmd.Stdin = &stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = r.feedback()
+	cmd.Env = append(os.Environ(), "TODO_HOOK_EVENT="+event)
+	// Don't wait forever for children that keep the output open
+	cmd.WaitDelay = time.Second
+
+	runErr := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return after, &VetoError{Hook: name, Message: fmt.Sprintf("timed out after %s", timeout)}
+	}
+
+	var modified *todo.Item
+	var messages []string
+	scanner := bufio.NewScanner(&stdout)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+		case modified == nil && strings.HasPrefix(line, "{"):
+			var item todo.Item
+			if err := json.Unmarshal([]byte(line), &item); err != nil {
+				return after, fmt.Errorf("Hook %s printed invalid JSON: %v", name, err)
+			}
+			modified = &item
+		default:
+			messages = append(messages, line)
+		}
+	}
+
+	if runErr != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(runErr, &exitErr) {
+			return after, fmt.Errorf("Running hook %s: %w", name, runErr)
+		}
+		return after, &VetoError{Hook: name, Message: strings.Join(messages, "; ")}
+	}
+
+	for _, m := range messages {
+		fmt.Fprintf(r.feedback(), "%s: %s\n", name, m)
+	}
+	if modified == nil {
+		return after, nil
+	}
+	// Hooks may change what a task says, not which task it is
+	modified.ID = after.ID
+	modified.CreatedAt = after.CreatedAt
+	if modified.Text == "" {
+		return after, fmt.Errorf("Hook %s returned an item without text", name)
+	}
+	return *modified, nil
+}
+
+func (r Runner) feedback() io.Writer {
+	if r.Feedback == nil {
+		return io.Discard
+	}
+	return r.Feedback
+}
+
+// Observer runs the hooks for the changes saved through a
+// todo.ObservedStore, so they run however the list was changed. Changes
+// hooks make are saved without being run through hooks again; a *VetoError
+// stops the save.
+type Observer struct {
+	Runner
+}
+
+// BeforeSave runs the hooks once for each changed item, passing on the
+// modifications they make
+func (o Observer) BeforeSave(changes []todo.Event) error {
+	seen := map[string]bool{}
+	for i, e := range changes {
+		if seen[e.Item.ID] {
+			continue
+		}
+		seen[e.Item.ID] = true
+		event := eventFor(e)
+		if event == "" {
+			continue
+		}
+		var before *todo.Item
+		if event != OnAdd {
+			before = &e.Previous
+		}
+
+		modified, err := o.Run(event, before, e.Item)
+		if err != nil {
+			return err
+		}
+		for j := i; j < len(changes); j++ {
+			if changes[j].Item.ID == e.Item.ID {
+				changes[j].Item = modified
+			}
+		}
+	}
+	return nil
+}
+
+// AfterSave does nothing; hooks run before the change is saved
+func (Observer) AfterSave([]todo.Event) {}
+
+// eventFor maps a list change to the hook event it triggers, or "" for none
+func eventFor(e todo.Event) string {
+	switch e.Type {
+	case todo.EventAdded:
+		return OnAdd
+	case todo.EventCompleted:
+		return OnComplete
+	case todo.EventDeleted, todo.EventCleared:
+		return ""
+	case todo.EventUpdated:
+		if e.Item.Done && !e.Previous.Done {
+			return OnComplete
+		}
+	}
+	return OnModify
+}
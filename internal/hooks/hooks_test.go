@@ -0,0 +1,201 @@
+package hooks
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+// writeHook creates an executable shell script in dir
+func writeHook(t *testing.T, dir, name, body string) {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+body+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScripts(t *testing.T) {
+	dir := t.TempDir()
+	writeHook(t, dir, "on-add-2", "exit 0")
+	writeHook(t, dir, "on-add-1", "exit 0")
+	writeHook(t, dir, "on-modify", "exit 0")
+	if err := os.WriteFile(filepath.Join(dir, "on-add-disabled"), []byte("#!/bin/sh\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	scripts, err := Runner{Dir: dir}.Scripts(OnAdd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scripts) != 2 || filepath.Base(scripts[0]) != "on-add-1" || filepath.Base(scripts[1]) != "on-add-2" {
+		t.Errorf("Expected on-add-1 and on-add-2, got %v", scripts)
+	}
+
+	if scripts, err := (Runner{Dir: filepath.Join(dir, "missing")}).Scripts(OnAdd); err != nil || len(scripts) != 0 {
+		t.Errorf("Expected no hooks for a missing directory, got %v, %v", scripts, err)
+	}
+}
+
+// observedStore saves a list in dir through the hooks in dir
+func observedStore(t *testing.T, dir string, feedback io.Writer) todo.Store {
+	t.Helper()
+	store := todo.FileStore{Path: filepath.Join(t.TempDir(), "todos.json")}
+	return todo.Observe(store, Observer{Runner{Dir: dir, Feedback: feedback}})
+}
+
+func load(t *testing.T, store todo.Store) *todo.List {
+	t.Helper()
+	list, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return list
+}
+
+func TestOnAddModifiesItem(t *testing.T) {
+	dir := t.TempDir()
+	// Raise the priority of anything mentioning "urgent" and report it
+	writeHook(t, dir, "on-add", `read item
+case "$item" in
+  *urgent*) echo "$item" | sed 's/"Priority":1/"Priority":2/'; echo "raised priority" ;;
+  *) echo "$item" ;;
+esac`)
+
+	var feedback strings.Builder
+	store := observedStore(t, dir, &feedback)
+	list := load(t, store)
+	if err := list.Add("Fix urgent bug"); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.Add("Water plants"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Save(list); err != nil {
+		t.Fatal(err)
+	}
+	if list.Items[0].Priority != todo.PriorityHigh || list.Items[1].Priority != todo.PriorityMedium {
+		t.Errorf("Expected only the urgent task to be raised: %+v", list.Items)
+	}
+	if saved := load(t, store); saved.Items[0].Priority != todo.PriorityHigh {
+		t.Errorf("Expected the raised priority to be saved: %+v", saved.Items)
+	}
+	if feedback.String() != "on-add: raised priority\n" {
+		t.Errorf("Unexpected feedback %q", feedback.String())
+	}
+
+	// The hook's own change is not run through hooks again
+	if err := store.Save(list); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(feedback.String(), "raised") != 1 {
+		t.Errorf("Expected the hook to run once, feedback: %q", feedback.String())
+	}
+}
+
+func TestHookVeto(t *testing.T) {
+	dir := t.TempDir()
+	writeHook(t, dir, "on-complete", `read before
+read after
+echo "finish the review first"
+exit 1`)
+
+	store := observedStore(t, dir, nil)
+	list := load(t, store)
+	if err := list.Add("Ship release"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(list); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.Complete(0); err != nil {
+		t.Fatal(err)
+	}
+
+	err := store.Save(list)
+	var veto *VetoError
+	if !errors.As(err, &veto) {
+		t.Fatalf("Expected a veto, got %v", err)
+	}
+	if veto.Hook != "on-complete" || veto.Message != "finish the review first" {
+		t.Errorf("Unexpected veto: %+v", veto)
+	}
+	if saved := load(t, store); saved.Items[0].Done {
+		t.Error("Expected the rejected change not to be saved")
+	}
+}
+
+func TestOnModifyGetsBeforeAndAfter(t *testing.T) {
+	dir := t.TempDir()
+	log := filepath.Join(dir, "log")
+	writeHook(t, dir, "on-modify", `read before
+read after
+echo "$before" >> "`+log+`"
+echo "$after" >> "`+log+`"`)
+
+	store := observedStore(t, dir, nil)
+	list := load(t, store)
+	if err := list.Add("Draft"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(list); err != nil {
+		t.Fatal(err)
+	}
+	// Replacing the items, as a sync does, runs the hooks too
+	edited := load(t, store)
+	edited.Items[0].Text = "Final"
+	if err := store.Save(edited); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"Text":"Draft"`) || !strings.Contains(lines[1], `"Text":"Final"`) {
+		t.Errorf("Unexpected hook input:\n%s", data)
+	}
+	if saved := load(t, store); saved.Items[0].Text != "Final" {
+		t.Errorf("Expected the change to stand, got %q", saved.Items[0].Text)
+	}
+}
+
+func TestHookTimeout(t *testing.T) {
+	dir := t.TempDir()
+	writeHook(t, dir, "on-add", "exec sleep 5")
+
+	start := time.Now()
+	_, err := Runner{Dir: dir, Timeout: 100 * time.Millisecond}.Run(OnAdd, nil, todo.NewItem("Slow"))
+	var veto *VetoError
+	if !errors.As(err, &veto) || !strings.Contains(veto.Message, "timed out") {
+		t.Errorf("Expected a timeout veto, got %v", err)
+	}
+	if time.Since(start) > 3*time.Second {
+		t.Errorf("Timeout took too long: %s", time.Since(start))
+	}
+}
+
+func TestHookCannotChangeIdentity(t *testing.T) {
+	dir := t.TempDir()
+	writeHook(t, dir, "on-add", `echo '{"ID":"other","Text":"Renamed","Priority":0}'`)
+
+	item := todo.NewItem("Original")
+	modified, err := Runner{Dir: dir}.Run(OnAdd, nil, item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if modified.ID != item.ID || modified.Text != "Renamed" || modified.Priority != todo.PriorityLow {
+		t.Errorf("Unexpected modified item: %+v", modified)
+	}
+}
@@ -0,0 +1,379 @@
+// Package ical converts todo items to and from iCalendar (RFC 5545) VTODO components.
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+const (
+	dateFormat     = "20060102"
+	dateTimeFormat = "20060102T150405Z"
+	localFormat    = "20060102T150405"
+
+	// maxLineOctets is the longest content line allowed before folding
+	maxLineOctets = 75
+
+	prodID = "-//rahul4507//todo//EN"
+)
+
+// Property is a single content line of an iCalendar object
+type Property struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// Encode writes the items as a VCALENDAR containing one VTODO per item
+func Encode(w io.Writer, items []todo.Item) error {
+	bw := bufio.NewWriter(w)
+	writeLine(bw, "BEGIN:VCALENDAR")
+	writeLine(bw, "VERSION:2.0")
+	writeLine(bw, "PRODID:"+prodID)
+	for _, item := range items {
+		encodeItem(bw, item, time.Now())
+	}
+	writeLine(bw, "END:VCALENDAR")
+	return bw.Flush()
+}
+
+// EncodeItem writes a single item as a standalone VCALENDAR
+func EncodeItem(w io.Writer, item todo.Item) error {
+	return Encode(w, []todo.Item{item})
+}
+
+func encodeItem(w *bufio.Writer, item todo.Item, stamp time.Time) {
+	writeLine(w, "BEGIN:VTODO")
+	writeLine(w, "UID:"+EscapeText(item.ID))
+	writeLine(w, "DTSTAMP:"+stamp.UTC().Format(dateTimeFormat))
+	writeLine(w, "CREATED:"+item.CreatedAt.UTC().Format(dateTimeFormat))
+	writeLine(w, "SUMMARY:"+EscapeText(item.Text))
+	writeLine(w, fmt.Sprintf("PRIORITY:%d", encodePriority(item.Priority)))
+
+	if item.DueDate != nil {
+		writeLine(w, "DUE"+formatDue(*item.DueDate))
+	}
+
+	if len(item.Tags) > 0 {
+		escaped := make([]string, len(item.Tags))
+		for i, tag := range item.Tags {
+			escaped[i] = EscapeText(tag)
+		}
+		writeLine(w, "CATEGORIES:"+strings.Join(escaped, ","))
+	}
+
+	if item.Done {
+		writeLine(w, "STATUS:COMPLETED")
+		if item.CompletedAt != nil {
+			writeLine(w, "COMPLETED:"+item.CompletedAt.UTC().Format(dateTimeFormat))
+		}
+	} else {
+		writeLine(w, "STATUS:NEEDS-ACTION")
+	}
+
+	// Fields without a standard property round-trip as extensions
+	if item.Estimate != nil {
+		writeLine(w, "X-TODO-ESTIMATE:"+item.Estimate.String())
+	}
+	if item.Tracked > 0 {
+		writeLine(w, "X-TODO-TRACKED:"+item.Tracked.String())
+	}
+
+	writeLine(w, "END:VTODO")
+}
+
+// formatDue renders a due date as a DATE when it has no time of day, otherwise as a UTC DATE-TIME
+func formatDue(t time.Time) string {
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0 {
+		return ";VALUE=DATE:" + t.Format(dateFormat)
+	}
+	return ":" + t.UTC().Format(dateTimeFormat)
+}
+
+// encodePriority maps to the iCalendar scale where 1 is highest and 9 lowest
+func encodePriority(p todo.Priority) int {
+	switch p {
+	case todo.PriorityHigh:
+		return 1
+	case todo.PriorityLow:
+		return 9
+	default:
+		return 5
+	}
+}
+
+func decodePriority(v int) todo.Priority {
+	switch {
+	case v >= 1 && v <= 4:
+		return todo.PriorityHigh
+	case v >= 6 && v <= 9:
+		return todo.PriorityLow
+	default:
+		// 0 means undefined, 5 is the "normal" middle
+		return todo.PriorityMedium
+	}
+}
+
+// writeLine writes a content line, folding it so no physical line exceeds 75 octets
+func writeLine(w *bufio.Writer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		// never split a multi-byte UTF-8 sequence
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		// continuation lines start with a space, which counts towards the limit
+		limit = maxLineOctets - 1
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
+
+// EscapeText escapes a TEXT value
+func EscapeText(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+// UnescapeText reverses EscapeText
+func UnescapeText(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i == len(s)-1 {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// splitText splits a multi-valued TEXT property on unescaped commas
+func splitText(s string) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case ',':
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// ReadLines unfolds the content lines of an iCalendar stream
+func ReadLines(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// ParseProperty splits a content line into its name, parameters and value
+func ParseProperty(line string) (Property, error) {
+	// The value starts after the first colon that is not inside a quoted parameter
+	colon := -1
+	quoted := false
+	for i := 0; i < len(line); i++ {
+		if line[i] == '"' {
+			quoted = !quoted
+		} else if line[i] == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return Property{}, fmt.Errorf("Invalid iCalendar line: %q", line)
+	}
+
+	head := line[:colon]
+	p := Property{Value: line[colon+1:], Params: map[string]string{}}
+
+	parts := strings.Split(head, ";")
+	p.Name = strings.ToUpper(parts[0])
+	for _, param := range parts[1:] {
+		key, value, ok := strings.Cut(param, "=")
+		if !ok {
+			continue
+		}
+		p.Params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+	return p, nil
+}
+
+// Decode reads every VTODO in an iCalendar stream. Other components such as
+// VEVENT, and components nested in a VTODO such as VALARM, are skipped.
+func Decode(r io.Reader) ([]todo.Item, error) {
+	lines, err := ReadLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []todo.Item
+	var current *todo.Item
+	// depth counts components opened inside the current VTODO
+	depth := 0
+
+	for _, line := range lines {
+		p, err := ParseProperty(line)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case p.Name == "BEGIN" && strings.EqualFold(p.Value, "VTODO") && current == nil:
+			item := todo.Item{Priority: todo.PriorityMedium, Tags: []string{}}
+			current = &item
+			continue
+		case p.Name == "BEGIN" && current != nil:
+			depth++
+			continue
+		case p.Name == "END" && current != nil && depth > 0:
+			depth--
+			continue
+		case p.Name == "END" && strings.EqualFold(p.Value, "VTODO") && current != nil:
+			if current.Text == "" {
+				return nil, errors.New("VTODO without SUMMARY")
+			}
+			items = append(items, *current)
+			current = nil
+			continue
+		}
+
+		if current == nil || depth > 0 {
+			continue
+		}
+		if err := applyProperty(current, p); err != nil {
+			return nil, err
+		}
+	}
+
+	if current != nil {
+		return nil, errors.New("Unterminated VTODO")
+	}
+	return items, nil
+}
+
+func applyProperty(item *todo.Item, p Property) error {
+	switch p.Name {
+	case "UID":
+		item.ID = UnescapeText(p.Value)
+	case "SUMMARY":
+		item.Text = UnescapeText(p.Value)
+	case "PRIORITY":
+		var v int
+		if _, err := fmt.Sscanf(p.Value, "%d", &v); err != nil {
+			return fmt.Errorf("Invalid PRIORITY: %q", p.Value)
+		}
+		item.Priority = decodePriority(v)
+	case "DUE":
+		t, err := ParseTime(p)
+		if err != nil {
+			return err
+		}
+		item.DueDate = &t
+	case "CREATED":
+		t, err := ParseTime(p)
+		if err != nil {
+			return err
+		}
+		item.CreatedAt = t
+	case "COMPLETED":
+		t, err := ParseTime(p)
+		if err != nil {
+			return err
+		}
+		item.CompletedAt = &t
+		item.Done = true
+	case "STATUS":
+		item.Done = strings.EqualFold(p.Value, "COMPLETED")
+		if !item.Done {
+			item.CompletedAt = nil
+		}
+	case "CATEGORIES":
+		for _, tag := range splitText(p.Value) {
+			tag = strings.TrimSpace(UnescapeText(tag))
+			if tag != "" && !item.HasTag(tag) {
+				item.Tags = append(item.Tags, tag)
+			}
+		}
+	case "X-TODO-ESTIMATE":
+		estimate, err := todo.ParseEstimate(p.Value)
+		if err != nil {
+			return err
+		}
+		item.Estimate = &estimate
+	case "X-TODO-TRACKED":
+		d, err := time.ParseDuration(p.Value)
+		if err != nil {
+			return fmt.Errorf("Invalid X-TODO-TRACKED: %q", p.Value)
+		}
+		item.Tracked = d
+	}
+	return nil
+}
+
+// ParseTime parses a DATE or DATE-TIME property value, honouring VALUE=DATE and TZID
+func ParseTime(p Property) (time.Time, error) {
+	v := p.Value
+	if strings.EqualFold(p.Params["VALUE"], "DATE") || len(v) == len(dateFormat) {
+		t, err := time.Parse(dateFormat, v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("Invalid %s date: %q", p.Name, v)
+		}
+		return t, nil
+	}
+
+	if strings.HasSuffix(v, "Z") {
+		t, err := time.Parse(dateTimeFormat, v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("Invalid %s date-time: %q", p.Name, v)
+		}
+		return t, nil
+	}
+
+	loc := time.Local
+	if tzid := p.Params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation(localFormat, v, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Invalid %s date-time: %q", p.Name, v)
+	}
+	return t, nil
+}
@@ -0,0 +1,257 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+func newTestItems(t *testing.T) []todo.Item {
+	t.Helper()
+	list := todo.NewList()
+	for _, text := range []string{"Buy milk, eggs; bread", "Write report", "Done task"} {
+		if err := list.Add(text); err != nil {
+			t.Fatalf("Failed to add item: %v", err)
+		}
+	}
+
+	due := time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+	if err := list.SetDueDate(0, due); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.SetPriority(0, todo.PriorityHigh); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.AddTag(0, "home"); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.AddTag(0, "errands,weekly"); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.SetPriority(1, todo.PriorityLow); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.SetEstimate(1, todo.Estimate{Duration: 2 * time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.LogTime(1, 30*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.Complete(2); err != nil {
+		t.Fatal(err)
+	}
+	return list.Items
+}
+
+func TestRoundTrip(t *testing.T) {
+	items := newTestItems(t)
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, items); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	decoded, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if len(decoded) != len(items) {
+		t.Fatalf("Expected %d items, got %d", len(items), len(decoded))
+	}
+
+	for i, want := range items {
+		got := decoded[i]
+		if got.ID != want.ID || got.Text != want.Text || got.Done != want.Done || got.Priority != want.Priority {
+			t.Errorf("Item %d mismatch:\n got  %+v\n want %+v", i, got, want)
+		}
+		if !got.CreatedAt.Equal(want.CreatedAt.Truncate(time.Second)) {
+			t.Errorf("Item %d CreatedAt = %v, expected %v", i, got.CreatedAt, want.CreatedAt)
+		}
+		if (got.DueDate == nil) != (want.DueDate == nil) || (got.DueDate != nil && !got.DueDate.Equal(*want.DueDate)) {
+			t.Errorf("Item %d DueDate = %v, expected %v", i, got.DueDate, want.DueDate)
+		}
+		if strings.Join(got.Tags, "|") != strings.Join(want.Tags, "|") {
+			t.Errorf("Item %d Tags = %q, expected %q", i, got.Tags, want.Tags)
+		}
+		if (got.CompletedAt == nil) != (want.CompletedAt == nil) {
+			t.Errorf("Item %d CompletedAt = %v, expected %v", i, got.CompletedAt, want.CompletedAt)
+		}
+		if got.Tracked != want.Tracked {
+			t.Errorf("Item %d Tracked = %v, expected %v", i, got.Tracked, want.Tracked)
+		}
+		if (got.Estimate == nil) != (want.Estimate == nil) || (got.Estimate != nil && *got.Estimate != *want.Estimate) {
+			t.Errorf("Item %d Estimate = %v, expected %v", i, got.Estimate, want.Estimate)
+		}
+	}
+}
+
+func TestEncodeProperties(t *testing.T) {
+	items := newTestItems(t)
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, items); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	out := buf.String()
+
+	expected := []string{
+		"BEGIN:VCALENDAR\r\n",
+		"VERSION:2.0\r\n",
+		"SUMMARY:Buy milk\\, eggs\\; bread\r\n",
+		"DUE;VALUE=DATE:20251231\r\n",
+		"PRIORITY:1\r\n",
+		"PRIORITY:9\r\n",
+		"CATEGORIES:home,errands\\,weekly\r\n",
+		"STATUS:NEEDS-ACTION\r\n",
+		"STATUS:COMPLETED\r\n",
+		"COMPLETED:",
+		"END:VCALENDAR\r\n",
+	}
+	for _, s := range expected {
+		if !strings.Contains(out, s) {
+			t.Errorf("Expected output to contain %q, got:\n%s", s, out)
+		}
+	}
+}
+
+func TestLineFolding(t *testing.T) {
+	long := strings.Repeat("Résumé review ", 20)
+	list := todo.NewList()
+	if err := list.Add(long); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, list.Items); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	for _, line := range strings.Split(buf.String(), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Errorf("Line exceeds %d octets (%d): %q", maxLineOctets, len(line), line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("Folding split a UTF-8 sequence: %q", line)
+		}
+	}
+
+	decoded, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if decoded[0].Text != long {
+		t.Errorf("Folded summary did not round-trip:\n got  %q\n want %q", decoded[0].Text, long)
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		raw     string
+		escaped string
+	}{
+		{"plain", "plain"},
+		{"a,b", `a\,b`},
+		{"a;b", `a\;b`},
+		{`back\slash`, `back\\slash`},
+		{"two\nlines", `two\nlines`},
+	}
+
+	for _, tt := range tests {
+		if got := EscapeText(tt.raw); got != tt.escaped {
+			t.Errorf("EscapeText(%q) = %q, expected %q", tt.raw, got, tt.escaped)
+		}
+		if got := UnescapeText(tt.escaped); got != tt.raw {
+			t.Errorf("UnescapeText(%q) = %q, expected %q", tt.escaped, got, tt.raw)
+		}
+	}
+}
+
+func TestDecodeForeignCalendar(t *testing.T) {
+	input := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Example//Other App//EN",
+		"BEGIN:VEVENT",
+		"UID:event-1",
+		"SUMMARY:Not a todo",
+		"END:VEVENT",
+		"BEGIN:VTODO",
+		"UID:abc-123",
+		"SUMMARY:Call the plumber about the",
+		"  leaking tap",
+		"DUE;TZID=Europe/Berlin:20250301T170000",
+		"PRIORITY:3",
+		"CATEGORIES:home",
+		"CATEGORIES:urgent,home",
+		"BEGIN:VALARM",
+		"ACTION:DISPLAY",
+		"SUMMARY:Alarm summary must be ignored",
+		"END:VALARM",
+		"END:VTODO",
+		"BEGIN:VTODO",
+		"SUMMARY:Finished",
+		"STATUS:COMPLETED",
+		"COMPLETED:20250102T030405Z",
+		"PRIORITY:0",
+		"END:VTODO",
+		"END:VCALENDAR",
+	}, "\n")
+
+	items, err := Decode(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 todos, got %d", len(items))
+	}
+
+	first := items[0]
+	if first.ID != "abc-123" {
+		t.Errorf("Unexpected UID: %s", first.ID)
+	}
+	if first.Text != "Call the plumber about the leaking tap" {
+		t.Errorf("Unexpected summary: %q", first.Text)
+	}
+	if first.Priority != todo.PriorityHigh {
+		t.Errorf("Expected PRIORITY:3 to map to HIGH, got %v", first.Priority)
+	}
+	if strings.Join(first.Tags, ",") != "home,urgent" {
+		t.Errorf("Unexpected tags: %v", first.Tags)
+	}
+	if first.DueDate == nil {
+		t.Fatal("Expected due date")
+	}
+	if berlin, err := time.LoadLocation("Europe/Berlin"); err == nil {
+		want := time.Date(2025, 3, 1, 17, 0, 0, 0, berlin)
+		if !first.DueDate.Equal(want) {
+			t.Errorf("DueDate = %v, expected %v", first.DueDate, want)
+		}
+	}
+
+	second := items[1]
+	if !second.Done || second.CompletedAt == nil {
+		t.Errorf("Expected completed todo, got %+v", second)
+	}
+	if second.Priority != todo.PriorityMedium {
+		t.Errorf("Expected undefined priority to map to MEDIUM, got %v", second.Priority)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	inputs := []string{
+		"BEGIN:VCALENDAR\nBEGIN:VTODO\nSUMMARY:x\n",                     // unterminated
+		"BEGIN:VCALENDAR\nBEGIN:VTODO\nUID:1\nEND:VTODO\nEND:VCALENDAR", // no summary
+		"BEGIN:VCALENDAR\nBEGIN:VTODO\nSUMMARY:x\nDUE:tomorrow\nEND:VTODO",
+		"BEGIN:VCALENDAR\nnot a property\nEND:VCALENDAR",
+	}
+
+	for _, input := range inputs {
+		if _, err := Decode(strings.NewReader(input)); err == nil {
+			t.Errorf("Expected error decoding %q", input)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+// Package jsonrpc serves JSON-RPC 2.0 over a stream such as stdin and stdout.
+// Messages are read one per line, or framed with a Content-Length header as
+// editors using the Language Server Protocol do; replies use the framing of
+// the first message received. Requests are handled one at a time, in order.
+package jsonrpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Version is the protocol version sent in every message
+const Version = "2.0"
+
+// Error codes defined by the specification
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// Error is a JSON-RPC error. Handlers return it to control the code sent;
+// other errors are sent as internal errors.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// InvalidParams returns an invalid params error
+func InvalidParams(format string, args ...any) *Error {
+	return &Error{Code: CodeInvalidParams, Message: fmt.Sprintf(format, args...)}
+}
+
+// Handler answers one method. The result is sent back as JSON.
+type Handler func(ctx context.Context, params json.RawMessage) (any, error)
+
+// Decode unmarshals params into v, rejecting unknown fields. Missing params
+// leave v unchanged.
+func Decode(params json.RawMessage, v any) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(params))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return InvalidParams("Invalid params: %v", err)
+	}
+	return nil
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// isNotification reports whether the request expects no reply
+func (r request) isNotification() bool {
+	return r.ID == nil
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+type notification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+// Server dispatches requests to registered handlers
+type Server struct {
+	methods map[string]Handler
+
+	// mu guards writes to w, which notifications share with replies
+	mu     sync.Mutex
+	w      io.Writer
+	framed bool
+}
+
+// NewServer returns a server without methods
+func NewServer() *Server {
+	return &Server{methods: make(map[string]Handler)}
+}
+
+// Handle registers the handler for method
+func (s *Server) Handle(method string, h Handler) {
+	s.methods[method] = h
+}
+
+// Serve reads requests from r and writes replies to w until r is exhausted
+// or ctx is done
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	s.mu.Lock()
+	s.w = w
+	s.mu.Unlock()
+
+	br := bufio.NewReader(r)
+	first := true
+	for ctx.Err() == nil {
+		msg, framed, err := readMessage(br)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if first {
+			s.mu.Lock()
+			s.framed = framed
+			s.mu.Unlock()
+			first = false
+		}
+		if len(bytes.TrimSpace(msg)) == 0 {
+			continue
+		}
+		if reply := s.handle(ctx, msg); reply != nil {
+			if err := s.write(reply); err != nil {
+				return err
+			}
+		}
+	}
+	return ctx.Err()
+}
+
+// Notify sends a notification to the client being served
+func (s *Server) Notify(method string, params any) error {
+	return s.write(notification{JSONRPC: Version, Method: method, Params: params})
+}
+
+// handle answers one message, a request or a batch, and returns the reply
+// or nil when there is nothing to send
+func (s *Server) handle(ctx context.Context, msg []byte) any {
+	msg = bytes.TrimSpace(msg)
+	if msg[0] != '[' {
+		var req request
+		if err := json.Unmarshal(msg, &req); err != nil {
+			return errorResponse(nil, &Error{Code: CodeParseError, Message: "Parse error: " + err.Error()})
+		}
+		return s.call(ctx, req)
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(msg, &batch); err != nil {
+		return errorResponse(nil, &Error{Code: CodeParseError, Message: "Parse error: " + err.Error()})
+	}
+	if len(batch) == 0 {
+		return errorResponse(nil, &Error{Code: CodeInvalidRequest, Message: "Empty batch"})
+	}
+	var replies []any
+	for _, raw := range batch {
+		var req request
+		if err := json.Unmarshal(raw, &req); err != nil {
+			replies = append(replies, errorResponse(nil, &Error{Code: CodeInvalidRequest, Message: "Invalid request"}))
+			continue
+		}
+		if reply := s.call(ctx, req); reply != nil {
+			replies = append(replies, reply)
+		}
+	}
+	if len(replies) == 0 {
+		return nil
+	}
+	return replies
+}
+
+// call runs one request and returns its response, or nil for a notification
+func (s *Server) call(ctx context.Context, req request) any {
+	if req.JSONRPC != Version || req.Method == "" {
+		return errorResponse(req.ID, &Error{Code: CodeInvalidRequest, Message: "Invalid request"})
+	}
+	h, ok := s.methods[req.Method]
+	if !ok {
+		if req.isNotification() {
+			return nil
+		}
+		return errorResponse(req.ID, &Error{Code: CodeMethodNotFound, Message: "Method not found: " + req.Method})
+	}
+
+	result, err := h(ctx, req.Params)
+	if req.isNotification() {
+		return nil
+	}
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &Error{Code: CodeInternalError, Message: err.Error()}
+		}
+		return errorResponse(req.ID, rpcErr)
+	}
+	if result == nil {
+		result = struct{}{}
+	}
+	return response{JSONRPC: Version, ID: req.ID, Result: result}
+}
+
+func errorResponse(id json.RawMessage, err *Error) response {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return response{JSONRPC: Version, ID: id, Error: err}
+}
+
+func (s *Server) write(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.w == nil {
+		return errors.New("Not serving")
+	}
+	if s.framed {
+		_, err = fmt.Fprintf(s.w, "Content-Length: %d\r\n\r\n%s", len(data), data)
+	} else {
+		_, err = fmt.Fprintf(s.w, "%s\n", data)
+	}
+	return err
+}
+
+// readMessage reads one message and reports whether it had a Content-Length
+// header
+func readMessage(r *bufio.Reader) ([]byte, bool, error) {
+	line, err := r.ReadBytes('\n')
+	if err != nil && (len(line) == 0 || !errors.Is(err, io.EOF)) {
+		return nil, false, err
+	}
+	name, value, ok := strings.Cut(string(line), ":")
+	if !ok || !strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+		return line, false, nil
+	}
+
+	length, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || length < 0 {
+		return nil, true, fmt.Errorf("Invalid Content-Length %q", strings.TrimSpace(value))
+	}
+	// Skip any other headers up to the blank line
+	for {
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return nil, true, err
+		}
+		if strings.TrimSpace(header) == "" {
+			break
+		}
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, true, err
+	}
+	return body, true, nil
+}
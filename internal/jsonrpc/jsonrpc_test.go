@@ -0,0 +1,122 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func newServer() *Server {
+	s := NewServer()
+	s.Handle("echo", func(ctx context.Context, params json.RawMessage) (any, error) {
+		var p struct {
+			Text string `json:"text"`
+		}
+		if err := Decode(params, &p); err != nil {
+			return nil, err
+		}
+		if p.Text == "" {
+			return nil, InvalidParams("Missing text")
+		}
+		return p, nil
+	})
+	s.Handle("fail", func(ctx context.Context, params json.RawMessage) (any, error) {
+		return nil, errors.New("Something broke")
+	})
+	return s
+}
+
+// serve runs the server over input and returns what it wrote
+func serve(t *testing.T, s *Server, input string) string {
+	t.Helper()
+	var out bytes.Buffer
+	if err := s.Serve(context.Background(), strings.NewReader(input), &out); err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+	return out.String()
+}
+
+func TestServeLines(t *testing.T) {
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"echo","params":{"text":"hi"}}`,
+		`{"jsonrpc":"2.0","id":"two","method":"missing"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"echo","params":{}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"echo","params":{"text":"hi","extra":1}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"fail"}`,
+		`{"jsonrpc":"2.0","method":"echo","params":{"text":"no reply"}}`,
+		`{not json`,
+		`{"id":6,"method":"echo"}`,
+		``,
+	}, "\n")
+
+	expected := []string{
+		`{"jsonrpc":"2.0","id":1,"result":{"text":"hi"}}`,
+		`{"jsonrpc":"2.0","id":"two","error":{"code":-32601,"message":"Method not found: missing"}}`,
+		`{"jsonrpc":"2.0","id":3,"error":{"code":-32602,"message":"Missing text"}}`,
+		`"id":4,"error":{"code":-32602,"message":"Invalid params: json: unknown field \"extra\""}`,
+		`{"jsonrpc":"2.0","id":5,"error":{"code":-32603,"message":"Something broke"}}`,
+		`{"jsonrpc":"2.0","id":null,"error":{"code":-32700`,
+		`{"jsonrpc":"2.0","id":6,"error":{"code":-32600,"message":"Invalid request"}}`,
+	}
+	lines := strings.Split(strings.TrimSpace(serve(t, newServer(), input)), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d replies, got %d:\n%s", len(expected), len(lines), strings.Join(lines, "\n"))
+	}
+	for i, want := range expected {
+		if !strings.Contains(lines[i], want) {
+			t.Errorf("Reply %d: expected %s, got %s", i, want, lines[i])
+		}
+	}
+}
+
+func TestServeBatch(t *testing.T) {
+	input := `[{"jsonrpc":"2.0","id":1,"method":"echo","params":{"text":"a"}},` +
+		`{"jsonrpc":"2.0","method":"echo","params":{"text":"b"}},` +
+		`{"jsonrpc":"2.0","id":2,"method":"missing"}]` + "\n"
+	var replies []response
+	if err := json.Unmarshal([]byte(serve(t, newServer(), input)), &replies); err != nil {
+		t.Fatal(err)
+	}
+	if len(replies) != 2 || string(replies[0].ID) != "1" || replies[1].Error == nil {
+		t.Errorf("Expected replies to the two requests, got %+v", replies)
+	}
+
+	// A batch of notifications gets no reply at all
+	if out := serve(t, newServer(), `[{"jsonrpc":"2.0","method":"echo","params":{"text":"b"}}]`); out != "" {
+		t.Errorf("Expected no reply, got %s", out)
+	}
+}
+
+func TestServeContentLength(t *testing.T) {
+	body := `{"jsonrpc":"2.0","id":1,"method":"echo","params":{"text":"framed"}}`
+	input := "Content-Length: " + strconv.Itoa(len(body)) + "\r\nContent-Type: application/json\r\n\r\n" + body
+	out := serve(t, newServer(), input)
+
+	reply := `{"jsonrpc":"2.0","id":1,"result":{"text":"framed"}}`
+	if out != "Content-Length: "+strconv.Itoa(len(reply))+"\r\n\r\n"+reply {
+		t.Errorf("Expected a framed reply, got %q", out)
+	}
+}
+
+func TestNotify(t *testing.T) {
+	s := newServer()
+	if err := s.Notify("changed", nil); err == nil {
+		t.Error("Expected an error notifying before serving")
+	}
+
+	var out bytes.Buffer
+	s.Handle("poke", func(ctx context.Context, params json.RawMessage) (any, error) {
+		return nil, s.Notify("changed", map[string]int{"n": 1})
+	})
+	if err := s.Serve(context.Background(), strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"poke"}`), &out); err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"jsonrpc":"2.0","method":"changed","params":{"n":1}}` + "\n" + `{"jsonrpc":"2.0","id":1,"result":{}}` + "\n"
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
@@ -0,0 +1,345 @@
+// Package markdown renders todo lists as Markdown checklists and keeps
+// checklists in Markdown files in sync with a list.
+package markdown
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+// GroupBy selects how rendered checklists are split into sections
+type GroupBy string
+
+const (
+	GroupNone     GroupBy = "none"
+	GroupTag      GroupBy = "tag"
+	GroupPriority GroupBy = "priority"
+)
+
+// ParseGroupBy validates a grouping name
+func ParseGroupBy(s string) (GroupBy, error) {
+	switch g := GroupBy(strings.ToLower(s)); g {
+	case GroupNone, GroupTag, GroupPriority:
+		return g, nil
+	}
+	return "", fmt.Errorf("Unknown grouping %q (use none, tag or priority)", s)
+}
+
+const dueMarker = "📅"
+
+// TaskLine renders one item as a checklist line
+func TaskLine(item todo.Item) string {
+	box := " "
+	if item.Done {
+		box = "x"
+	}
+	line := fmt.Sprintf("- [%s] %s", box, item.Text)
+	if item.DueDate != nil {
+		line += fmt.Sprintf(" %s %s", dueMarker, item.DueDate.Format("2006-01-02"))
+	}
+	return line
+}
+
+// Render writes the items as a Markdown checklist. When grouping by tag, items
+// with several tags are listed under each of them.
+func Render(w io.Writer, items []todo.Item, group GroupBy) error {
+	var b strings.Builder
+	b.WriteString("# TODO List\n")
+
+	switch group {
+	case GroupTag:
+		byTag := make(map[string][]todo.Item)
+		var untagged []todo.Item
+		for _, item := range items {
+			if len(item.Tags) == 0 {
+				untagged = append(untagged, item)
+			}
+			for _, tag := range item.Tags {
+				byTag[tag] = append(byTag[tag], item)
+			}
+		}
+		tags := make([]string, 0, len(byTag))
+		for tag := range byTag {
+			tags = append(tags, tag)
+		}
+		sort.Strings(tags)
+		for _, tag := range tags {
+			writeSection(&b, tag, byTag[tag])
+		}
+		writeSection(&b, "Untagged", untagged)
+
+	case GroupPriority:
+		sections := []struct {
+			title    string
+			priority todo.Priority
+		}{
+			{"🔴 High", todo.PriorityHigh},
+			{"🟡 Medium", todo.PriorityMedium},
+			{"🟢 Low", todo.PriorityLow},
+		}
+		for _, s := range sections {
+			var matching []todo.Item
+			for _, item := range items {
+				if item.Priority == s.priority {
+					matching = append(matching, item)
+				}
+			}
+			writeSection(&b, s.title, matching)
+		}
+
+	default:
+		b.WriteString("\n")
+		for _, item := range items {
+			b.WriteString(TaskLine(item) + "\n")
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeSection(b *strings.Builder, title string, items []todo.Item) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n## %s\n\n", title)
+	for _, item := range items {
+		b.WriteString(TaskLine(item) + "\n")
+	}
+}
+
+// checklistLine matches "- [ ] text", "* [x] text" and numbered "1. [ ] text" items
+var checklistLine = regexp.MustCompile(`^(\s*(?:[-*+]|\d+[.)])\s+\[)([ xX])(\]\s+)(.*?)\s*$`)
+
+// dueSuffix matches the due date marker written by TaskLine
+var dueSuffix = regexp.MustCompile(`\s*` + dueMarker + `\s*(\d{4}-\d{2}-\d{2})$`)
+
+// Task is a checklist item found in a Markdown document
+type Task struct {
+	Line int // zero-based line index in the document
+	Text string
+	Done bool
+	Due  *time.Time
+}
+
+// Document is a Markdown file whose checklist boxes can be rewritten while
+// every other byte is preserved
+type Document struct {
+	lines   []string
+	newline string
+}
+
+// ParseDocument splits Markdown into lines, remembering the line ending style
+func ParseDocument(data []byte) *Document {
+	newline := "\n"
+	if bytes.Contains(data, []byte("\r\n")) {
+		newline = "\r\n"
+	}
+	return &Document{
+		lines:   strings.Split(string(data), newline),
+		newline: newline,
+	}
+}
+
+// Bytes returns the document contents
+func (d *Document) Bytes() []byte {
+	return []byte(strings.Join(d.lines, d.newline))
+}
+
+// Tasks returns the checklist items outside fenced code blocks
+func (d *Document) Tasks() []Task {
+	var tasks []Task
+	fence := ""
+	for i, line := range d.lines {
+		trimmed := strings.TrimSpace(line)
+		if fence == "" && (strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")) {
+			fence = trimmed[:3]
+			continue
+		}
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+
+		m := checklistLine.FindStringSubmatch(line)
+		if m == nil || m[4] == "" {
+			continue
+		}
+
+		task := Task{Line: i, Text: m[4], Done: m[2] != " "}
+		if due := dueSuffix.FindStringSubmatch(task.Text); due != nil {
+			if t, err := time.Parse("2006-01-02", due[1]); err == nil {
+				task.Due = &t
+				task.Text = strings.TrimSpace(strings.TrimSuffix(task.Text, due[0]))
+			}
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+// SetDone ticks or clears the checkbox on a line returned by Tasks
+func (d *Document) SetDone(line int, done bool) {
+	m := checklistLine.FindStringSubmatchIndex(d.lines[line])
+	if m == nil {
+		return
+	}
+	box := " "
+	if done {
+		box = "x"
+	}
+	l := d.lines[line]
+	d.lines[line] = l[:m[4]] + box + l[m[5]:]
+}
+
+// SyncResult describes what a Sync changed, by task text
+type SyncResult struct {
+	Added      []string // new tasks imported into the list
+	Completed  []string // completed in the list because they were ticked in the file
+	Reopened   []string // reopened in the list because they were unticked in the file
+	Ticked     []string // ticked in the file because they are done in the list
+	Unticked   []string // unticked in the file because they were reopened in the list
+	Deleted    []string // left alone because they were deleted from the list since the last sync
+	FileDirty  bool
+	StoreDirty bool
+}
+
+// Sync imports the document's checklist into the list and writes completion
+// status back into the document.
+//
+// base holds each task's completion status as of the previous sync (nil on the
+// first sync). It tells which side changed when the file and the list disagree;
+// without it, done wins. Sync returns the new base to persist for next time.
+func Sync(doc *Document, list *todo.List, base map[string]bool) (SyncResult, map[string]bool) {
+	var result SyncResult
+	newBase := make(map[string]bool)
+
+	for _, task := range doc.Tasks() {
+		index := indexOfText(list, task.Text)
+		baseDone, seen := base[task.Text]
+
+		if index < 0 {
+			if seen {
+				// Synced before but now gone from the list: it was deleted
+				// there. Remember it so the next sync doesn't import it again.
+				result.Deleted = append(result.Deleted, task.Text)
+				newBase[task.Text] = baseDone
+				continue
+			}
+			item := todo.NewItem(task.Text)
+			item.DueDate = task.Due
+			if task.Done {
+				now := time.Now()
+				item.Done = true
+				item.CompletedAt = &now
+			}
+			if err := list.AddItem(item); err != nil {
+				continue
+			}
+			list.Sort()
+			result.Added = append(result.Added, task.Text)
+			result.StoreDirty = true
+			newBase[task.Text] = task.Done
+			continue
+		}
+
+		storeDone := list.Items[index].Done
+		done := storeDone
+		if task.Done != storeDone {
+			fileChanged := !seen || task.Done != baseDone
+			storeChanged := !seen || storeDone != baseDone
+			switch {
+			case fileChanged && !storeChanged:
+				done = task.Done
+			case storeChanged && !fileChanged:
+				done = storeDone
+			default:
+				done = true
+			}
+		}
+
+		if done != storeDone {
+			if done {
+				if err := list.Complete(index); err != nil {
+					continue
+				}
+				result.Completed = append(result.Completed, task.Text)
+			} else {
+				if err := list.Uncomplete(index); err != nil {
+					continue
+				}
+				result.Reopened = append(result.Reopened, task.Text)
+			}
+			result.StoreDirty = true
+		}
+		if done != task.Done {
+			doc.SetDone(task.Line, done)
+			if done {
+				result.Ticked = append(result.Ticked, task.Text)
+			} else {
+				result.Unticked = append(result.Unticked, task.Text)
+			}
+			result.FileDirty = true
+		}
+		newBase[task.Text] = done
+	}
+
+	return result, newBase
+}
+
+// SyncState remembers, per Markdown file, each task's completion status as of
+// the last sync
+type SyncState struct {
+	path  string
+	Files map[string]map[string]bool
+}
+
+// LoadSyncState reads the state from path. A missing file yields an empty state.
+func LoadSyncState(path string) (*SyncState, error) {
+	s := &SyncState{path: path, Files: make(map[string]map[string]bool)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.Files); err != nil {
+		return nil, fmt.Errorf("Invalid sync state %s: %w", path, err)
+	}
+	if s.Files == nil {
+		s.Files = make(map[string]map[string]bool)
+	}
+	return s, nil
+}
+
+// Save writes the state back to the file it was loaded from
+func (s *SyncState) Save() error {
+	data, err := json.Marshal(s.Files)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0644)
+}
+
+func indexOfText(list *todo.List, text string) int {
+	for i, item := range list.Items {
+		if item.Text == text {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,213 @@
+package markdown
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+func mustAdd(t *testing.T, list *todo.List, text string) int {
+	t.Helper()
+	if err := list.Add(text); err != nil {
+		t.Fatal(err)
+	}
+	return list.IndexOf(list.Items[len(list.Items)-1].ID)
+}
+
+func TestRender(t *testing.T) {
+	due := time.Date(2030, 5, 1, 0, 0, 0, 0, time.UTC)
+	items := []todo.Item{
+		{Text: "Write docs", Priority: todo.PriorityHigh, Tags: []string{"docs", "work"}, DueDate: &due},
+		{Text: "Buy milk", Priority: todo.PriorityLow, Done: true, Tags: []string{}},
+	}
+
+	var b strings.Builder
+	if err := Render(&b, items, GroupTag); err != nil {
+		t.Fatal(err)
+	}
+	expected := "# TODO List\n\n" +
+		"## docs\n\n- [ ] Write docs 📅 2030-05-01\n\n" +
+		"## work\n\n- [ ] Write docs 📅 2030-05-01\n\n" +
+		"## Untagged\n\n- [x] Buy milk\n"
+	if b.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+
+	b.Reset()
+	if err := Render(&b, items, GroupPriority); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	if !strings.Contains(out, "## 🔴 High") || !strings.Contains(out, "## 🟢 Low") || strings.Contains(out, "Medium") {
+		t.Errorf("Expected only non-empty priority sections, got:\n%s", out)
+	}
+
+	b.Reset()
+	if err := Render(&b, items, GroupNone); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(b.String(), "##") {
+		t.Errorf("Expected no sections, got:\n%s", b.String())
+	}
+}
+
+func TestParseGroupBy(t *testing.T) {
+	if g, err := ParseGroupBy("Tag"); err != nil || g != GroupTag {
+		t.Errorf("Expected tag grouping, got %q, %v", g, err)
+	}
+	if _, err := ParseGroupBy("colour"); err == nil {
+		t.Error("Expected error for unknown grouping")
+	}
+}
+
+const readme = "# Project\n" +
+	"\n" +
+	"Some intro text.\n" +
+	"\n" +
+	"- [ ] Ship v1 📅 2030-01-15\n" +
+	"* [x] Set up CI\n" +
+	"  1. [ ] Nested numbered\n" +
+	"- [ ]\n" +
+	"- plain bullet\n" +
+	"\n" +
+	"```md\n" +
+	"- [ ] Example in code\n" +
+	"```\n"
+
+func TestTasks(t *testing.T) {
+	tasks := ParseDocument([]byte(readme)).Tasks()
+	if len(tasks) != 3 {
+		t.Fatalf("Expected 3 tasks, got %d: %+v", len(tasks), tasks)
+	}
+
+	if tasks[0].Text != "Ship v1" || tasks[0].Done || tasks[0].Due == nil || tasks[0].Due.Format("2006-01-02") != "2030-01-15" {
+		t.Errorf("Unexpected first task: %+v", tasks[0])
+	}
+	if tasks[1].Text != "Set up CI" || !tasks[1].Done || tasks[1].Line != 5 {
+		t.Errorf("Unexpected second task: %+v", tasks[1])
+	}
+	if tasks[2].Text != "Nested numbered" {
+		t.Errorf("Unexpected third task: %+v", tasks[2])
+	}
+}
+
+func TestSetDonePreservesContent(t *testing.T) {
+	input := strings.ReplaceAll(readme, "\n", "\r\n")
+	doc := ParseDocument([]byte(input))
+	tasks := doc.Tasks()
+
+	doc.SetDone(tasks[0].Line, true)
+	doc.SetDone(tasks[1].Line, false)
+
+	expected := strings.Replace(input, "- [ ] Ship v1", "- [x] Ship v1", 1)
+	expected = strings.Replace(expected, "* [x] Set up CI", "* [ ] Set up CI", 1)
+	if string(doc.Bytes()) != expected {
+		t.Errorf("Expected:\n%q\ngot:\n%q", expected, doc.Bytes())
+	}
+}
+
+func TestSyncImportsAndWritesBack(t *testing.T) {
+	list := todo.NewList()
+	index := mustAdd(t, list, "Ship v1")
+	if err := list.Complete(index); err != nil {
+		t.Fatal(err)
+	}
+	mustAdd(t, list, "Set up CI")
+
+	doc := ParseDocument([]byte(readme))
+	result, base := Sync(doc, list, nil)
+
+	if len(result.Added) != 1 || result.Added[0] != "Nested numbered" {
+		t.Errorf("Expected the new task to be added, got %v", result.Added)
+	}
+	if len(result.Ticked) != 1 || result.Ticked[0] != "Ship v1" {
+		t.Errorf("Expected Ship v1 ticked in the file, got %v", result.Ticked)
+	}
+	if len(result.Completed) != 1 || result.Completed[0] != "Set up CI" {
+		t.Errorf("Expected Set up CI completed in the list, got %v", result.Completed)
+	}
+	if !result.FileDirty || !result.StoreDirty {
+		t.Errorf("Expected both sides to change: %+v", result)
+	}
+
+	if !strings.Contains(string(doc.Bytes()), "- [x] Ship v1 📅 2030-01-15\n") {
+		t.Errorf("Expected Ship v1 to be ticked:\n%s", doc.Bytes())
+	}
+	if !strings.HasPrefix(string(doc.Bytes()), "# Project\n\nSome intro text.\n") {
+		t.Errorf("Surrounding content should be preserved:\n%s", doc.Bytes())
+	}
+	if !base["Ship v1"] || !base["Set up CI"] || base["Nested numbered"] {
+		t.Errorf("Unexpected base: %v", base)
+	}
+
+	// A second sync with nothing changed is a no-op
+	result, _ = Sync(doc, list, base)
+	if result.FileDirty || result.StoreDirty {
+		t.Errorf("Expected no changes on resync: %+v", result)
+	}
+}
+
+func TestSyncUsesBaseToPickChangedSide(t *testing.T) {
+	list := todo.NewList()
+	index := mustAdd(t, list, "Set up CI")
+	if err := list.Complete(index); err != nil {
+		t.Fatal(err)
+	}
+	base := map[string]bool{"Set up CI": true}
+
+	// Unticked in the file since the last sync: reopen in the list
+	doc := ParseDocument([]byte("- [ ] Set up CI\n"))
+	result, base := Sync(doc, list, base)
+	if len(result.Reopened) != 1 || list.Items[0].Done {
+		t.Errorf("Expected the task to be reopened: %+v", result)
+	}
+
+	// Completed in the list since the last sync: tick the file
+	if err := list.Complete(0); err != nil {
+		t.Fatal(err)
+	}
+	result, _ = Sync(doc, list, base)
+	if len(result.Ticked) != 1 || string(doc.Bytes()) != "- [x] Set up CI\n" {
+		t.Errorf("Expected the file to be ticked, got %+v\n%s", result, doc.Bytes())
+	}
+}
+
+func TestSyncSkipsTasksDeletedFromList(t *testing.T) {
+	list := todo.NewList()
+	doc := ParseDocument([]byte("- [ ] Old task\n"))
+
+	result, base := Sync(doc, list, map[string]bool{"Old task": false})
+	if len(result.Deleted) != 1 || len(list.Items) != 0 || result.StoreDirty {
+		t.Errorf("Deleted task should not be re-added: %+v", result)
+	}
+
+	// The line stays in the file, so the next sync sees it again
+	result, _ = Sync(doc, list, base)
+	if len(result.Added) != 0 || len(list.Items) != 0 || result.StoreDirty {
+		t.Errorf("Deleted task should stay deleted on the next sync: %+v", result)
+	}
+}
+
+func TestSyncState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mdsync.json")
+
+	state, err := LoadSyncState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state.Files["/tmp/README.md"] = map[string]bool{"Ship v1": true}
+	if err := state.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadSyncState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.Files["/tmp/README.md"]["Ship v1"] {
+		t.Errorf("Expected state to round-trip, got %v", loaded.Files)
+	}
+}
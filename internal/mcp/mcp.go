@@ -0,0 +1,261 @@
+// Package mcp serves a todo list to AI assistants over the Model Context
+// Protocol. Tools change and query the list through rpc.Service, and the
+// list itself is offered as resources. Messages are JSON-RPC, one per line.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/rahul4507/todo/internal/jsonrpc"
+	"github.com/rahul4507/todo/internal/rpc"
+)
+
+// ProtocolVersion is the newest protocol revision supported
+const ProtocolVersion = "2025-06-18"
+
+// supportedVersions are the revisions a client may ask for
+var supportedVersions = []string{ProtocolVersion, "2025-03-26", "2024-11-05"}
+
+// Resource URIs
+const (
+	ListURI     = "todo://list"
+	ListTextURI = "todo://list.txt"
+)
+
+// CodeResourceNotFound is the error for reading an unknown resource
+const CodeResourceNotFound = -32002
+
+// Server answers MCP requests for one client
+type Server struct {
+	service *rpc.Service
+	version string
+	srv     *jsonrpc.Server
+
+	// mu guards subscribed, the resources the client wants updates for
+	mu         sync.Mutex
+	subscribed map[string]bool
+}
+
+// NewServer serves service; version is reported to clients as the server's
+func NewServer(service *rpc.Service, version string) *Server {
+	s := &Server{
+		service:    service,
+		version:    version,
+		srv:        jsonrpc.NewServer(),
+		subscribed: make(map[string]bool),
+	}
+	s.srv.Handle("initialize", s.initialize)
+	s.srv.Handle("ping", func(ctx context.Context, params json.RawMessage) (any, error) {
+		return nil, nil
+	})
+	s.srv.Handle("tools/list", s.listTools)
+	s.srv.Handle("tools/call", s.callTool)
+	s.srv.Handle("resources/list", s.listResources)
+	s.srv.Handle("resources/templates/list", func(ctx context.Context, params json.RawMessage) (any, error) {
+		return map[string]any{"resourceTemplates": []any{}}, nil
+	})
+	s.srv.Handle("resources/read", s.readResource)
+	s.srv.Handle("resources/subscribe", s.subscribe(true))
+	s.srv.Handle("resources/unsubscribe", s.subscribe(false))
+	return s
+}
+
+// Serve answers requests from r on w until r is exhausted or ctx is done
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	return s.srv.Serve(ctx, r, w)
+}
+
+// Changed tells the client that the list changed, if it subscribed to it
+func (s *Server) Changed() error {
+	s.mu.Lock()
+	var uris []string
+	for uri := range s.subscribed {
+		uris = append(uris, uri)
+	}
+	s.mu.Unlock()
+
+	for _, uri := range uris {
+		if err := s.srv.Notify("notifications/resources/updated", map[string]string{"uri": uri}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) initialize(ctx context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		ProtocolVersion string         `json:"protocolVersion"`
+		Capabilities    map[string]any `json:"capabilities"`
+		ClientInfo      map[string]any `json:"clientInfo"`
+	}
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	version := ProtocolVersion
+	for _, v := range supportedVersions {
+		if p.ProtocolVersion == v {
+			version = v
+		}
+	}
+	return map[string]any{
+		"protocolVersion": version,
+		"capabilities": map[string]any{
+			"tools":     map[string]any{"listChanged": false},
+			"resources": map[string]any{"subscribe": true, "listChanged": false},
+		},
+		"serverInfo": map[string]string{"name": "todo", "version": s.version},
+		"instructions": "Tools manage the user's todo list. Tasks are referred to by id, " +
+			"or by number as shown in the list. Read " + ListURI + " to see every task.",
+	}, nil
+}
+
+// decode unmarshals params, ignoring fields it does not know, as later
+// protocol revisions may add some
+func decode(params json.RawMessage, v any) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return jsonrpc.InvalidParams("Invalid params: %v", err)
+	}
+	return nil
+}
+
+type resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	MimeType    string `json:"mimeType"`
+}
+
+var resources = []resource{
+	{
+		URI:         ListURI,
+		Name:        "Todo list",
+		Description: "Every task with its number, id, priority, due date and tags",
+		MimeType:    "application/json",
+	},
+	{
+		URI:         ListTextURI,
+		Name:        "Todo list (text)",
+		Description: "The list as `todo list` prints it",
+		MimeType:    "text/plain",
+	},
+}
+
+func (s *Server) listResources(ctx context.Context, params json.RawMessage) (any, error) {
+	return map[string]any{"resources": resources}, nil
+}
+
+type uriParams struct {
+	URI string `json:"uri"`
+}
+
+func (s *Server) readResource(ctx context.Context, params json.RawMessage) (any, error) {
+	var p uriParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+
+	var text, mimeType string
+	switch p.URI {
+	case ListURI:
+		list, err := s.service.List()
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.MarshalIndent(list, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		text, mimeType = string(data), "application/json"
+	case ListTextURI:
+		var err error
+		if text, err = s.service.Text(); err != nil {
+			return nil, err
+		}
+		mimeType = "text/plain"
+	default:
+		return nil, &jsonrpc.Error{Code: CodeResourceNotFound, Message: "Resource not found", Data: p}
+	}
+
+	return map[string]any{
+		"contents": []map[string]string{{"uri": p.URI, "mimeType": mimeType, "text": text}},
+	}, nil
+}
+
+func (s *Server) subscribe(on bool) jsonrpc.Handler {
+	return func(ctx context.Context, params json.RawMessage) (any, error) {
+		var p uriParams
+		if err := decode(params, &p); err != nil {
+			return nil, err
+		}
+		if p.URI != ListURI && p.URI != ListTextURI {
+			return nil, &jsonrpc.Error{Code: CodeResourceNotFound, Message: "Resource not found", Data: p}
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if on {
+			s.subscribed[p.URI] = true
+		} else {
+			delete(s.subscribed, p.URI)
+		}
+		return nil, nil
+	}
+}
+
+type content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type toolResult struct {
+	Content []content `json:"content"`
+	IsError bool      `json:"isError,omitempty"`
+}
+
+func (s *Server) listTools(ctx context.Context, params json.RawMessage) (any, error) {
+	list := []map[string]any{}
+	for _, t := range tools {
+		list = append(list, map[string]any{
+			"name":        t.Name,
+			"description": t.Description,
+			"inputSchema": t.InputSchema,
+		})
+	}
+	return map[string]any{"tools": list}, nil
+}
+
+func (s *Server) callTool(ctx context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	t := findTool(p.Name)
+	if t == nil {
+		return nil, jsonrpc.InvalidParams("Unknown tool: %s", p.Name)
+	}
+
+	result, err := t.call(s.service, p.Arguments)
+	if err != nil {
+		// Bad arguments are reported to the model so it can correct them;
+		// anything else is a failure of the server
+		var rpcErr *jsonrpc.Error
+		if errors.As(err, &rpcErr) && rpcErr.Code == jsonrpc.CodeInvalidParams {
+			return toolResult{Content: []content{{Type: "text", Text: rpcErr.Message}}, IsError: true}, nil
+		}
+		return nil, err
+	}
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return toolResult{Content: []content{{Type: "text", Text: string(data)}}}, nil
+}
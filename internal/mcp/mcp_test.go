@@ -0,0 +1,300 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rahul4507/todo/internal/jsonrpc"
+	"github.com/rahul4507/todo/internal/rpc"
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+// client drives a server over pipes, as an assistant does over stdio
+type client struct {
+	t      *testing.T
+	in     io.Writer
+	out    *bufio.Scanner
+	id     int
+	server *Server
+}
+
+type message struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *jsonrpc.Error  `json:"error"`
+}
+
+func newClient(t *testing.T) (*client, todo.Store) {
+	t.Helper()
+	store := todo.FileStore{Path: filepath.Join(t.TempDir(), "todos.json")}
+	list := todo.NewList()
+	if err := list.Add("Write report"); err != nil {
+		t.Fatal(err)
+	}
+	yesterday := time.Now().AddDate(0, 0, -1)
+	if err := list.SetDueDate(0, yesterday); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.Add("Buy milk"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(list); err != nil {
+		t.Fatal(err)
+	}
+
+	server := NewServer(&rpc.Service{Store: store}, "test")
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		server.Serve(context.Background(), inR, outW)
+		outW.Close()
+		close(done)
+	}()
+	t.Cleanup(func() {
+		inW.Close()
+		<-done
+	})
+
+	c := &client{t: t, in: inW, out: bufio.NewScanner(outR), server: server}
+	var init struct {
+		ProtocolVersion string `json:"protocolVersion"`
+		ServerInfo      struct {
+			Name string `json:"name"`
+		} `json:"serverInfo"`
+	}
+	c.mustCall("initialize", map[string]any{
+		"protocolVersion": "2025-03-26",
+		"capabilities":    map[string]any{},
+		"clientInfo":      map[string]string{"name": "test", "version": "1"},
+	}, &init)
+	if init.ProtocolVersion != "2025-03-26" || init.ServerInfo.Name != "todo" {
+		t.Errorf("Expected the requested protocol version from todo, got %+v", init)
+	}
+	c.send(map[string]any{"jsonrpc": "2.0", "method": "notifications/initialized"})
+	return c, store
+}
+
+func (c *client) send(msg any) {
+	c.t.Helper()
+	data, _ := json.Marshal(msg)
+	if _, err := fmt.Fprintf(c.in, "%s\n", data); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *client) read() message {
+	c.t.Helper()
+	if !c.out.Scan() {
+		c.t.Fatalf("Expected a message, got %v", c.out.Err())
+	}
+	var msg message
+	if err := json.Unmarshal(c.out.Bytes(), &msg); err != nil {
+		c.t.Fatal(err)
+	}
+	return msg
+}
+
+// call sends a request and returns the reply to it
+func (c *client) call(method string, params any) message {
+	c.t.Helper()
+	c.id++
+	c.send(map[string]any{"jsonrpc": "2.0", "id": c.id, "method": method, "params": params})
+	msg := c.read()
+	if string(msg.ID) != fmt.Sprint(c.id) {
+		c.t.Fatalf("Expected a reply to %d, got %+v", c.id, msg)
+	}
+	return msg
+}
+
+func (c *client) mustCall(method string, params any, result any) {
+	c.t.Helper()
+	msg := c.call(method, params)
+	if msg.Error != nil {
+		c.t.Fatalf("%s failed: %v", method, msg.Error)
+	}
+	if err := json.Unmarshal(msg.Result, result); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// tool calls a tool and returns its text and whether it failed
+func (c *client) tool(name string, args any) (string, bool) {
+	c.t.Helper()
+	var result toolResult
+	c.mustCall("tools/call", map[string]any{"name": name, "arguments": args}, &result)
+	if len(result.Content) != 1 || result.Content[0].Type != "text" {
+		c.t.Fatalf("Expected one text result, got %+v", result)
+	}
+	return result.Content[0].Text, result.IsError
+}
+
+func TestListTools(t *testing.T) {
+	c, _ := newClient(t)
+	var result struct {
+		Tools []struct {
+			Name        string         `json:"name"`
+			InputSchema map[string]any `json:"inputSchema"`
+		} `json:"tools"`
+	}
+	c.mustCall("tools/list", nil, &result)
+
+	var names []string
+	for _, tool := range result.Tools {
+		names = append(names, tool.Name)
+		if tool.InputSchema["type"] != "object" {
+			t.Errorf("Expected an object schema for %s, got %v", tool.Name, tool.InputSchema)
+		}
+	}
+	expected := "add_task complete_task search_tasks list_overdue set_priority set_due_date tag_task"
+	if strings.Join(names, " ") != expected {
+		t.Errorf("Expected tools %s, got %v", expected, names)
+	}
+}
+
+func TestTools(t *testing.T) {
+	c, store := newClient(t)
+
+	text, failed := c.tool("add_task", map[string]any{"text": "Fix bug", "priority": "high", "tags": []string{"work"}})
+	if failed {
+		t.Fatalf("add_task failed: %s", text)
+	}
+	var added rpc.ItemResult
+	if err := json.Unmarshal([]byte(text), &added); err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		tool string
+		args map[string]any
+	}{
+		{"set_priority", map[string]any{"id": added.Item.ID, "priority": "low"}},
+		{"set_due_date", map[string]any{"id": added.Item.ID, "due": "2030-05-01"}},
+		{"tag_task", map[string]any{"id": added.Item.ID, "tags": []string{"work", "bugs"}}},
+		{"complete_task", map[string]any{"number": 2}},
+	}
+	for _, step := range steps {
+		if text, failed := c.tool(step.tool, step.args); failed {
+			t.Fatalf("%s failed: %s", step.tool, text)
+		}
+	}
+
+	list, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := list.Items[list.IndexOf(added.Item.ID)]
+	if item.Priority != todo.PriorityLow || item.DueDate.Format("2006-01-02") != "2030-05-01" || strings.Join(item.Tags, ",") != "work,bugs" {
+		t.Errorf("Expected the task to be changed, got %+v", item)
+	}
+	if done := list.Items[len(list.Items)-1]; !done.Done || done.Text != "Buy milk" {
+		t.Errorf("Expected Buy milk to be done, got %+v", done)
+	}
+
+	var found []rpc.ItemResult
+	text, _ = c.tool("search_tasks", map[string]any{"query": "BUG"})
+	if err := json.Unmarshal([]byte(text), &found); err != nil || len(found) != 1 || found[0].Item.ID != added.Item.ID {
+		t.Errorf("Expected to find the bug, got %s", text)
+	}
+
+	var overdue []rpc.ItemResult
+	text, _ = c.tool("list_overdue", map[string]any{})
+	if err := json.Unmarshal([]byte(text), &overdue); err != nil || len(overdue) != 1 || overdue[0].Item.Text != "Write report" {
+		t.Errorf("Expected the report to be overdue, got %s", text)
+	}
+}
+
+func TestToolValidation(t *testing.T) {
+	c, store := newClient(t)
+
+	tests := []struct {
+		tool string
+		args map[string]any
+		err  string
+	}{
+		{"add_task", map[string]any{}, "Task text cannot be empty"},
+		{"add_task", map[string]any{"text": "Write report"}, "already"},
+		{"add_task", map[string]any{"text": "Plan", "priority": "urgent"}, "Invalid priority"},
+		{"add_task", map[string]any{"text": "Plan", "when": "now"}, "unknown field"},
+		{"complete_task", map[string]any{}, "Missing id or number"},
+		{"complete_task", map[string]any{"number": "one"}, "Invalid params"},
+		{"complete_task", map[string]any{"id": "nope"}, "No task with id nope"},
+		{"set_priority", map[string]any{"number": 1}, "Missing priority"},
+		{"set_due_date", map[string]any{"number": 1, "due": "next week"}, "Invalid date"},
+		{"tag_task", map[string]any{"number": 1, "tags": []string{" "}}, "Missing tags"},
+		{"search_tasks", map[string]any{"query": ""}, "Missing query"},
+	}
+	for _, tt := range tests {
+		text, failed := c.tool(tt.tool, tt.args)
+		if !failed || !strings.Contains(text, tt.err) {
+			t.Errorf("%s %v: expected an error containing %q, got %q", tt.tool, tt.args, tt.err, text)
+		}
+	}
+
+	if msg := c.call("tools/call", map[string]any{"name": "drop_tables"}); msg.Error == nil || msg.Error.Code != jsonrpc.CodeInvalidParams {
+		t.Errorf("Expected an invalid params error for an unknown tool, got %+v", msg)
+	}
+
+	list, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 2 || list.Items[0].Done {
+		t.Errorf("Expected the list to be unchanged, got %+v", list.Items)
+	}
+}
+
+func TestResources(t *testing.T) {
+	c, _ := newClient(t)
+
+	var listed struct {
+		Resources []resource `json:"resources"`
+	}
+	c.mustCall("resources/list", nil, &listed)
+	if len(listed.Resources) != 2 || listed.Resources[0].URI != ListURI {
+		t.Errorf("Expected the list resources, got %+v", listed)
+	}
+
+	var read struct {
+		Contents []struct {
+			URI      string `json:"uri"`
+			MimeType string `json:"mimeType"`
+			Text     string `json:"text"`
+		} `json:"contents"`
+	}
+	c.mustCall("resources/read", uriParams{URI: ListURI}, &read)
+	var list rpc.ListResult
+	if err := json.Unmarshal([]byte(read.Contents[0].Text), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 2 || list.Items[1].Number != 2 || list.Items[1].Item.Text != "Buy milk" {
+		t.Errorf("Expected both tasks, got %+v", list)
+	}
+
+	c.mustCall("resources/read", uriParams{URI: ListTextURI}, &read)
+	if !strings.Contains(read.Contents[0].Text, "2. [ ] 🟡 Buy milk") {
+		t.Errorf("Expected the printed list, got %q", read.Contents[0].Text)
+	}
+
+	if msg := c.call("resources/read", uriParams{URI: "todo://nope"}); msg.Error == nil || msg.Error.Code != CodeResourceNotFound {
+		t.Errorf("Expected resource not found, got %+v", msg)
+	}
+
+	// Subscribers hear about changes
+	var empty struct{}
+	c.mustCall("resources/subscribe", uriParams{URI: ListURI}, &empty)
+	go c.server.Changed()
+	msg := c.read()
+	if msg.Method != "notifications/resources/updated" || !strings.Contains(string(msg.Params), ListURI) {
+		t.Errorf("Expected an update for %s, got %+v", ListURI, msg)
+	}
+}
@@ -0,0 +1,140 @@
+package mcp
+
+import (
+	"encoding/json"
+
+	"github.com/rahul4507/todo/internal/jsonrpc"
+	"github.com/rahul4507/todo/internal/rpc"
+)
+
+// tool is one operation offered to the assistant
+type tool struct {
+	Name        string
+	Description string
+	InputSchema map[string]any
+	call        func(s *rpc.Service, args json.RawMessage) (any, error)
+}
+
+// Schemas for the arguments shared by several tools
+var (
+	idProperty = map[string]any{
+		"type":        "string",
+		"description": "ID of the task",
+	}
+	numberProperty = map[string]any{
+		"type":        "integer",
+		"minimum":     1,
+		"description": "Number of the task as shown in the list; use id when you have it, as numbers change",
+	}
+	priorityProperty = map[string]any{
+		"type": "string",
+		"enum": []string{"high", "medium", "low"},
+	}
+	dueProperty = map[string]any{
+		"type":        "string",
+		"pattern":     `^\d{4}-\d{2}-\d{2}$`,
+		"description": "Due date as YYYY-MM-DD",
+	}
+	tagsProperty = map[string]any{
+		"type":  "array",
+		"items": map[string]any{"type": "string", "minLength": 1},
+	}
+)
+
+// schema returns an object schema; tasks referred to by id or number get
+// both properties added
+func schema(properties map[string]any, required []string, refersToTask bool) map[string]any {
+	props := map[string]any{}
+	for name, p := range properties {
+		props[name] = p
+	}
+	s := map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+	if refersToTask {
+		props["id"] = idProperty
+		props["number"] = numberProperty
+		s["anyOf"] = []map[string]any{{"required": []string{"id"}}, {"required": []string{"number"}}}
+	}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+// args decodes the arguments of a tool and calls op with them
+func args[P any, R any](op func(s *rpc.Service, p P) (R, error)) func(*rpc.Service, json.RawMessage) (any, error) {
+	return func(s *rpc.Service, raw json.RawMessage) (any, error) {
+		var p P
+		if err := jsonrpc.Decode(raw, &p); err != nil {
+			return nil, err
+		}
+		return op(s, p)
+	}
+}
+
+var tools = []tool{
+	{
+		Name:        "add_task",
+		Description: "Add a task to the todo list",
+		InputSchema: schema(map[string]any{
+			"text":     map[string]any{"type": "string", "minLength": 1, "description": "What needs doing"},
+			"priority": priorityProperty,
+			"due":      dueProperty,
+			"tags":     tagsProperty,
+		}, []string{"text"}, false),
+		call: args((*rpc.Service).Add),
+	},
+	{
+		Name:        "complete_task",
+		Description: "Mark a task as done",
+		InputSchema: schema(nil, nil, true),
+		call:        args((*rpc.Service).Complete),
+	},
+	{
+		Name:        "search_tasks",
+		Description: "Find tasks whose text or tags contain the query, ignoring case",
+		InputSchema: schema(map[string]any{
+			"query":    map[string]any{"type": "string", "minLength": 1},
+			"assignee": map[string]any{"type": "string", "description": "Only tasks assigned to this user"},
+		}, []string{"query"}, false),
+		call: args((*rpc.Service).Search),
+	},
+	{
+		Name:        "list_overdue",
+		Description: "List pending tasks that are past their due date",
+		InputSchema: schema(nil, nil, false),
+		call: args(func(s *rpc.Service, _ struct{}) ([]rpc.ItemResult, error) {
+			return s.Overdue()
+		}),
+	},
+	{
+		Name:        "set_priority",
+		Description: "Set the priority of a task",
+		InputSchema: schema(map[string]any{"priority": priorityProperty}, []string{"priority"}, true),
+		call:        args((*rpc.Service).SetPriority),
+	},
+	{
+		Name:        "set_due_date",
+		Description: "Set the due date of a task",
+		InputSchema: schema(map[string]any{"due": dueProperty}, []string{"due"}, true),
+		call:        args((*rpc.Service).SetDueDate),
+	},
+	{
+		Name:        "tag_task",
+		Description: "Add tags to a task",
+		InputSchema: schema(map[string]any{"tags": tagsProperty}, []string{"tags"}, true),
+		call:        args((*rpc.Service).Tag),
+	},
+}
+
+func findTool(name string) *tool {
+	for i := range tools {
+		if tools[i].Name == name {
+			return &tools[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package remind
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/smtp"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Notifier delivers a reminder somewhere a person will see it
+type Notifier interface {
+	Notify(r Reminder) error
+}
+
+// WriterNotifier prints reminders to a writer such as os.Stdout
+type WriterNotifier struct {
+	W io.Writer
+}
+
+// Notify writes the reminder subject with a timestamp
+func (n WriterNotifier) Notify(r Reminder) error {
+	_, err := fmt.Fprintf(n.W, "[%s] ⏰ %s\n", time.Now().Format("2006-01-02 15:04"), r.Subject())
+	return err
+}
+
+// CommandNotifier runs a user-configured command for every reminder. The
+// message is passed on stdin and the item details in TODO_* environment variables.
+type CommandNotifier struct {
+	Command string
+	Args    []string
+	Timeout time.Duration
+}
+
+// Notify runs the command and fails if it exits non-zero or times out
+func (n CommandNotifier) Notify(r Reminder) error {
+	ctx := context.Background()
+	if n.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, n.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, n.Command, n.Args...)
+	cmd.Stdin = strings.NewReader(r.Message())
+	cmd.Env = append(os.Environ(),
+		"TODO_ID="+r.Item.ID,
+		"TODO_TEXT="+r.Item.Text,
+		"TODO_DUE="+r.Item.DueDate.Format("2006-01-02"),
+		"TODO_PRIORITY="+r.Item.Priority.String(),
+		"TODO_TAGS="+strings.Join(r.Item.Tags, ","),
+		"TODO_SUBJECT="+r.Subject(),
+	)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("Reminder command %s failed: %w: %s", n.Command, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// SMTPNotifier emails reminders through an SMTP server
+type SMTPNotifier struct {
+	Addr string // host:port
+	From string
+	To   []string
+	Auth smtp.Auth // optional
+}
+
+// Notify sends the reminder as a plain text email
+func (n SMTPNotifier) Notify(r Reminder) error {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", r.Subject())
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(r.Message(), "\n", "\r\n"))
+
+	if err := smtp.SendMail(n.Addr, n.Auth, n.From, n.To, msg.Bytes()); err != nil {
+		return fmt.Errorf("Sending reminder email failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package remind
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSMTPServer accepts mail on a local port and records each message
+type fakeSMTPServer struct {
+	ln net.Listener
+
+	mu       sync.Mutex
+	from     string
+	to       []string
+	messages []string
+}
+
+func startFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	s := &fakeSMTPServer{ln: ln}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost fake SMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch verb {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "MAIL":
+			s.mu.Lock()
+			s.from = line
+			s.mu.Unlock()
+			reply("250 OK")
+		case "RCPT":
+			s.mu.Lock()
+			s.to = append(s.to, line)
+			s.mu.Unlock()
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, data.String())
+			s.mu.Unlock()
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	server := startFakeSMTPServer(t)
+	list := newTestList(t, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+
+	n := SMTPNotifier{
+		Addr: server.ln.Addr().String(),
+		From: "todo@example.com",
+		To:   []string{"me@example.com"},
+	}
+	if err := n.Notify(Reminder{Item: list.Items[0], Offset: 24 * time.Hour}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	if len(server.messages) != 1 {
+		t.Fatalf("Expected 1 message, got %d", len(server.messages))
+	}
+	if !strings.Contains(server.from, "todo@example.com") {
+		t.Errorf("Unexpected sender: %s", server.from)
+	}
+	if len(server.to) != 1 || !strings.Contains(server.to[0], "me@example.com") {
+		t.Errorf("Unexpected recipients: %v", server.to)
+	}
+
+	msg := server.messages[0]
+	if !strings.Contains(msg, "Subject: Due in 1d: Submit report") {
+		t.Errorf("Message should contain subject, got:\n%s", msg)
+	}
+	if !strings.Contains(msg, "Due: 2025-06-01") {
+		t.Errorf("Message should contain due date, got:\n%s", msg)
+	}
+}
+
+func TestSMTPNotifierUnreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	list := newTestList(t, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+	n := SMTPNotifier{Addr: addr, From: "todo@example.com", To: []string{"me@example.com"}}
+	if err := n.Notify(Reminder{Item: list.Items[0]}); err == nil {
+		t.Error("Expected error when the SMTP server is unreachable")
+	}
+}
+
+func TestCommandNotifier(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	out := filepath.Join(t.TempDir(), "out.txt")
+	list := newTestList(t, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+
+	n := CommandNotifier{
+		Command: "sh",
+		Args:    []string{"-c", `{ echo "$TODO_TEXT|$TODO_DUE"; cat; } > "$0"`, out},
+		Timeout: 5 * time.Second,
+	}
+	if err := n.Notify(Reminder{Item: list.Items[0], Offset: time.Hour}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("Command did not write output: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "Submit report|2025-06-01\n") {
+		t.Errorf("Unexpected environment in command: %s", data)
+	}
+	if !strings.Contains(string(data), "Due in 1h: Submit report") {
+		t.Errorf("Expected message on stdin, got: %s", data)
+	}
+
+	failing := CommandNotifier{Command: "sh", Args: []string{"-c", "exit 3"}}
+	if err := failing.Notify(Reminder{Item: list.Items[0]}); err == nil {
+		t.Error("Expected error when the command fails")
+	}
+}
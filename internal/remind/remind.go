@@ -0,0 +1,236 @@
+// Package remind fires reminders for todo items ahead of their due dates.
+package remind
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+// Reminder is a single notification about an item that is coming due
+type Reminder struct {
+	Item   todo.Item
+	Offset time.Duration // how long before the due date the reminder was scheduled
+	At     time.Time     // when the reminder was scheduled to fire
+}
+
+// Key identifies a reminder so it fires only once. Changing the due date re-arms it.
+func (r Reminder) Key() string {
+	return fmt.Sprintf("%s|%s|%s", r.Item.ID, r.Item.DueDate.UTC().Format(time.RFC3339), r.Offset)
+}
+
+// Subject returns a one-line summary of the reminder
+func (r Reminder) Subject() string {
+	if r.Offset == 0 {
+		return fmt.Sprintf("Due now: %s", r.Item.Text)
+	}
+	return fmt.Sprintf("Due in %s: %s", formatOffset(r.Offset), r.Item.Text)
+}
+
+// Message returns the full reminder text
+func (r Reminder) Message() string {
+	msg := fmt.Sprintf("%s\nDue: %s\nPriority: %s\n",
+		r.Subject(), r.Item.DueDate.Format("2006-01-02"), r.Item.Priority)
+	if len(r.Item.Tags) > 0 {
+		msg += fmt.Sprintf("Tags: %s\n", strings.Join(r.Item.Tags, ", "))
+	}
+	return msg
+}
+
+// ParseOffsets parses a comma separated list of offsets such as "1d,2h,30m".
+// Besides the units accepted by time.ParseDuration, "d" means days.
+func ParseOffsets(s string) ([]time.Duration, error) {
+	var offsets []time.Duration
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		var d time.Duration
+		if days, ok := strings.CutSuffix(part, "d"); ok {
+			n, err := strconv.Atoi(days)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid offset: %s", part)
+			}
+			d = time.Duration(n) * 24 * time.Hour
+		} else {
+			var err error
+			d, err = time.ParseDuration(part)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid offset: %s", part)
+			}
+		}
+
+		if d < 0 {
+			return nil, fmt.Errorf("Offset cannot be negative: %s", part)
+		}
+		offsets = append(offsets, d)
+	}
+
+	if len(offsets) == 0 {
+		return nil, errors.New("No reminder offsets given")
+	}
+	return offsets, nil
+}
+
+func formatOffset(d time.Duration) string {
+	if d >= 24*time.Hour && d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	return todo.FormatDuration(d)
+}
+
+// Pending returns the reminders for incomplete items that are due to fire at now.
+// A reminder is pending from its scheduled time until maxLate has passed, so a
+// daemon that was not running for a while still catches up on recent reminders.
+func Pending(list *todo.List, offsets []time.Duration, now time.Time, maxLate time.Duration) []Reminder {
+	var reminders []Reminder
+	for _, item := range list.Items {
+		if item.Done || item.DueDate == nil {
+			continue
+		}
+		for _, offset := range offsets {
+			at := item.DueDate.Add(-offset)
+			if at.After(now) || now.Sub(at) > maxLate {
+				continue
+			}
+			reminders = append(reminders, Reminder{Item: item, Offset: offset, At: at})
+		}
+	}
+	return reminders
+}
+
+// State remembers which reminders have already fired
+type State struct {
+	path  string
+	Fired map[string]time.Time
+}
+
+// LoadState reads the fired reminders from path. A missing file yields an empty state.
+func LoadState(path string) (*State, error) {
+	s := &State{path: path, Fired: make(map[string]time.Time)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &s.Fired); err != nil {
+		return nil, fmt.Errorf("Invalid reminder state %s: %w", path, err)
+	}
+	if s.Fired == nil {
+		s.Fired = make(map[string]time.Time)
+	}
+	return s, nil
+}
+
+// Save writes the state back to the file it was loaded from
+func (s *State) Save() error {
+	data, err := json.Marshal(s.Fired)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0644)
+}
+
+// Daemon periodically checks the todo list and delivers reminders
+type Daemon struct {
+	// Load returns the current todo list; it is called on every check so
+	// changes made by other commands are picked up
+	Load func() (*todo.List, error)
+
+	Offsets   []time.Duration
+	Notifiers []Notifier
+	State     *State
+
+	// MaxLate bounds how long after its scheduled time a missed reminder is still sent
+	MaxLate time.Duration
+
+	// Interval between checks in Run
+	Interval time.Duration
+}
+
+// Check delivers all pending reminders that have not fired yet and returns how
+// many were sent. A reminder counts as fired once any notifier delivered it.
+func (d *Daemon) Check(now time.Time) (int, error) {
+	list, err := d.Load()
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	sent := 0
+	for _, r := range Pending(list, d.Offsets, now, d.MaxLate) {
+		key := r.Key()
+		if _, ok := d.State.Fired[key]; ok {
+			continue
+		}
+
+		delivered := false
+		for _, n := range d.Notifiers {
+			if err := n.Notify(r); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			delivered = true
+		}
+
+		if delivered {
+			d.State.Fired[key] = now
+			sent++
+		}
+	}
+
+	before := len(d.State.Fired)
+	d.prune(now)
+	if sent > 0 || len(d.State.Fired) != before {
+		if err := d.State.Save(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return sent, errors.Join(errs...)
+}
+
+// prune forgets reminders that can no longer become pending. A reminder fires
+// at or after its scheduled time, so once MaxLate has passed since it fired it
+// is outside the catch-up window as well.
+func (d *Daemon) prune(now time.Time) {
+	for key, firedAt := range d.State.Fired {
+		if now.Sub(firedAt) > d.MaxLate {
+			delete(d.State.Fired, key)
+		}
+	}
+}
+
+// Run checks for reminders every Interval until ctx is cancelled. Errors from
+// individual checks are passed to onError, if set, and do not stop the daemon.
+func (d *Daemon) Run(ctx context.Context, onError func(error)) error {
+	if d.Interval <= 0 {
+		return fmt.Errorf("Invalid interval %s: must be positive", d.Interval)
+	}
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.Check(time.Now()); err != nil && onError != nil {
+			onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
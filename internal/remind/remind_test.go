@@ -0,0 +1,232 @@
+package remind
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+// recordingNotifier remembers every reminder it receives
+type recordingNotifier struct {
+	got []Reminder
+	err error
+}
+
+func (n *recordingNotifier) Notify(r Reminder) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.got = append(n.got, r)
+	return nil
+}
+
+func newTestList(t *testing.T, due time.Time) *todo.List {
+	t.Helper()
+	list := todo.NewList()
+	if err := list.Add("Submit report"); err != nil {
+		t.Fatalf("Failed to add item: %v", err)
+	}
+	if err := list.SetDueDate(0, due); err != nil {
+		t.Fatalf("Failed to set due date: %v", err)
+	}
+	return list
+}
+
+func newTestDaemon(t *testing.T, list *todo.List, notifiers ...Notifier) *Daemon {
+	t.Helper()
+	state, err := LoadState(filepath.Join(t.TempDir(), "reminders.json"))
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	return &Daemon{
+		Load:      func() (*todo.List, error) { return list, nil },
+		Offsets:   []time.Duration{24 * time.Hour, time.Hour},
+		Notifiers: notifiers,
+		State:     state,
+		MaxLate:   2 * time.Hour,
+	}
+}
+
+func TestParseOffsets(t *testing.T) {
+	offsets, err := ParseOffsets("1d, 2h,30m,0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []time.Duration{24 * time.Hour, 2 * time.Hour, 30 * time.Minute, 0}
+	if len(offsets) != len(expected) {
+		t.Fatalf("Expected %d offsets, got %d", len(expected), len(offsets))
+	}
+	for i := range expected {
+		if offsets[i] != expected[i] {
+			t.Errorf("Offset %d = %v, expected %v", i, offsets[i], expected[i])
+		}
+	}
+
+	for _, input := range []string{"", "soon", "xd", "-1h"} {
+		if _, err := ParseOffsets(input); err == nil {
+			t.Errorf("Expected error for offsets %q", input)
+		}
+	}
+}
+
+func TestReminderSubject(t *testing.T) {
+	list := newTestList(t, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+	item := list.Items[0]
+
+	if s := (Reminder{Item: item, Offset: 24 * time.Hour}).Subject(); s != "Due in 1d: Submit report" {
+		t.Errorf("Unexpected subject: %s", s)
+	}
+	if s := (Reminder{Item: item, Offset: 90 * time.Minute}).Subject(); s != "Due in 1h30m: Submit report" {
+		t.Errorf("Unexpected subject: %s", s)
+	}
+	if s := (Reminder{Item: item}).Subject(); s != "Due now: Submit report" {
+		t.Errorf("Unexpected subject: %s", s)
+	}
+}
+
+func TestPending(t *testing.T) {
+	due := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	list := newTestList(t, due)
+	offsets := []time.Duration{24 * time.Hour, time.Hour}
+
+	tests := []struct {
+		now      time.Time
+		expected int
+	}{
+		{due.Add(-48 * time.Hour), 0},              // too early
+		{due.Add(-23 * time.Hour), 1},              // day-before reminder
+		{due.Add(-30 * time.Minute), 1},            // day-before is stale, hour-before fires
+		{due.Add(-24*time.Hour + 3*time.Hour), 0},  // missed by more than maxLate
+		{due.Add(-time.Hour + 90*time.Minute), 1},  // hour-before still within maxLate
+		{due.Add(-time.Hour + 2*time.Hour + 1), 0}, // hour-before stale too
+	}
+
+	for _, tt := range tests {
+		got := Pending(list, offsets, tt.now, 2*time.Hour)
+		if len(got) != tt.expected {
+			t.Errorf("Pending at %v: expected %d reminders, got %d", tt.now, tt.expected, len(got))
+		}
+	}
+
+	// Completed items never remind
+	if err := list.Complete(0); err != nil {
+		t.Fatal(err)
+	}
+	if got := Pending(list, offsets, due.Add(-23*time.Hour), 2*time.Hour); len(got) != 0 {
+		t.Errorf("Expected no reminders for completed item, got %d", len(got))
+	}
+}
+
+func TestDaemonFiresOnce(t *testing.T) {
+	due := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	list := newTestList(t, due)
+	notifier := &recordingNotifier{}
+	d := newTestDaemon(t, list, notifier)
+
+	now := due.Add(-23 * time.Hour)
+	sent, err := d.Check(now)
+	if err != nil || sent != 1 {
+		t.Fatalf("Expected 1 reminder sent, got %d (err: %v)", sent, err)
+	}
+
+	// A second check does not repeat the reminder
+	sent, err = d.Check(now.Add(time.Minute))
+	if err != nil || sent != 0 {
+		t.Fatalf("Expected no repeated reminder, got %d (err: %v)", sent, err)
+	}
+
+	// Restarting with the saved state does not repeat it either
+	state, err := LoadState(d.State.path)
+	if err != nil {
+		t.Fatalf("Failed to reload state: %v", err)
+	}
+	d.State = state
+	if sent, _ := d.Check(now.Add(2 * time.Minute)); sent != 0 {
+		t.Errorf("Expected no reminder after restart, got %d", sent)
+	}
+
+	// Moving the due date re-arms the reminders
+	if err := list.SetDueDate(0, due.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if sent, _ := d.Check(now.Add(3 * time.Minute)); sent != 1 {
+		t.Errorf("Expected reminder for new due date, got %d", sent)
+	}
+
+	if len(notifier.got) != 2 {
+		t.Errorf("Expected 2 delivered reminders, got %d", len(notifier.got))
+	}
+}
+
+func TestDaemonRetriesFailedDelivery(t *testing.T) {
+	due := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	list := newTestList(t, due)
+	failing := &recordingNotifier{err: errors.New("mail server down")}
+	d := newTestDaemon(t, list, failing)
+
+	now := due.Add(-30 * time.Minute)
+	if _, err := d.Check(now); err == nil {
+		t.Fatal("Expected delivery error")
+	}
+
+	failing.err = nil
+	if sent, err := d.Check(now.Add(time.Minute)); err != nil || sent != 1 {
+		t.Errorf("Expected reminder on retry, got %d (err: %v)", sent, err)
+	}
+}
+
+func TestDaemonPrunesOldState(t *testing.T) {
+	due := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	list := newTestList(t, due)
+	d := newTestDaemon(t, list, &recordingNotifier{})
+
+	if sent, _ := d.Check(due.Add(-30 * time.Minute)); sent != 1 {
+		t.Fatalf("Expected 1 reminder, got %d", sent)
+	}
+	if _, err := d.Check(due.Add(24 * time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.State.Fired) != 0 {
+		t.Errorf("Expected fired reminders to be pruned, got %v", d.State.Fired)
+	}
+}
+
+func TestDaemonRunRejectsInterval(t *testing.T) {
+	d := newTestDaemon(t, todo.NewList())
+	for _, interval := range []time.Duration{0, -time.Minute} {
+		d.Interval = interval
+		if err := d.Run(context.Background(), nil); err == nil {
+			t.Errorf("Expected an error for interval %s", interval)
+		}
+	}
+}
+
+func TestLoadStateInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reminders.json")
+	if err := os.WriteFile(path, []byte("{{{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadState(path); err == nil {
+		t.Error("Expected error for invalid state file")
+	}
+}
+
+func TestWriterNotifier(t *testing.T) {
+	list := newTestList(t, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+	var buf bytes.Buffer
+
+	if err := (WriterNotifier{W: &buf}).Notify(Reminder{Item: list.Items[0], Offset: time.Hour}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Due in 1h: Submit report") {
+		t.Errorf("Unexpected output: %s", buf.String())
+	}
+}
@@ -0,0 +1,469 @@
+// Package rpc exposes a todo list to long-running clients such as editor
+// plugins. Service implements the list operations; Register serves them as
+// JSON-RPC methods, and Watch reports changes other programs make to the file.
+package rpc
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rahul4507/todo/internal/filewatch"
+	"github.com/rahul4507/todo/internal/jsonrpc"
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+// ChangedMethod is the notification sent when the list changes on disk
+const ChangedMethod = "changed"
+
+// Service runs list operations against a store. Each operation loads the
+// list, so changes made by other programs are always seen.
+type Service struct {
+	Store todo.Store
+	// User is recorded as the creator of tasks added
+	User string
+
+	// mu serializes load-modify-save cycles on the store
+	mu sync.Mutex
+	// version is the version of the list as last loaded or saved
+	version string
+}
+
+// Ref picks a task by ID or by its number in the list, counting from 1
+type Ref struct {
+	ID     string `json:"id,omitempty"`
+	Number int    `json:"number,omitempty"`
+}
+
+// ItemResult is a task together with its current number in the list
+type ItemResult struct {
+	Number int       `json:"number"`
+	Item   todo.Item `json:"item"`
+}
+
+// ListResult is the whole list
+type ListResult struct {
+	Version string       `json:"version"`
+	Items   []ItemResult `json:"items"`
+}
+
+// ChangedParams are sent with ChangedMethod
+type ChangedParams struct {
+	Version string `json:"version"`
+}
+
+// AddParams describe a new task; only the text is required
+type AddParams struct {
+	Text     string   `json:"text"`
+	Priority string   `json:"priority,omitempty"`
+	Due      string   `json:"due,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// EditParams change the text of a task
+type EditParams struct {
+	Ref
+	Text string `json:"text"`
+}
+
+// PriorityParams set the priority of a task
+type PriorityParams struct {
+	Ref
+	Priority string `json:"priority"`
+}
+
+// DueParams set the due date of a task, as YYYY-MM-DD
+type DueParams struct {
+	Ref
+	Due string `json:"due"`
+}
+
+// TagParams add tags to a task
+type TagParams struct {
+	Ref
+	Tags []string `json:"tags"`
+}
+
+// SearchParams search text and tags, optionally only a user's tasks
+type SearchParams struct {
+	Query    string `json:"query"`
+	Assignee string `json:"assignee,omitempty"`
+}
+
+// FilterParams select tasks; empty fields match everything
+type FilterParams struct {
+	Priority string `json:"priority,omitempty"`
+	Tag      string `json:"tag,omitempty"`
+	Assignee string `json:"assignee,omitempty"`
+	// Status is "pending", "done" or empty for all
+	Status string `json:"status,omitempty"`
+}
+
+// Version changes whenever anything in the list changes
+func Version(list *todo.List) string {
+	data, _ := json.Marshal(list.Items)
+	sum := sha1.Sum(data)
+	return hex.EncodeToString(sum[:10])
+}
+
+// Version returns the version of the list as last loaded or saved
+func (s *Service) Version() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.version
+}
+
+// load reads the list; the lock must be held
+func (s *Service) load() (*todo.List, error) {
+	list, err := s.Store.Load()
+	if err != nil {
+		return nil, err
+	}
+	list.User = s.User
+	s.version = Version(list)
+	return list, nil
+}
+
+// save writes the list; the lock must be held
+func (s *Service) save(list *todo.List) error {
+	if err := s.Store.Save(list); err != nil {
+		return err
+	}
+	s.version = Version(list)
+	return nil
+}
+
+// resolve returns the index of the task ref points to
+func resolve(list *todo.List, ref Ref) (int, error) {
+	switch {
+	case ref.ID != "":
+		if i := list.IndexOf(ref.ID); i >= 0 {
+			return i, nil
+		}
+		return 0, jsonrpc.InvalidParams("No task with id %s", ref.ID)
+	case ref.Number > 0 && ref.Number <= len(list.Items):
+		return ref.Number - 1, nil
+	case ref.Number != 0:
+		return 0, jsonrpc.InvalidParams("No task number %d", ref.Number)
+	}
+	return 0, jsonrpc.InvalidParams("Missing id or number")
+}
+
+func results(list *todo.List, items []todo.Item) []ItemResult {
+	out := []ItemResult{}
+	for _, item := range items {
+		out = append(out, ItemResult{Number: list.IndexOf(item.ID) + 1, Item: item})
+	}
+	return out
+}
+
+// Modify applies change to the task ref points to, saves the list and
+// returns the task as changed
+func (s *Service) Modify(ref Ref, change func(list *todo.List, index int) error) (ItemResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.load()
+	if err != nil {
+		return ItemResult{}, err
+	}
+	index, err := resolve(list, ref)
+	if err != nil {
+		return ItemResult{}, err
+	}
+	item := list.Items[index]
+	if err := change(list, index); err != nil {
+		return ItemResult{}, jsonrpc.InvalidParams("%s", err)
+	}
+	if err := s.save(list); err != nil {
+		return ItemResult{}, err
+	}
+	// The task may have moved, or be gone
+	if i := list.IndexOf(item.ID); i >= 0 {
+		return ItemResult{Number: i + 1, Item: list.Items[i]}, nil
+	}
+	return ItemResult{Item: item}, nil
+}
+
+// read runs fn on the current list
+func (s *Service) read(fn func(list *todo.List) any) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return fn(list), nil
+}
+
+// List returns every task
+func (s *Service) List() (ListResult, error) {
+	result, err := s.read(func(list *todo.List) any {
+		return ListResult{Version: Version(list), Items: results(list, list.Items)}
+	})
+	if err != nil {
+		return ListResult{}, err
+	}
+	return result.(ListResult), nil
+}
+
+// Add adds a task
+func (s *Service) Add(p AddParams) (ItemResult, error) {
+	item := todo.NewItem(strings.TrimSpace(p.Text))
+	if item.Text == "" {
+		return ItemResult{}, jsonrpc.InvalidParams("Task text cannot be empty")
+	}
+	if p.Priority != "" {
+		priority, err := parsePriority(p.Priority)
+		if err != nil {
+			return ItemResult{}, err
+		}
+		item.Priority = priority
+	}
+	if p.Due != "" {
+		due, err := parseDate(p.Due)
+		if err != nil {
+			return ItemResult{}, err
+		}
+		item.DueDate = &due
+	}
+	for _, tag := range p.Tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			item.Tags = append(item.Tags, tag)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list, err := s.load()
+	if err != nil {
+		return ItemResult{}, err
+	}
+	if err := list.AddItem(item); err != nil {
+		return ItemResult{}, jsonrpc.InvalidParams("%s", err)
+	}
+	if err := s.save(list); err != nil {
+		return ItemResult{}, err
+	}
+	i := list.IndexOf(item.ID)
+	return ItemResult{Number: i + 1, Item: list.Items[i]}, nil
+}
+
+// Search returns tasks whose text or tags contain the query
+func (s *Service) Search(p SearchParams) ([]ItemResult, error) {
+	if strings.TrimSpace(p.Query) == "" {
+		return nil, jsonrpc.InvalidParams("Missing query")
+	}
+	result, err := s.read(func(list *todo.List) any {
+		items := list.Search(p.Query)
+		if p.Assignee != "" {
+			items = todo.AssignedTo(items, p.Assignee)
+		}
+		return results(list, items)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]ItemResult), nil
+}
+
+// Filter returns the tasks matching every field set in p
+func (s *Service) Filter(p FilterParams) ([]ItemResult, error) {
+	var priority todo.Priority
+	if p.Priority != "" {
+		var err error
+		if priority, err = parsePriority(p.Priority); err != nil {
+			return nil, err
+		}
+	}
+	if p.Status != "" && p.Status != "pending" && p.Status != "done" {
+		return nil, jsonrpc.InvalidParams("Invalid status %q (use pending or done)", p.Status)
+	}
+
+	filter := todo.Filter{Tag: p.Tag, Assignee: p.Assignee, Status: p.Status}
+	if p.Priority != "" {
+		filter.Priority = &priority
+	}
+	result, err := s.read(func(list *todo.List) any {
+		return results(list, list.Filter(filter))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]ItemResult), nil
+}
+
+// Complete marks a task as done
+func (s *Service) Complete(ref Ref) (ItemResult, error) {
+	return s.Modify(ref, (*todo.List).Complete)
+}
+
+// Uncomplete marks a task as pending again
+func (s *Service) Uncomplete(ref Ref) (ItemResult, error) {
+	return s.Modify(ref, (*todo.List).Uncomplete)
+}
+
+// Delete removes a task, returning it as it was
+func (s *Service) Delete(ref Ref) (ItemResult, error) {
+	return s.Modify(ref, (*todo.List).Delete)
+}
+
+// Edit changes the text of a task
+func (s *Service) Edit(p EditParams) (ItemResult, error) {
+	return s.Modify(p.Ref, func(list *todo.List, index int) error {
+		return list.Edit(index, strings.TrimSpace(p.Text))
+	})
+}
+
+// Overdue returns pending tasks that are past their due date
+func (s *Service) Overdue() ([]ItemResult, error) {
+	result, err := s.read(func(list *todo.List) any {
+		return results(list, list.GetOverdue())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]ItemResult), nil
+}
+
+// SetPriority sets the priority of a task
+func (s *Service) SetPriority(p PriorityParams) (ItemResult, error) {
+	if p.Priority == "" {
+		return ItemResult{}, jsonrpc.InvalidParams("Missing priority")
+	}
+	priority, err := parsePriority(p.Priority)
+	if err != nil {
+		return ItemResult{}, err
+	}
+	return s.Modify(p.Ref, func(list *todo.List, index int) error {
+		return list.SetPriority(index, priority)
+	})
+}
+
+// SetDueDate sets the due date of a task
+func (s *Service) SetDueDate(p DueParams) (ItemResult, error) {
+	if p.Due == "" {
+		return ItemResult{}, jsonrpc.InvalidParams("Missing due date")
+	}
+	due, err := parseDate(p.Due)
+	if err != nil {
+		return ItemResult{}, err
+	}
+	return s.Modify(p.Ref, func(list *todo.List, index int) error {
+		return list.SetDueDate(index, due)
+	})
+}
+
+// Tag adds tags to a task, skipping those it already has
+func (s *Service) Tag(p TagParams) (ItemResult, error) {
+	var tags []string
+	for _, tag := range p.Tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) == 0 {
+		return ItemResult{}, jsonrpc.InvalidParams("Missing tags")
+	}
+	return s.Modify(p.Ref, func(list *todo.List, index int) error {
+		for _, tag := range tags {
+			if !list.Items[index].HasTag(tag) {
+				if err := list.AddTag(index, tag); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Text returns the list as `todo list` prints it
+func (s *Service) Text() (string, error) {
+	result, err := s.read(func(list *todo.List) any {
+		return list.String()
+	})
+	if err != nil {
+		return "", err
+	}
+	return result.(string), nil
+}
+
+// Stats returns statistics about the list
+func (s *Service) Stats() (todo.Stats, error) {
+	result, err := s.read(func(list *todo.List) any {
+		return list.GetStats()
+	})
+	if err != nil {
+		return todo.Stats{}, err
+	}
+	return result.(todo.Stats), nil
+}
+
+func parsePriority(s string) (todo.Priority, error) {
+	if priority, ok := todo.LookupPriority(s); ok {
+		return priority, nil
+	}
+	return 0, jsonrpc.InvalidParams("Invalid priority %q (use high, medium or low)", s)
+}
+
+func parseDate(s string) (time.Time, error) {
+	due, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, jsonrpc.InvalidParams("Invalid date %q. Use YYYY-MM-DD", s)
+	}
+	return due, nil
+}
+
+// method adapts a Service operation to a JSON-RPC handler
+func method[P any, R any](op func(P) (R, error)) jsonrpc.Handler {
+	return func(ctx context.Context, params json.RawMessage) (any, error) {
+		var p P
+		if err := jsonrpc.Decode(params, &p); err != nil {
+			return nil, err
+		}
+		return op(p)
+	}
+}
+
+// Register serves the operations as JSON-RPC methods
+func (s *Service) Register(srv *jsonrpc.Server) {
+	srv.Handle("list", method(func(struct{}) (ListResult, error) { return s.List() }))
+	srv.Handle("add", method(s.Add))
+	srv.Handle("complete", method(s.Complete))
+	srv.Handle("uncomplete", method(s.Uncomplete))
+	srv.Handle("edit", method(s.Edit))
+	srv.Handle("delete", method(s.Delete))
+	srv.Handle("priority", method(s.SetPriority))
+	srv.Handle("due", method(s.SetDueDate))
+	srv.Handle("tag", method(s.Tag))
+	srv.Handle("search", method(s.Search))
+	srv.Handle("filter", method(s.Filter))
+	srv.Handle("overdue", method(func(struct{}) ([]ItemResult, error) { return s.Overdue() }))
+	srv.Handle("stats", method(func(struct{}) (todo.Stats, error) { return s.Stats() }))
+}
+
+// Watch calls changed whenever the list is changed by another program,
+// until ctx is done. The file at path is checked every interval as
+// filewatch.Poll does; changes made through the service itself are not
+// reported.
+func (s *Service) Watch(ctx context.Context, path string, interval time.Duration, changed func(version string), onError func(error)) {
+	filewatch.Poll(ctx, path, interval, func() error {
+		s.mu.Lock()
+		previous := s.version
+		list, err := s.Store.Load()
+		if err == nil {
+			s.version = Version(list)
+		}
+		current := s.version
+		s.mu.Unlock()
+
+		if err == nil && previous != "" && current != previous {
+			changed(current)
+		}
+		return err
+	}, onError)
+}
@@ -0,0 +1,219 @@
+package rpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rahul4507/todo/internal/jsonrpc"
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+// client talks to a service served over pipes, as an editor would over stdio
+type client struct {
+	t   *testing.T
+	in  io.Writer
+	out *bufio.Scanner
+	id  int
+}
+
+type message struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *jsonrpc.Error  `json:"error"`
+}
+
+func newClient(t *testing.T) (*client, todo.Store) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "todos.json")
+	store := todo.FileStore{Path: path}
+	list := todo.NewList()
+	if err := list.Add("Write report"); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.AddTag(0, "work"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(list); err != nil {
+		t.Fatal(err)
+	}
+
+	service := &Service{Store: store, User: "alice"}
+	srv := jsonrpc.NewServer()
+	service.Register(srv)
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		srv.Serve(ctx, inR, outW)
+		outW.Close()
+		close(done)
+	}()
+	go service.Watch(ctx, path, 10*time.Millisecond, func(version string) {
+		srv.Notify(ChangedMethod, ChangedParams{Version: version})
+	}, func(err error) { t.Error(err) })
+	t.Cleanup(func() {
+		cancel()
+		inW.Close()
+		<-done
+	})
+	return &client{t: t, in: inW, out: bufio.NewScanner(outR)}, store
+}
+
+// read returns the next message from the server
+func (c *client) read() message {
+	c.t.Helper()
+	if !c.out.Scan() {
+		c.t.Fatalf("Expected a message, got %v", c.out.Err())
+	}
+	var msg message
+	if err := json.Unmarshal(c.out.Bytes(), &msg); err != nil {
+		c.t.Fatal(err)
+	}
+	return msg
+}
+
+// call sends a request and decodes its result into result, returning any
+// error the server sent
+func (c *client) call(method string, params any, result any) *jsonrpc.Error {
+	c.t.Helper()
+	c.id++
+	data, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": c.id, "method": method, "params": params})
+	if _, err := fmt.Fprintf(c.in, "%s\n", data); err != nil {
+		c.t.Fatal(err)
+	}
+	msg := c.read()
+	if string(msg.ID) != fmt.Sprint(c.id) {
+		c.t.Fatalf("Expected a reply to %d, got %+v", c.id, msg)
+	}
+	if msg.Error != nil {
+		return msg.Error
+	}
+	if result != nil {
+		if err := json.Unmarshal(msg.Result, result); err != nil {
+			c.t.Fatal(err)
+		}
+	}
+	return nil
+}
+
+func TestMethods(t *testing.T) {
+	c, _ := newClient(t)
+
+	var added ItemResult
+	if err := c.call("add", AddParams{Text: "Fix bug", Priority: "high", Due: "2030-01-02", Tags: []string{"work"}}, &added); err != nil {
+		t.Fatal(err)
+	}
+	if added.Number != 2 || added.Item.Priority != todo.PriorityHigh || added.Item.Creator != "alice" || added.Item.DueDate == nil {
+		t.Errorf("Expected a second, high priority task, got %+v", added)
+	}
+
+	if err := c.call("add", AddParams{Text: "Fix bug"}, nil); err == nil || err.Code != jsonrpc.CodeInvalidParams {
+		t.Errorf("Expected an invalid params error adding a duplicate, got %v", err)
+	}
+	if err := c.call("add", AddParams{Text: "Other", Due: "tomorrow"}, nil); err == nil || err.Code != jsonrpc.CodeInvalidParams {
+		t.Errorf("Expected an invalid params error for a bad date, got %v", err)
+	}
+
+	var completed ItemResult
+	if err := c.call("complete", Ref{ID: added.Item.ID}, &completed); err != nil {
+		t.Fatal(err)
+	}
+	if !completed.Item.Done || completed.Item.ID != added.Item.ID {
+		t.Errorf("Expected the task to be done, got %+v", completed)
+	}
+	if err := c.call("complete", Ref{Number: 9}, nil); err == nil {
+		t.Error("Expected an error for a missing task")
+	}
+
+	var edited ItemResult
+	if err := c.call("edit", EditParams{Ref: Ref{Number: 1}, Text: "Write the report"}, &edited); err != nil {
+		t.Fatal(err)
+	}
+	if edited.Item.Text != "Write the report" {
+		t.Errorf("Expected the text to change, got %q", edited.Item.Text)
+	}
+
+	var found []ItemResult
+	if err := c.call("search", SearchParams{Query: "REPORT"}, &found); err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].Item.ID != edited.Item.ID {
+		t.Errorf("Expected to find the report, got %+v", found)
+	}
+
+	var pending []ItemResult
+	if err := c.call("filter", FilterParams{Tag: "work", Status: "pending"}, &pending); err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].Item.Text != "Write the report" {
+		t.Errorf("Expected one pending work task, got %+v", pending)
+	}
+	if err := c.call("filter", FilterParams{Status: "later"}, nil); err == nil {
+		t.Error("Expected an error for an unknown status")
+	}
+
+	var stats todo.Stats
+	if err := c.call("stats", nil, &stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Total != 2 || stats.Completed != 1 {
+		t.Errorf("Expected 2 tasks with 1 done, got %+v", stats)
+	}
+
+	if err := c.call("stats", map[string]int{"extra": 1}, nil); err == nil {
+		t.Error("Expected an error for unknown params")
+	}
+}
+
+func TestChangedNotification(t *testing.T) {
+	c, store := newClient(t)
+
+	var before ListResult
+	if err := c.call("list", nil, &before); err != nil {
+		t.Fatal(err)
+	}
+
+	// Changes through the service are not announced
+	if err := c.call("add", AddParams{Text: "Buy milk"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	list, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := list.Add("Call mum"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(list); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := c.read()
+	if msg.Method != ChangedMethod {
+		t.Fatalf("Expected a %s notification, got %+v", ChangedMethod, msg)
+	}
+	var params ChangedParams
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		t.Fatal(err)
+	}
+
+	var after ListResult
+	if err := c.call("list", nil, &after); err != nil {
+		t.Fatal(err)
+	}
+	if after.Version != params.Version || after.Version == before.Version || len(after.Items) != 3 {
+		t.Errorf("Expected version %s with 3 tasks, got %s with %d", params.Version, after.Version, len(after.Items))
+	}
+}
@@ -0,0 +1,351 @@
+// Package sqlstore keeps the todo list in a SQLite database, with indexes on
+// due date, priority and tags so queries don't need to load the whole list.
+package sqlstore
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rahul4507/todo/internal/todo"
+
+	_ "modernc.org/sqlite"
+)
+
+// Items are kept whole as JSON in the data column, so every field round
+// trips; the other columns copy the fields that queries filter and sort on.
+const schema = `
+CREATE TABLE IF NOT EXISTS items (
+	id                TEXT PRIMARY KEY,
+	position          INTEGER NOT NULL,
+	text              TEXT NOT NULL,
+	done              INTEGER NOT NULL,
+	priority          INTEGER NOT NULL,
+	due               INTEGER,
+	estimate_duration INTEGER NOT NULL DEFAULT 0,
+	estimate_points   REAL NOT NULL DEFAULT 0,
+	data              TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS items_order ON items(done, position);
+CREATE INDEX IF NOT EXISTS items_due ON items(done, due);
+CREATE INDEX IF NOT EXISTS items_priority ON items(priority, position);
+CREATE INDEX IF NOT EXISTS items_text ON items(text);
+
+CREATE TABLE IF NOT EXISTS tags (
+	item_id TEXT NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+	tag     TEXT NOT NULL,
+	PRIMARY KEY (item_id, tag)
+);
+CREATE INDEX IF NOT EXISTS tags_tag ON tags(tag);
+`
+
+// Store keeps the list in a SQLite database. Besides loading and saving the
+// whole list it implements todo.Querier, and todo.Mutator to change items in
+// place.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens the database at path, creating it if needed
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("Opening %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Load reads the whole list
+func (s *Store) Load() (*todo.List, error) {
+	items, err := s.query(`SELECT data FROM items ORDER BY position`)
+	if err != nil {
+		return nil, err
+	}
+	list := todo.NewList()
+	list.Items = append(list.Items, items...)
+	return list, nil
+}
+
+// Save makes the database match list, writing only the items that changed
+func (s *Store) Save(list *todo.List) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	type storedItem struct {
+		position int
+		data     string
+	}
+	stored := make(map[string]storedItem)
+	rows, err := tx.Query(`SELECT id, position, data FROM items`)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var id string
+		var si storedItem
+		if err := rows.Scan(&id, &si.position, &si.data); err != nil {
+			rows.Close()
+			return err
+		}
+		stored[id] = si
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(list.Items))
+	for i, item := range list.Items {
+		if item.ID == "" {
+			return fmt.Errorf("Item %q has no ID", item.Text)
+		}
+		if seen[item.ID] {
+			return fmt.Errorf("Duplicate item ID %s", item.ID)
+		}
+		seen[item.ID] = true
+
+		data, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		old, exists := stored[item.ID]
+		if exists && old.position == i && old.data == string(data) {
+			continue
+		}
+		if err := writeItem(tx, i, item, data); err != nil {
+			return err
+		}
+		if !exists || old.data != string(data) {
+			if err := writeTags(tx, item); err != nil {
+				return err
+			}
+		}
+	}
+	for id := range stored {
+		if !seen[id] {
+			if _, err := tx.Exec(`DELETE FROM items WHERE id = ?`, id); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// ItemAt returns the item at index, in list order
+func (s *Store) ItemAt(index int) (todo.Item, error) {
+	if index < 0 {
+		return todo.Item{}, errors.New("Item index out of Range")
+	}
+	items, err := s.query(`SELECT data FROM items ORDER BY position LIMIT 1 OFFSET ?`, index)
+	if err != nil {
+		return todo.Item{}, err
+	}
+	if len(items) == 0 {
+		return todo.Item{}, errors.New("Item index out of Range")
+	}
+	return items[0], nil
+}
+
+// Apply saves changes to single items, writing only their rows. Like
+// List.AddItem it adds items to the end and rejects text or IDs already in
+// the list; items completed or uncompleted move like with List.Complete.
+// Nothing is saved if a change can't be made.
+func (s *Store) Apply(changes []todo.Event) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	resort := false
+	for _, change := range changes {
+		item := change.Item
+		if item.ID == "" {
+			return fmt.Errorf("Item %q has no ID", item.Text)
+		}
+		var position int
+		switch change.Type {
+		case todo.EventDeleted, todo.EventCleared:
+			if _, err := tx.Exec(`DELETE FROM items WHERE id = ?`, item.ID); err != nil {
+				return err
+			}
+			continue
+
+		case todo.EventAdded:
+			if item.Text == "" {
+				return errors.New("Task text cannot be empty")
+			}
+			var exists bool
+			if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM items WHERE text = ? OR id = ?)`, item.Text, item.ID).Scan(&exists); err != nil {
+				return err
+			}
+			if exists {
+				return errors.New("Item already exists in the list")
+			}
+			if err := tx.QueryRow(`SELECT coalesce(max(position), -1) + 1 FROM items`).Scan(&position); err != nil {
+				return err
+			}
+
+		default:
+			err := tx.QueryRow(`SELECT position FROM items WHERE id = ?`, item.ID).Scan(&position)
+			if errors.Is(err, sql.ErrNoRows) {
+				return errors.New("Item not found")
+			}
+			if err != nil {
+				return err
+			}
+			resort = resort || item.Done != change.Previous.Done
+		}
+
+		data, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		if err := writeItem(tx, position, item, data); err != nil {
+			return err
+		}
+		if err := writeTags(tx, item); err != nil {
+			return err
+		}
+	}
+	if resort {
+		if err := sortItems(tx); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Search returns items whose text or tags contain the query. Like
+// List.Search it ignores case, though only for ASCII letters.
+func (s *Store) Search(query string) ([]todo.Item, error) {
+	pattern := "%" + escapeLike(query) + "%"
+	return s.query(`SELECT data FROM items
+		WHERE text LIKE ?1 ESCAPE '\'
+		   OR id IN (SELECT item_id FROM tags WHERE tag LIKE ?1 ESCAPE '\')
+		ORDER BY position`, pattern)
+}
+
+// FilterByPriority returns items with the given priority
+func (s *Store) FilterByPriority(priority todo.Priority) ([]todo.Item, error) {
+	return s.query(`SELECT data FROM items WHERE priority = ? ORDER BY position`, int(priority))
+}
+
+// FilterByTag returns items with the given tag
+func (s *Store) FilterByTag(tag string) ([]todo.Item, error) {
+	return s.query(`SELECT data FROM items
+		WHERE id IN (SELECT item_id FROM tags WHERE tag = ?)
+		ORDER BY position`, tag)
+}
+
+// Overdue returns pending items that were due before now
+func (s *Store) Overdue(now time.Time) ([]todo.Item, error) {
+	return s.query(`SELECT data FROM items WHERE due < ? AND done = 0 ORDER BY position`, now.UnixNano())
+}
+
+// Stats returns the same statistics as List.GetStats
+func (s *Store) Stats() (todo.Stats, error) {
+	var stats todo.Stats
+	var estimated int64
+	err := s.db.QueryRow(`SELECT
+		count(*),
+		coalesce(sum(done), 0),
+		coalesce(sum(CASE WHEN done = 0 THEN estimate_duration END), 0),
+		coalesce(sum(CASE WHEN done = 0 THEN estimate_points END), 0)
+		FROM items`).Scan(&stats.Total, &stats.Completed, &estimated, &stats.EstimatedPoints)
+	if err != nil {
+		return stats, err
+	}
+	stats.Pending = stats.Total - stats.Completed
+	stats.EstimatedTime = time.Duration(estimated)
+	return stats, nil
+}
+
+func (s *Store) query(query string, args ...any) ([]todo.Item, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []todo.Item
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var item todo.Item
+		if err := json.Unmarshal([]byte(data), &item); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// writeItem inserts or replaces the row for item
+func writeItem(tx *sql.Tx, position int, item todo.Item, data []byte) error {
+	var due sql.NullInt64
+	if item.DueDate != nil {
+		due = sql.NullInt64{Int64: item.DueDate.UnixNano(), Valid: true}
+	}
+	var estimateDuration int64
+	var estimatePoints float64
+	if item.Estimate != nil {
+		estimateDuration, estimatePoints = int64(item.Estimate.Duration), item.Estimate.Points
+	}
+	_, err := tx.Exec(`INSERT INTO items
+		(id, position, text, done, priority, due, estimate_duration, estimate_points, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			position = excluded.position,
+			text = excluded.text,
+			done = excluded.done,
+			priority = excluded.priority,
+			due = excluded.due,
+			estimate_duration = excluded.estimate_duration,
+			estimate_points = excluded.estimate_points,
+			data = excluded.data`,
+		item.ID, position, item.Text, item.Done, int(item.Priority), due,
+		estimateDuration, estimatePoints, string(data))
+	return err
+}
+
+func writeTags(tx *sql.Tx, item todo.Item) error {
+	if _, err := tx.Exec(`DELETE FROM tags WHERE item_id = ?`, item.ID); err != nil {
+		return err
+	}
+	for _, tag := range item.Tags {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO tags (item_id, tag) VALUES (?, ?)`, item.ID, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sortItems renumbers the items so pending ones come first, like List.Sort
+func sortItems(tx *sql.Tx) error {
+	_, err := tx.Exec(`UPDATE items SET position = sorted.position
+		FROM (SELECT id, row_number() OVER (ORDER BY done, position) - 1 AS position FROM items) AS sorted
+		WHERE items.id = sorted.id AND items.position != sorted.position`)
+	return err
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,243 @@
+package sqlstore
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+func openStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(filepath.Join(t.TempDir(), "todos.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// sampleList builds a list that exercises every queried field
+func sampleList(t *testing.T) *todo.List {
+	t.Helper()
+	list := todo.NewList()
+	for _, text := range []string{"Write report", "Buy milk", "Fix login bug", "Call 100% of clients"} {
+		if err := list.Add(text); err != nil {
+			t.Fatal(err)
+		}
+	}
+	past := time.Now().Add(-48 * time.Hour)
+	future := time.Now().Add(48 * time.Hour)
+	steps := []error{
+		list.SetPriority(0, todo.PriorityHigh),
+		list.SetDueDate(0, past),
+		list.AddTag(0, "work"),
+		list.SetEstimate(0, todo.Estimate{Duration: 2 * time.Hour}),
+		list.AddTag(1, "home"),
+		list.SetDueDate(1, future),
+		list.SetPriority(2, todo.PriorityHigh),
+		list.AddTag(2, "work"),
+		list.SetDueDate(2, past),
+		list.SetEstimate(3, todo.Estimate{Points: 3}),
+		list.Complete(2),
+	}
+	for _, err := range steps {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return list
+}
+
+func ids(items []todo.Item) string {
+	var out []string
+	for _, item := range items {
+		out = append(out, item.ID)
+	}
+	return strings.Join(out, ",")
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	s := openStore(t)
+	list := sampleList(t)
+	if err := s.Save(list); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids(loaded.Items) != ids(list.Items) {
+		t.Fatalf("Expected order %s, got %s", ids(list.Items), ids(loaded.Items))
+	}
+	first := loaded.Items[0]
+	if first.Priority != todo.PriorityHigh || first.DueDate == nil || len(first.Tags) != 1 ||
+		first.Estimate == nil || first.Estimate.Duration != 2*time.Hour {
+		t.Errorf("Fields were not preserved: %+v", first)
+	}
+
+	// Deleting and editing are saved, and deleted items lose their tags
+	if err := loaded.Delete(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.Edit(0, "Buy oat milk"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save(loaded); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reloaded.Items) != 3 || reloaded.Items[0].Text != "Buy oat milk" {
+		t.Errorf("Unexpected items: %+v", reloaded.Items)
+	}
+	var tags int
+	if err := s.db.QueryRow(`SELECT count(*) FROM tags WHERE item_id = ?`, first.ID).Scan(&tags); err != nil || tags != 0 {
+		t.Errorf("Expected the deleted item's tags to go, got %d (%v)", tags, err)
+	}
+}
+
+func TestQueriesMatchList(t *testing.T) {
+	s := openStore(t)
+	list := sampleList(t)
+	if err := s.Save(list); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(name string, got []todo.Item, err error, expected []todo.Item) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if ids(got) != ids(expected) {
+			t.Errorf("%s: expected %s, got %s", name, ids(expected), ids(got))
+		}
+	}
+
+	for _, query := range []string{"milk", "WORK", "bug", "100%", "_", "none"} {
+		got, err := s.Search(query)
+		check("Search "+query, got, err, list.Search(query))
+	}
+	got, err := s.FilterByPriority(todo.PriorityHigh)
+	check("FilterByPriority", got, err, list.FilterByPriority(todo.PriorityHigh))
+	got, err = s.FilterByTag("work")
+	check("FilterByTag", got, err, list.FilterByTag("work"))
+	got, err = s.Overdue(time.Now())
+	check("Overdue", got, err, list.GetOverdue())
+
+	stats, err := s.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats != list.GetStats() {
+		t.Errorf("Expected stats %+v, got %+v", list.GetStats(), stats)
+	}
+}
+
+func TestApplyMatchesList(t *testing.T) {
+	s := openStore(t)
+	list := sampleList(t)
+	if err := s.Save(list); err != nil {
+		t.Fatal(err)
+	}
+
+	// Changes made to the list, applied to the store, give the same list
+	var changes []todo.Event
+	list.Subscribe(func(e todo.Event) { changes = append(changes, e) })
+	steps := []error{
+		list.Add("Plan sprint"),
+		list.Complete(0),
+		list.AddTag(1, "urgent"),
+		list.Uncomplete(len(list.Items) - 1),
+		list.Delete(2),
+	}
+	for _, err := range steps {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Apply(changes); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids(loaded.Items) != ids(list.Items) {
+		t.Errorf("Expected order %s, got %s", ids(list.Items), ids(loaded.Items))
+	}
+	for i, item := range list.Items {
+		if !todo.SameItem(item, loaded.Items[i]) {
+			t.Errorf("Item %d: expected %+v, got %+v", i, item, loaded.Items[i])
+		}
+	}
+	if tagged, _ := s.FilterByTag("urgent"); len(tagged) != 1 {
+		t.Errorf("Expected the new tag to be indexed, got %+v", tagged)
+	}
+
+	item, err := s.ItemAt(1)
+	if err != nil || item.ID != list.Items[1].ID {
+		t.Errorf("ItemAt(1) = %+v, %v", item, err)
+	}
+	if _, err := s.ItemAt(len(list.Items)); err == nil {
+		t.Error("Expected error for an index past the end")
+	}
+}
+
+func TestApplyRejectsWholeChange(t *testing.T) {
+	s := openStore(t)
+	list := sampleList(t)
+	if err := s.Save(list); err != nil {
+		t.Fatal(err)
+	}
+
+	added := todo.NewItem("Plan sprint")
+	duplicate := todo.NewItem(list.Items[0].Text)
+	missing := todo.NewItem("Not stored")
+	tests := [][]todo.Event{
+		{{Type: todo.EventAdded, Item: added}, {Type: todo.EventAdded, Item: duplicate}},
+		{{Type: todo.EventAdded, Item: added}, {Type: todo.EventEdited, Item: missing}},
+		{{Type: todo.EventAdded, Item: todo.NewItem("")}},
+	}
+	for _, changes := range tests {
+		if err := s.Apply(changes); err == nil {
+			t.Errorf("Expected an error applying %+v", changes)
+		}
+	}
+	if found, _ := s.Search("Plan sprint"); len(found) != 0 {
+		t.Errorf("Expected nothing saved from rejected changes, got %+v", found)
+	}
+}
+
+func TestQueriesUseIndexes(t *testing.T) {
+	s := openStore(t)
+	cases := map[string]string{
+		`SELECT data FROM items WHERE priority = 2 ORDER BY position`:                      "items_priority",
+		`SELECT data FROM items WHERE due < 0 AND done = 0 ORDER BY position`:              "items_due",
+		`SELECT data FROM items WHERE id IN (SELECT item_id FROM tags WHERE tag = 'work')`: "tags_tag",
+	}
+	for query, index := range cases {
+		rows, err := s.db.Query("EXPLAIN QUERY PLAN " + query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var plan []string
+		for rows.Next() {
+			var id, parent, unused int
+			var detail string
+			if err := rows.Scan(&id, &parent, &unused, &detail); err != nil {
+				t.Fatal(err)
+			}
+			plan = append(plan, detail)
+		}
+		rows.Close()
+		if !strings.Contains(strings.Join(plan, "\n"), index) {
+			t.Errorf("Expected %q to use %s, plan:\n%s", query, index, strings.Join(plan, "\n"))
+		}
+	}
+}
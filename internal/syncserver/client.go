@@ -0,0 +1,182 @@
+package syncserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+// Client is a device's side of the sync, kept in a JSON file between syncs
+type Client struct {
+	path string
+
+	Server string `json:"server"`
+	Token  string `json:"token"`
+	// Device is the name the server knows the device by
+	Device string `json:"device,omitempty"`
+	Clock  uint64 `json:"clock"`
+	Vector Vector `json:"vector"`
+	// Base holds the items as of the last sync, to find local changes
+	Base map[string]todo.Item `json:"base"`
+
+	HTTP *http.Client `json:"-"`
+}
+
+// Result describes what a Sync did
+type Result struct {
+	// Pushed is the number of local changes sent
+	Pushed int
+	// Pulled is the number of items added or changed by other devices
+	Pulled int
+	// Deleted is the number of items deleted by other devices
+	Deleted int
+}
+
+// LoadClient reads the client state from path. A missing file yields a
+// client that has never synced.
+func LoadClient(path string) (*Client, error) {
+	c := &Client{path: path}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, c); err != nil {
+			return nil, fmt.Errorf("Invalid sync state %s: %w", path, err)
+		}
+	}
+	if c.Vector == nil {
+		c.Vector = make(Vector)
+	}
+	if c.Base == nil {
+		c.Base = make(map[string]todo.Item)
+	}
+	return c, nil
+}
+
+// Save writes the client state back; it holds the token, so only the owner
+// can read it
+func (c *Client) Save() error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0600)
+}
+
+// tick returns the clock for a local change
+func (c *Client) tick(now time.Time) uint64 {
+	c.Clock++
+	if ms := uint64(now.UnixMilli()); ms > c.Clock {
+		c.Clock = ms
+	}
+	return c.Clock
+}
+
+// changes returns the changes made to list since the last sync
+func (c *Client) changes(list *todo.List, now time.Time) []Change {
+	var changes []Change
+	current := make(map[string]bool, len(list.Items))
+	for _, item := range list.Items {
+		current[item.ID] = true
+		if base, ok := c.Base[item.ID]; ok && todo.SameItem(base, item) {
+			continue
+		}
+		item := item
+		changes = append(changes, Change{ID: item.ID, Clock: c.tick(now), Item: &item})
+	}
+	for id := range c.Base {
+		if !current[id] {
+			changes = append(changes, Change{ID: id, Clock: c.tick(now), Deleted: true})
+		}
+	}
+	return changes
+}
+
+// Sync sends the changes made to list since the last sync and applies the
+// changes other devices made. Where two devices changed the same item the
+// later change wins, a deletion included. The caller saves list and then c.
+func (c *Client) Sync(list *todo.List, now time.Time) (Result, error) {
+	var result Result
+	if c.Server == "" || c.Token == "" {
+		return result, errors.New("No sync server configured")
+	}
+	changes := c.changes(list, now)
+	resp, err := c.post(Request{Vector: c.Vector, Changes: changes})
+	if err != nil {
+		return result, err
+	}
+	result.Pushed = len(changes)
+
+	for _, change := range resp.Changes {
+		if change.Clock > c.Clock {
+			c.Clock = change.Clock
+		}
+		i := list.IndexOf(change.ID)
+		switch {
+		case change.Deleted:
+			if i >= 0 {
+				list.Items = append(list.Items[:i], list.Items[i+1:]...)
+				result.Deleted++
+			}
+		case change.Item == nil:
+			return result, fmt.Errorf("Invalid change to item %q from server", change.ID)
+		case i >= 0:
+			list.Items[i] = *change.Item
+			result.Pulled++
+		default:
+			list.Items = append(list.Items, *change.Item)
+			result.Pulled++
+		}
+	}
+	if result.Pulled > 0 || result.Deleted > 0 {
+		list.Sort()
+	}
+
+	c.Device, c.Vector = resp.Device, resp.Vector
+	c.Base = make(map[string]todo.Item, len(list.Items))
+	for _, item := range list.Items {
+		c.Base[item.ID] = item
+	}
+	return result, nil
+}
+
+func (c *Client) post(req Request) (Response, error) {
+	var resp Response
+	body, err := json.Marshal(req)
+	if err != nil {
+		return resp, err
+	}
+	httpReq, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(c.Server, "/")+Path, bytes.NewReader(body))
+	if err != nil {
+		return resp, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+c.Token)
+
+	client := c.HTTP
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		return resp, err
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(httpResp.Body, 200))
+		return resp, fmt.Errorf("Sync server returned %s: %s", httpResp.Status, bytes.TrimSpace(msg))
+	}
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return resp, fmt.Errorf("Invalid response from sync server: %w", err)
+	}
+	return resp, nil
+}
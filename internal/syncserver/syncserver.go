@@ -0,0 +1,288 @@
+// Package syncserver syncs a todo list between devices through a central
+// server that exchanges deltas rather than whole files. The server keeps the
+// latest change to every item, deletions included as tombstones, numbered per
+// device; a device sends the changes it made since its last sync along with a
+// version vector of the changes it has seen, and gets back only the ones it is
+// missing. Each device authenticates with its own token.
+package syncserver
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+// Path is where the server handles sync requests
+const Path = "/v1/sync"
+
+const maxRequestSize = 16 << 20
+
+// Vector counts the changes seen from each device
+type Vector map[string]uint64
+
+// covers reports whether the change is already counted in v
+func (v Vector) covers(c Change) bool {
+	return c.Seq <= v[c.Device]
+}
+
+// Change is a version of one item. A deleted item is kept as a tombstone so
+// devices that sync later learn about the deletion.
+type Change struct {
+	ID     string `json:"id"`
+	Device string `json:"device"`
+	// Seq numbers the device's changes; the server assigns it
+	Seq uint64 `json:"seq"`
+	// Clock is the device's hybrid logical clock when it made the change;
+	// the change with the later clock wins
+	Clock   uint64     `json:"clock"`
+	Deleted bool       `json:"deleted,omitempty"`
+	Item    *todo.Item `json:"item,omitempty"`
+}
+
+// wins reports whether c supersedes o
+func (c Change) wins(o Change) bool {
+	if c.Clock != o.Clock {
+		return c.Clock > o.Clock
+	}
+	return c.Device > o.Device
+}
+
+// Request is what a device sends to sync
+type Request struct {
+	// Vector is what the device has seen so far
+	Vector  Vector   `json:"vector"`
+	Changes []Change `json:"changes"`
+}
+
+// Response is what the server sends back
+type Response struct {
+	// Device is the name the token belongs to
+	Device string `json:"device"`
+	// Vector is what the device has seen once it applies Changes
+	Vector  Vector   `json:"vector"`
+	Changes []Change `json:"changes"`
+}
+
+// Server stores the changes of all devices in a JSON file
+type Server struct {
+	path string
+	mu   sync.Mutex
+
+	// Devices maps a device name to the SHA-256 hash of its token
+	Devices map[string]string `json:"devices"`
+	Vector  Vector            `json:"vector"`
+	// Changes holds the latest change to each item
+	Changes map[string]Change `json:"changes"`
+}
+
+// Open reads the server state from path. A missing file yields an empty server.
+func Open(path string) (*Server, error) {
+	s := &Server{path: path}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Server) load() error {
+	s.Devices, s.Vector, s.Changes = nil, nil, nil
+	data, err := os.ReadFile(s.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, s); err != nil {
+			return fmt.Errorf("Invalid sync server state %s: %w", s.path, err)
+		}
+	}
+	if s.Devices == nil {
+		s.Devices = make(map[string]string)
+	}
+	if s.Vector == nil {
+		s.Vector = make(Vector)
+	}
+	if s.Changes == nil {
+		s.Changes = make(map[string]Change)
+	}
+	return nil
+}
+
+func (s *Server) save() error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// AddDevice registers a device and returns its token
+func (s *Server) AddDevice(name string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return "", err
+	}
+	if name == "" || strings.ContainsAny(name, " \t\n") {
+		return "", fmt.Errorf("Invalid device name %q", name)
+	}
+	if _, ok := s.Devices[name]; ok {
+		return "", fmt.Errorf("Device %s already exists", name)
+	}
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(b)
+	s.Devices[name] = hashToken(token)
+	return token, s.save()
+}
+
+// RemoveDevice revokes a device's token. Changes it made stay.
+func (s *Server) RemoveDevice(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	if _, ok := s.Devices[name]; !ok {
+		return fmt.Errorf("No device named %s", name)
+	}
+	delete(s.Devices, name)
+	return s.save()
+}
+
+// DeviceNames returns the registered devices, sorted
+func (s *Server) DeviceNames() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.Devices))
+	for name := range s.Devices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// device returns the device the token belongs to
+func (s *Server) device(token string) (string, bool) {
+	hash := hashToken(token)
+	for name, h := range s.Devices {
+		if subtle.ConstantTimeCompare([]byte(h), []byte(hash)) == 1 {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != Path {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "Use POST to sync", http.StatusMethodNotAllowed)
+		return
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		http.Error(w, "Missing device token", http.StatusUnauthorized)
+		return
+	}
+	var req Request
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+		http.Error(w, "Invalid sync request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp, err := s.sync(token, req)
+	var reqErr *requestError
+	switch {
+	case errors.Is(err, errUnauthorized):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	case errors.As(err, &reqErr):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+var errUnauthorized = errors.New("Unknown device token")
+
+type requestError struct {
+	msg string
+}
+
+func (e *requestError) Error() string {
+	return e.msg
+}
+
+// sync stores the device's changes and returns the ones it hasn't seen
+func (s *Server) sync(token string, req Request) (Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Reload so devices added or removed from the command line take effect
+	if err := s.load(); err != nil {
+		return Response{}, err
+	}
+	device, ok := s.device(token)
+	if !ok {
+		return Response{}, errUnauthorized
+	}
+
+	pushed := make(map[string]bool, len(req.Changes))
+	for _, c := range req.Changes {
+		if c.ID == "" || (!c.Deleted && (c.Item == nil || c.Item.ID != c.ID)) {
+			return Response{}, &requestError{fmt.Sprintf("Invalid change to item %q", c.ID)}
+		}
+		if c.Deleted {
+			c.Item = nil
+		}
+		s.Vector[device]++
+		c.Device, c.Seq = device, s.Vector[device]
+		if old, ok := s.Changes[c.ID]; !ok || c.wins(old) {
+			s.Changes[c.ID] = c
+			pushed[c.ID] = true
+		}
+	}
+	if len(req.Changes) > 0 {
+		if err := s.save(); err != nil {
+			return Response{}, err
+		}
+	}
+
+	resp := Response{Device: device, Vector: s.Vector, Changes: []Change{}}
+	for id, c := range s.Changes {
+		if !pushed[id] && !req.Vector.covers(c) {
+			resp.Changes = append(resp.Changes, c)
+		}
+	}
+	sort.Slice(resp.Changes, func(i, j int) bool {
+		return resp.Changes[j].wins(resp.Changes[i])
+	})
+	return resp, nil
+}
@@ -0,0 +1,209 @@
+package syncserver
+
+import (
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+var now = time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+
+// device is a client with its own list
+type device struct {
+	client *Client
+	list   *todo.List
+}
+
+// newDevices starts a server and returns two devices registered with it
+func newDevices(t *testing.T) (*Server, *device, *device) {
+	t.Helper()
+	dir := t.TempDir()
+	server, err := Open(filepath.Join(dir, "server.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+
+	devices := make([]*device, 2)
+	for i, name := range []string{"laptop", "phone"} {
+		token, err := server.AddDevice(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client, err := LoadClient(filepath.Join(dir, name+".json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.Server, client.Token, client.HTTP = ts.URL, token, ts.Client()
+		devices[i] = &device{client: client, list: todo.NewList()}
+	}
+	return server, devices[0], devices[1]
+}
+
+func mustSync(t *testing.T, d *device, at time.Time) Result {
+	t.Helper()
+	result, err := d.client.Sync(d.list, at)
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	return result
+}
+
+func mustAdd(t *testing.T, list *todo.List, texts ...string) {
+	t.Helper()
+	for _, text := range texts {
+		if err := list.Add(text); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func itemTexts(list *todo.List) string {
+	var texts []string
+	for _, item := range list.Items {
+		texts = append(texts, item.Text)
+	}
+	return strings.Join(texts, ", ")
+}
+
+func TestSyncDeltas(t *testing.T) {
+	_, laptop, phone := newDevices(t)
+
+	mustAdd(t, laptop.list, "Task 1", "Task 2")
+	if result := mustSync(t, laptop, now); result.Pushed != 2 || result.Pulled != 0 {
+		t.Errorf("Expected 2 changes pushed, got %+v", result)
+	}
+	if result := mustSync(t, phone, now); result.Pulled != 2 {
+		t.Errorf("Expected 2 changes pulled, got %+v", result)
+	}
+	if itemTexts(phone.list) != "Task 1, Task 2" {
+		t.Errorf("Expected the phone to have both tasks, got %s", itemTexts(phone.list))
+	}
+
+	// Nothing changed, so nothing is sent either way
+	if result := mustSync(t, phone, now); result != (Result{}) {
+		t.Errorf("Expected an empty delta, got %+v", result)
+	}
+
+	// Only the changed item travels
+	if err := phone.list.Complete(0); err != nil {
+		t.Fatal(err)
+	}
+	if result := mustSync(t, phone, now.Add(time.Second)); result.Pushed != 1 {
+		t.Errorf("Expected 1 change pushed, got %+v", result)
+	}
+	if result := mustSync(t, laptop, now.Add(2*time.Second)); result.Pulled != 1 || result.Pushed != 0 {
+		t.Errorf("Expected 1 change pulled, got %+v", result)
+	}
+	if !laptop.list.Items[1].Done {
+		t.Errorf("Expected Task 1 completed on the laptop, got %+v", laptop.list.Items)
+	}
+}
+
+func TestSyncDeletionsAndConflicts(t *testing.T) {
+	_, laptop, phone := newDevices(t)
+	mustAdd(t, laptop.list, "Old task", "Draft", "Keep")
+	mustSync(t, laptop, now)
+	mustSync(t, phone, now)
+
+	// The laptop deletes one task and edits another; later the phone edits
+	// the same task and a third one
+	if err := laptop.list.Delete(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := laptop.list.Edit(0, "Draft from laptop"); err != nil {
+		t.Fatal(err)
+	}
+	if err := phone.list.Edit(1, "Draft from phone"); err != nil {
+		t.Fatal(err)
+	}
+	if err := phone.list.Edit(2, "Keep (edited)"); err != nil {
+		t.Fatal(err)
+	}
+	mustSync(t, laptop, now.Add(time.Second))
+	result := mustSync(t, phone, now.Add(2*time.Second))
+	if result.Deleted != 1 {
+		t.Errorf("Expected the deletion to reach the phone, got %+v", result)
+	}
+	mustSync(t, laptop, now.Add(3*time.Second))
+
+	for name, d := range map[string]*device{"laptop": laptop, "phone": phone} {
+		if got := itemTexts(d.list); got != "Draft from phone, Keep (edited)" {
+			t.Errorf("%s: expected the later edit to win and the deletion to stick, got %s", name, got)
+		}
+	}
+}
+
+func TestSyncKeepsTombstones(t *testing.T) {
+	server, laptop, phone := newDevices(t)
+	mustAdd(t, laptop.list, "Short lived", "Stays")
+	mustSync(t, laptop, now)
+	if err := laptop.list.Delete(0); err != nil {
+		t.Fatal(err)
+	}
+	mustSync(t, laptop, now.Add(time.Second))
+
+	var tombstones int
+	for _, c := range server.Changes {
+		if c.Deleted {
+			tombstones++
+		}
+	}
+	if tombstones != 1 {
+		t.Errorf("Expected 1 tombstone on the server, got %d", tombstones)
+	}
+
+	// A device syncing for the first time never sees the deleted task
+	if result := mustSync(t, phone, now.Add(2*time.Second)); result.Pulled != 1 || itemTexts(phone.list) != "Stays" {
+		t.Errorf("Expected only the remaining task, got %+v, %s", result, itemTexts(phone.list))
+	}
+}
+
+func TestSyncRejectsBadTokens(t *testing.T) {
+	server, laptop, phone := newDevices(t)
+
+	laptop.client.Token = "not-a-token"
+	if _, err := laptop.client.Sync(laptop.list, now); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Expected an unknown token to be refused, got %v", err)
+	}
+
+	if err := server.RemoveDevice("phone"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := phone.client.Sync(phone.list, now); err == nil {
+		t.Error("Expected a removed device to be refused")
+	}
+}
+
+func TestStatePersists(t *testing.T) {
+	server, laptop, _ := newDevices(t)
+	mustAdd(t, laptop.list, "Task")
+	mustSync(t, laptop, now)
+	if err := laptop.client.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := Open(server.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Vector["laptop"] != 1 || len(reopened.Changes) != 1 {
+		t.Errorf("Expected the server state to survive a restart, got %+v", reopened)
+	}
+	if names := reopened.DeviceNames(); len(names) != 2 || names[0] != "laptop" || names[1] != "phone" {
+		t.Errorf("Unexpected devices %v", names)
+	}
+
+	client, err := LoadClient(laptop.client.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.Device != "laptop" || client.Vector["laptop"] != 1 || len(client.Base) != 1 {
+		t.Errorf("Expected the client state to survive a reload, got %+v", client)
+	}
+}
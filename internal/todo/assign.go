@@ -0,0 +1,77 @@
+package todo
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// UnassignedLabel groups items without an assignee in StatsByAssignee output
+const UnassignedLabel = "(unassigned)"
+
+// SetAssignee assigns a task to user; an empty user unassigns it
+func (l *List) SetAssignee(index int, user string) error {
+	if index < 0 || index >= len(l.Items) {
+		return errors.New("Item index out of Range")
+	}
+	user = strings.TrimSpace(user)
+	if strings.ContainsAny(user, ",\n") {
+		return errors.New("User names cannot contain commas or newlines")
+	}
+	previous := l.Items[index].clone()
+	l.Items[index].Assignee = user
+	l.emit(EventAssigned, l.Items[index], previous)
+	return nil
+}
+
+// FilterByAssignee returns items assigned to user; an empty user returns
+// unassigned items
+func (l *List) FilterByAssignee(user string) []Item {
+	return AssignedTo(l.Items, user)
+}
+
+// AssignedTo narrows items, e.g. search results, to those assigned to user
+func AssignedTo(items []Item, user string) []Item {
+	var results []Item
+	for _, item := range items {
+		if strings.EqualFold(item.Assignee, user) {
+			results = append(results, item)
+		}
+	}
+	return results
+}
+
+// UserStats are the statistics of the tasks assigned to one user
+type UserStats struct {
+	// User is the assignee, or UnassignedLabel
+	User string
+	Stats
+}
+
+// StatsByAssignee returns statistics per assignee, sorted by name, with
+// unassigned tasks last
+func (l *List) StatsByAssignee() []UserStats {
+	byUser := make(map[string]*List)
+	for _, item := range l.Items {
+		user := item.Assignee
+		if user == "" {
+			user = UnassignedLabel
+		}
+		if byUser[user] == nil {
+			byUser[user] = NewList()
+		}
+		byUser[user].Items = append(byUser[user].Items, item)
+	}
+
+	stats := make([]UserStats, 0, len(byUser))
+	for user, list := range byUser {
+		stats = append(stats, UserStats{User: user, Stats: list.GetStats()})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if (stats[i].User == UnassignedLabel) != (stats[j].User == UnassignedLabel) {
+			return stats[j].User == UnassignedLabel
+		}
+		return stats[i].User < stats[j].User
+	})
+	return stats
+}
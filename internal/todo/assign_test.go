@@ -0,0 +1,121 @@
+package todo
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustAssign(t *testing.T, list *List, index int, user string) {
+	t.Helper()
+	if err := list.SetAssignee(index, user); err != nil {
+		t.Fatalf("Failed to assign item %d: %v", index, err)
+	}
+}
+
+func TestSetAssignee(t *testing.T) {
+	list := NewList()
+	mustAdd(t, list, "Task")
+
+	var events []Event
+	list.Subscribe(func(e Event) { events = append(events, e) })
+
+	mustAssign(t, list, 0, " alice ")
+	if list.Items[0].Assignee != "alice" {
+		t.Errorf("Expected assignee alice, got %q", list.Items[0].Assignee)
+	}
+	mustAssign(t, list, 0, "")
+	if list.Items[0].Assignee != "" {
+		t.Errorf("Expected the task to be unassigned, got %q", list.Items[0].Assignee)
+	}
+	if len(events) != 2 || events[0].Type != EventAssigned || events[1].Previous.Assignee != "alice" {
+		t.Errorf("Unexpected events: %+v", events)
+	}
+
+	if err := list.SetAssignee(5, "bob"); err == nil {
+		t.Error("Expected error for invalid index")
+	}
+	if err := list.SetAssignee(0, "bob, carol"); err == nil {
+		t.Error("Expected error for a name with a comma")
+	}
+}
+
+func TestCreator(t *testing.T) {
+	list := NewList()
+	list.User = "alice"
+	mustAdd(t, list, "Task")
+	if list.Items[0].Creator != "alice" {
+		t.Errorf("Expected creator alice, got %q", list.Items[0].Creator)
+	}
+
+	imported := NewItem("Imported")
+	imported.Creator = "bob"
+	if err := list.AddItem(imported); err != nil {
+		t.Fatal(err)
+	}
+	if list.Items[1].Creator != "bob" {
+		t.Errorf("Expected an imported creator to be kept, got %q", list.Items[1].Creator)
+	}
+}
+
+func TestFilterByAssignee(t *testing.T) {
+	list := NewList()
+	mustAdd(t, list, "Task 1")
+	mustAdd(t, list, "Task 2")
+	mustAdd(t, list, "Task 3")
+	mustAssign(t, list, 0, "alice")
+	mustAssign(t, list, 2, "Alice")
+
+	if results := list.FilterByAssignee("alice"); len(results) != 2 {
+		t.Errorf("Expected 2 tasks for alice, got %d", len(results))
+	}
+	if results := list.FilterByAssignee(""); len(results) != 1 || results[0].Text != "Task 2" {
+		t.Errorf("Expected Task 2 to be unassigned, got %+v", results)
+	}
+	if results := AssignedTo(list.Search("task 3"), "alice"); len(results) != 1 {
+		t.Errorf("Expected to narrow search results, got %+v", results)
+	}
+}
+
+func TestStatsByAssignee(t *testing.T) {
+	list := NewList()
+	mustAdd(t, list, "Task 1")
+	mustAdd(t, list, "Task 2")
+	mustAdd(t, list, "Task 3")
+	mustAdd(t, list, "Task 4")
+	mustAssign(t, list, 0, "bob")
+	mustAssign(t, list, 1, "alice")
+	mustAssign(t, list, 2, "bob")
+	if err := list.SetEstimate(2, Estimate{Duration: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	mustComplete(t, list, 0)
+
+	stats := list.StatsByAssignee()
+	if len(stats) != 3 {
+		t.Fatalf("Expected 3 groups, got %+v", stats)
+	}
+	expected := []UserStats{
+		{"alice", Stats{Total: 1, Pending: 1}},
+		{"bob", Stats{Total: 2, Pending: 1, Completed: 1, EstimatedTime: time.Hour}},
+		{UnassignedLabel, Stats{Total: 1, Pending: 1}},
+	}
+	for i, want := range expected {
+		if stats[i] != want {
+			t.Errorf("Expected %+v, got %+v", want, stats[i])
+		}
+	}
+}
+
+func TestFilteredString(t *testing.T) {
+	list := NewList()
+	mustAdd(t, list, "Mine")
+	mustAdd(t, list, "Theirs")
+	mustAssign(t, list, 0, "alice")
+	mustAssign(t, list, 1, "bob")
+
+	result := list.Filtered(func(item Item) bool { return item.Assignee == "bob" })
+	if strings.Contains(result, "Mine") || !strings.Contains(result, "2. [ ] 🟡 Theirs 👤 bob") {
+		t.Errorf("Expected only bob's task with its number in the list, got %q", result)
+	}
+}
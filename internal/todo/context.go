@@ -0,0 +1,40 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ContextName normalizes a context name such as "@Office" to "office".
+// Names are single words so they read well after an @.
+func ContextName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "@"))
+	if name == "" {
+		return "", errors.New("Missing context name")
+	}
+	if strings.ContainsAny(name, " \t\n,@") {
+		return "", fmt.Errorf("Invalid context name %q: use a single word", name)
+	}
+	return name, nil
+}
+
+// SetContext sets the context a task is done in, such as office or phone;
+// an empty name clears it
+func (l *List) SetContext(index int, name string) error {
+	if index < 0 || index >= len(l.Items) {
+		return errors.New("Item index out of Range")
+	}
+	if strings.TrimSpace(name) != "" {
+		var err error
+		if name, err = ContextName(name); err != nil {
+			return err
+		}
+	} else {
+		name = ""
+	}
+	previous := l.Items[index].clone()
+	l.Items[index].Context = name
+	l.emit(EventContextChanged, l.Items[index], previous)
+	return nil
+}
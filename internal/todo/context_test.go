@@ -0,0 +1,55 @@
+package todo
+
+import "testing"
+
+func TestContextName(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		valid    bool
+	}{
+		{"office", "office", true},
+		{" @Office ", "office", true},
+		{"", "", false},
+		{"@", "", false},
+		{"the office", "", false},
+		{"home,office", "", false},
+	}
+	for _, tt := range tests {
+		got, err := ContextName(tt.input)
+		if (err == nil) != tt.valid || got != tt.expected {
+			t.Errorf("ContextName(%q) = %q, %v", tt.input, got, err)
+		}
+	}
+}
+
+func TestSetContext(t *testing.T) {
+	list := NewList()
+	mustAdd(t, list, "Print slides")
+
+	var events []Event
+	list.Subscribe(func(e Event) { events = append(events, e) })
+
+	if err := list.SetContext(0, "@Office"); err != nil {
+		t.Fatal(err)
+	}
+	if list.Items[0].Context != "office" {
+		t.Errorf("Expected context office, got %q", list.Items[0].Context)
+	}
+	if err := list.SetContext(0, ""); err != nil {
+		t.Fatal(err)
+	}
+	if list.Items[0].Context != "" {
+		t.Errorf("Expected the context to be cleared, got %q", list.Items[0].Context)
+	}
+	if len(events) != 2 || events[0].Type != EventContextChanged || events[1].Previous.Context != "office" {
+		t.Errorf("Unexpected events: %+v", events)
+	}
+
+	if err := list.SetContext(3, "home"); err == nil {
+		t.Error("Expected error for invalid index")
+	}
+	if err := list.SetContext(0, "at home"); err == nil {
+		t.Error("Expected error for a name with a space")
+	}
+}
@@ -0,0 +1,180 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Estimate is the expected effort for a task, either as a duration or in story points
+type Estimate struct {
+	Duration time.Duration `json:"Duration,omitempty"`
+	Points   float64       `json:"Points,omitempty"`
+}
+
+// ParseEstimate parses an estimate such as "30m", "2h", "1h30m" or "3pt"
+func ParseEstimate(s string) (Estimate, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return Estimate{}, errors.New("Estimate cannot be empty")
+	}
+
+	for _, suffix := range []string{"pts", "pt", "sp", "p"} {
+		if strings.HasSuffix(s, suffix) {
+			points, err := strconv.ParseFloat(strings.TrimSuffix(s, suffix), 64)
+			if err != nil || points <= 0 {
+				return Estimate{}, fmt.Errorf("Invalid story points: %s", s)
+			}
+			return Estimate{Points: points}, nil
+		}
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return Estimate{}, fmt.Errorf("Invalid estimate: %s (use e.g. 30m, 2h or 3pt)", s)
+	}
+	return Estimate{Duration: d}, nil
+}
+
+// IsZero reports whether no effort has been estimated
+func (e Estimate) IsZero() bool {
+	return e.Duration == 0 && e.Points == 0
+}
+
+func (e Estimate) String() string {
+	if e.Points > 0 {
+		return strconv.FormatFloat(e.Points, 'f', -1, 64) + "pt"
+	}
+	return FormatDuration(e.Duration)
+}
+
+// FormatDuration renders a duration compactly, e.g. "2h", "1h30m" or "45m"
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	h := int(d / time.Hour)
+	m := int((d % time.Hour) / time.Minute)
+	switch {
+	case h > 0 && m > 0:
+		return fmt.Sprintf("%dh%dm", h, m)
+	case h > 0:
+		return fmt.Sprintf("%dh", h)
+	default:
+		return fmt.Sprintf("%dm", m)
+	}
+}
+
+// SetEstimate sets the estimated effort of a task
+func (l *List) SetEstimate(index int, estimate Estimate) error {
+	if index < 0 || index >= len(l.Items) {
+		return errors.New("Item index out of Range")
+	}
+	previous := l.Items[index].clone()
+	if estimate.IsZero() {
+		l.Items[index].Estimate = nil
+	} else {
+		l.Items[index].Estimate = &estimate
+	}
+	l.emit(EventEstimated, l.Items[index], previous)
+	return nil
+}
+
+// LogTime adds time actually spent on a task
+func (l *List) LogTime(index int, spent time.Duration) error {
+	if index < 0 || index >= len(l.Items) {
+		return errors.New("Item index out of Range")
+	}
+	if spent <= 0 {
+		return errors.New("Tracked time must be positive")
+	}
+	previous := l.Items[index].clone()
+	l.Items[index].Tracked += spent
+	l.emit(EventTimeLogged, l.Items[index], previous)
+	return nil
+}
+
+// UntaggedLabel groups items without tags in an EstimateReport
+const UntaggedLabel = "(untagged)"
+
+// TagEstimate compares estimated and tracked effort for one tag
+type TagEstimate struct {
+	Tag   string
+	Items int
+
+	// Tracked is all time logged on the tag's items
+	Tracked time.Duration
+
+	// Items estimated as a duration, and the time logged on them
+	EstimatedTime time.Duration
+	TrackedOnTime time.Duration
+
+	// Items estimated in story points, and the time logged on them
+	EstimatedPoints float64
+	TrackedOnPoints time.Duration
+}
+
+// Accuracy returns tracked time divided by estimated time for items estimated as a
+// duration, or 0 when there are none. Values above 1 mean the estimates were too low.
+func (t TagEstimate) Accuracy() float64 {
+	if t.EstimatedTime == 0 {
+		return 0
+	}
+	return float64(t.TrackedOnTime) / float64(t.EstimatedTime)
+}
+
+// HoursPerPoint returns tracked hours per estimated story point, or 0 when no points were estimated
+func (t TagEstimate) HoursPerPoint() float64 {
+	if t.EstimatedPoints == 0 {
+		return 0
+	}
+	return t.TrackedOnPoints.Hours() / t.EstimatedPoints
+}
+
+// EstimateReport groups items by tag and compares their estimates with tracked time.
+// Items with several tags count towards each of them; items with neither an estimate
+// nor tracked time are skipped.
+func (l *List) EstimateReport() []TagEstimate {
+	byTag := make(map[string]*TagEstimate)
+
+	for _, item := range l.Items {
+		if item.Estimate == nil && item.Tracked == 0 {
+			continue
+		}
+
+		tags := item.Tags
+		if len(tags) == 0 {
+			tags = []string{UntaggedLabel}
+		}
+
+		for _, tag := range tags {
+			row, ok := byTag[tag]
+			if !ok {
+				row = &TagEstimate{Tag: tag}
+				byTag[tag] = row
+			}
+			row.Items++
+			row.Tracked += item.Tracked
+			if item.Estimate == nil {
+				continue
+			}
+			if item.Estimate.Points > 0 {
+				row.EstimatedPoints += item.Estimate.Points
+				row.TrackedOnPoints += item.Tracked
+			} else {
+				row.EstimatedTime += item.Estimate.Duration
+				row.TrackedOnTime += item.Tracked
+			}
+		}
+	}
+
+	report := make([]TagEstimate, 0, len(byTag))
+	for _, row := range byTag {
+		report = append(report, *row)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		return report[i].Tag < report[j].Tag
+	})
+	return report
+}
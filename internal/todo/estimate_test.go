@@ -0,0 +1,202 @@
+package todo
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseEstimate(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Estimate
+	}{
+		{"30m", Estimate{Duration: 30 * time.Minute}},
+		{"2h", Estimate{Duration: 2 * time.Hour}},
+		{"1h30m", Estimate{Duration: 90 * time.Minute}},
+		{"3pt", Estimate{Points: 3}},
+		{"5SP", Estimate{Points: 5}},
+		{"0.5p", Estimate{Points: 0.5}},
+	}
+
+	for _, tt := range tests {
+		result, err := ParseEstimate(tt.input)
+		if err != nil {
+			t.Errorf("ParseEstimate(%s) returned error: %v", tt.input, err)
+			continue
+		}
+		if result != tt.expected {
+			t.Errorf("ParseEstimate(%s) = %+v, expected %+v", tt.input, result, tt.expected)
+		}
+	}
+
+	for _, input := range []string{"", "soon", "-2h", "0pt", "xpt"} {
+		if _, err := ParseEstimate(input); err == nil {
+			t.Errorf("Expected error for estimate %q", input)
+		}
+	}
+}
+
+func TestEstimateString(t *testing.T) {
+	tests := []struct {
+		estimate Estimate
+		expected string
+	}{
+		{Estimate{Duration: 30 * time.Minute}, "30m"},
+		{Estimate{Duration: 2 * time.Hour}, "2h"},
+		{Estimate{Duration: 90 * time.Minute}, "1h30m"},
+		{Estimate{Points: 3}, "3pt"},
+		{Estimate{Points: 1.5}, "1.5pt"},
+	}
+
+	for _, tt := range tests {
+		if result := tt.estimate.String(); result != tt.expected {
+			t.Errorf("Estimate.String() = %s, expected %s", result, tt.expected)
+		}
+	}
+}
+
+func TestSetEstimate(t *testing.T) {
+	list := NewList()
+	mustAdd(t, list, "Task 1")
+
+	if err := list.SetEstimate(0, Estimate{Duration: time.Hour}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if list.Items[0].Estimate == nil || list.Items[0].Estimate.Duration != time.Hour {
+		t.Errorf("Expected estimate of 1h, got %v", list.Items[0].Estimate)
+	}
+
+	if !strings.Contains(list.String(), "1h") {
+		t.Error("String output should contain the estimate")
+	}
+
+	// A zero estimate clears it
+	if err := list.SetEstimate(0, Estimate{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if list.Items[0].Estimate != nil {
+		t.Error("Expected estimate to be cleared")
+	}
+
+	if err := list.SetEstimate(10, Estimate{Points: 1}); err == nil {
+		t.Error("Expected error when setting estimate on invalid index")
+	}
+}
+
+func TestLogTime(t *testing.T) {
+	list := NewList()
+	mustAdd(t, list, "Task 1")
+
+	if err := list.LogTime(0, 30*time.Minute); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := list.LogTime(0, 45*time.Minute); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if list.Items[0].Tracked != 75*time.Minute {
+		t.Errorf("Expected 1h15m tracked, got %v", list.Items[0].Tracked)
+	}
+
+	if err := list.LogTime(0, 0); err == nil {
+		t.Error("Expected error when logging zero time")
+	}
+	if err := list.LogTime(10, time.Minute); err == nil {
+		t.Error("Expected error when logging time on invalid index")
+	}
+}
+
+func TestStatsRemainingEstimate(t *testing.T) {
+	list := NewList()
+	mustAdd(t, list, "Task 1")
+	mustAdd(t, list, "Task 2")
+	mustAdd(t, list, "Task 3")
+
+	if err := list.SetEstimate(0, Estimate{Duration: 2 * time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.SetEstimate(1, Estimate{Points: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.SetEstimate(2, Estimate{Duration: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	mustComplete(t, list, 2)
+
+	stats := list.GetStats()
+	if stats.EstimatedTime != 2*time.Hour {
+		t.Errorf("Expected 2h remaining, got %v", stats.EstimatedTime)
+	}
+	if stats.EstimatedPoints != 3 {
+		t.Errorf("Expected 3 points remaining, got %v", stats.EstimatedPoints)
+	}
+}
+
+func TestEstimateReport(t *testing.T) {
+	list := NewList()
+	mustAdd(t, list, "Write docs")
+	mustAdd(t, list, "Fix bug")
+	mustAdd(t, list, "Chore")
+	mustAdd(t, list, "No estimate")
+
+	mustAddTag(t, list, 0, "docs")
+	mustAddTag(t, list, 1, "work")
+	mustAddTag(t, list, 1, "docs")
+
+	if err := list.SetEstimate(0, Estimate{Duration: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.LogTime(0, 90*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.SetEstimate(1, Estimate{Points: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.LogTime(1, 4*time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.LogTime(2, 15*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	report := list.EstimateReport()
+	if len(report) != 3 {
+		t.Fatalf("Expected 3 report rows, got %d: %+v", len(report), report)
+	}
+
+	// Rows are sorted by tag name
+	if report[0].Tag != UntaggedLabel || report[1].Tag != "docs" || report[2].Tag != "work" {
+		t.Errorf("Unexpected row order: %+v", report)
+	}
+
+	docs := report[1]
+	if docs.Items != 2 {
+		t.Errorf("Expected 2 docs items, got %d", docs.Items)
+	}
+	if docs.EstimatedTime != time.Hour || docs.EstimatedPoints != 2 {
+		t.Errorf("Unexpected docs estimates: %+v", docs)
+	}
+	if docs.Tracked != 330*time.Minute {
+		t.Errorf("Expected 5h30m tracked for docs, got %v", docs.Tracked)
+	}
+
+	if docs.Accuracy() != 1.5 {
+		t.Errorf("Expected docs accuracy 1.5, got %v", docs.Accuracy())
+	}
+	if docs.HoursPerPoint() != 2 {
+		t.Errorf("Expected 2 hours per point for docs, got %v", docs.HoursPerPoint())
+	}
+
+	work := report[2]
+	if work.HoursPerPoint() != 2 {
+		t.Errorf("Expected 2 hours per point for work, got %v", work.HoursPerPoint())
+	}
+	if work.Accuracy() != 0 {
+		t.Errorf("Expected no time accuracy for work, got %v", work.Accuracy())
+	}
+
+	untagged := report[0]
+	if untagged.Tracked != 15*time.Minute || untagged.Items != 1 {
+		t.Errorf("Unexpected untagged row: %+v", untagged)
+	}
+}
@@ -0,0 +1,90 @@
+package todo
+
+import "time"
+
+// EventType names the kind of change an Event describes
+type EventType string
+
+const (
+	EventAdded           EventType = "added"
+	EventCompleted       EventType = "completed"
+	EventUncompleted     EventType = "uncompleted"
+	EventEdited          EventType = "edited"
+	EventDeleted         EventType = "deleted"
+	EventPriorityChanged EventType = "priority-changed"
+	EventDueDateChanged  EventType = "due-date-changed"
+	EventTagAdded        EventType = "tag-added"
+	EventTagRemoved      EventType = "tag-removed"
+	EventEstimated       EventType = "estimated"
+	EventTimeLogged      EventType = "time-logged"
+	EventAssigned        EventType = "assigned"
+	EventContextChanged  EventType = "context-changed"
+	EventUpdated         EventType = "updated"
+	// EventCleared is sent for each item removed by ClearCompleted
+	EventCleared EventType = "cleared"
+)
+
+// Event describes one change to a list
+type Event struct {
+	Type EventType
+	// Item is the item after the change, or the removed item for
+	// EventDeleted and EventCleared
+	Item Item
+	// Previous is the item before the change; it is empty for EventAdded
+	Previous Item
+	// Tag is the tag added or removed by EventTagAdded and EventTagRemoved
+	Tag  string
+	Time time.Time
+}
+
+type subscriber struct {
+	id int
+	fn func(Event)
+}
+
+// Subscribe calls fn after every change made through the list's methods,
+// until the returned function is called
+func (l *List) Subscribe(fn func(Event)) (unsubscribe func()) {
+	l.nextSubscriber++
+	id := l.nextSubscriber
+	l.subscribers = append(l.subscribers, subscriber{id, fn})
+
+	return func() {
+		for i, s := range l.subscribers {
+			if s.id == id {
+				l.subscribers = append(l.subscribers[:i:i], l.subscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// emit notifies subscribers of a change
+func (l *List) emit(typ EventType, item, previous Item) {
+	if len(l.subscribers) == 0 {
+		return
+	}
+	l.emitEvent(Event{Type: typ, Item: item.clone(), Previous: previous.clone(), Time: time.Now()})
+}
+
+// emitTag notifies subscribers of a tag being added or removed
+func (l *List) emitTag(typ EventType, item, previous Item, tag string) {
+	if len(l.subscribers) == 0 {
+		return
+	}
+	l.emitEvent(Event{Type: typ, Item: item.clone(), Previous: previous.clone(), Tag: tag, Time: time.Now()})
+}
+
+func (l *List) emitEvent(e Event) {
+	for _, s := range l.subscribers {
+		s.fn(e)
+	}
+}
+
+// clone copies an item so later changes to the list don't show through
+func (item Item) clone() Item {
+	if item.Tags != nil {
+		item.Tags = append([]string{}, item.Tags...)
+	}
+	return item
+}
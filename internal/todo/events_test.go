@@ -0,0 +1,126 @@
+package todo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEventsForEveryMutation(t *testing.T) {
+	list := NewList()
+	var events []Event
+	list.Subscribe(func(e Event) {
+		events = append(events, e)
+	})
+
+	mustAdd(t, list, "Write tests")
+	mustAdd(t, list, "Review PR")
+	id := list.Items[0].ID
+
+	steps := []struct {
+		name     string
+		do       func() error
+		expected EventType
+	}{
+		{"Edit", func() error { return list.Edit(0, "Write more tests") }, EventEdited},
+		{"SetPriority", func() error { return list.SetPriority(0, PriorityHigh) }, EventPriorityChanged},
+		{"SetDueDate", func() error { return list.SetDueDate(0, time.Now()) }, EventDueDateChanged},
+		{"AddTag", func() error { return list.AddTag(0, "work") }, EventTagAdded},
+		{"RemoveTag", func() error { return list.RemoveTag(0, "work") }, EventTagRemoved},
+		{"SetEstimate", func() error { return list.SetEstimate(0, Estimate{Points: 3}) }, EventEstimated},
+		{"LogTime", func() error { return list.LogTime(0, time.Hour) }, EventTimeLogged},
+		{"Complete", func() error { return list.Complete(0) }, EventCompleted},
+		{"Uncomplete", func() error { return list.Uncomplete(list.IndexOf(id)) }, EventUncompleted},
+		{"Update", func() error {
+			item := list.Items[list.IndexOf(id)]
+			item.Done = true
+			return list.Update(list.IndexOf(id), item)
+		}, EventUpdated},
+		{"Delete", func() error { return list.Delete(0) }, EventDeleted},
+	}
+
+	if len(events) != 2 || events[0].Type != EventAdded || events[1].Item.Text != "Review PR" {
+		t.Fatalf("Expected two added events, got %+v", events)
+	}
+
+	for _, step := range steps {
+		events = nil
+		if err := step.do(); err != nil {
+			t.Fatalf("%s failed: %v", step.name, err)
+		}
+		if len(events) != 1 || events[0].Type != step.expected {
+			t.Errorf("%s: expected one %s event, got %+v", step.name, step.expected, events)
+		}
+	}
+
+	events = nil
+	if count := list.ClearCompleted(); count != 1 {
+		t.Fatalf("Expected to clear one item, got %d", count)
+	}
+	if len(events) != 1 || events[0].Type != EventCleared || events[0].Item.ID != id {
+		t.Errorf("Expected a cleared event for the completed item, got %+v", events)
+	}
+}
+
+func TestEventCarriesPreviousItem(t *testing.T) {
+	list := NewList()
+	mustAdd(t, list, "Task")
+	mustAddTag(t, list, 0, "a")
+	mustAddTag(t, list, 0, "b")
+
+	var events []Event
+	list.Subscribe(func(e Event) { events = append(events, e) })
+
+	if err := list.RemoveTag(0, "a"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Later changes to the list don't alter events already delivered
+	if err := list.Edit(0, "Renamed"); err != nil {
+		t.Fatal(err)
+	}
+	mustAddTag(t, list, 0, "c")
+
+	got := events[0]
+	if got.Tag != "a" || got.Item.Text != "Task" {
+		t.Errorf("Unexpected event: %+v", got)
+	}
+	if len(got.Previous.Tags) != 2 || got.Previous.Tags[0] != "a" || got.Previous.Tags[1] != "b" {
+		t.Errorf("Expected previous tags [a b], got %v", got.Previous.Tags)
+	}
+	if len(got.Item.Tags) != 1 || got.Item.Tags[0] != "b" {
+		t.Errorf("Expected tags [b], got %v", got.Item.Tags)
+	}
+}
+
+func TestFailedMutationsEmitNothing(t *testing.T) {
+	list := NewList()
+	mustAdd(t, list, "Task")
+
+	called := false
+	list.Subscribe(func(Event) { called = true })
+
+	if err := list.Complete(5); err == nil {
+		t.Fatal("Expected error for invalid index")
+	}
+	if err := list.Add("Task"); err == nil {
+		t.Fatal("Expected error for duplicate")
+	}
+	if called {
+		t.Error("Expected no events for failed changes")
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	list := NewList()
+	var first, second int
+	unsubscribe := list.Subscribe(func(Event) { first++ })
+	list.Subscribe(func(Event) { second++ })
+
+	mustAdd(t, list, "One")
+	unsubscribe()
+	mustAdd(t, list, "Two")
+
+	if first != 1 || second != 2 {
+		t.Errorf("Expected 1 and 2 calls, got %d and %d", first, second)
+	}
+}
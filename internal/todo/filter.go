@@ -0,0 +1,82 @@
+package todo
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+)
+
+// HasTag reports whether the item has tag
+func (i Item) HasTag(tag string) bool {
+	return slices.Contains(i.Tags, tag)
+}
+
+// MatchesQuery reports whether the item's text or a tag contains query,
+// ignoring case
+func (i Item) MatchesQuery(query string) bool {
+	query = strings.ToLower(query)
+	if strings.Contains(strings.ToLower(i.Text), query) {
+		return true
+	}
+	for _, tag := range i.Tags {
+		if strings.Contains(strings.ToLower(tag), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOverdue reports whether the item is pending and was due before now
+func (i Item) IsOverdue(now time.Time) bool {
+	return !i.Done && i.DueDate != nil && i.DueDate.Before(now)
+}
+
+// SameItem reports whether a and b are identical in every field
+func SameItem(a, b Item) bool {
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	return bytes.Equal(x, y)
+}
+
+// Filter selects items; fields left empty match every item
+type Filter struct {
+	// Query matches text or tags, like List.Search
+	Query    string
+	Tag      string
+	Priority *Priority
+	// Assignee matches ignoring case
+	Assignee string
+	// Status is "pending", "done" or empty for all
+	Status string
+	// Overdue keeps only items that are overdue at Now
+	Overdue bool
+	Now     time.Time
+}
+
+// Match reports whether the item passes every part of the filter
+func (f Filter) Match(item Item) bool {
+	switch {
+	case f.Query != "" && !item.MatchesQuery(f.Query),
+		f.Tag != "" && !item.HasTag(f.Tag),
+		f.Priority != nil && item.Priority != *f.Priority,
+		f.Assignee != "" && !strings.EqualFold(item.Assignee, f.Assignee),
+		f.Status == "pending" && item.Done,
+		f.Status == "done" && !item.Done,
+		f.Overdue && !item.IsOverdue(f.Now):
+		return false
+	}
+	return true
+}
+
+// Filter returns the items that pass f
+func (l *List) Filter(f Filter) []Item {
+	var results []Item
+	for _, item := range l.Items {
+		if f.Match(item) {
+			results = append(results, item)
+		}
+	}
+	return results
+}
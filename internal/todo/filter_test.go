@@ -0,0 +1,62 @@
+package todo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilterMatch(t *testing.T) {
+	now := time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC)
+	yesterday := now.AddDate(0, 0, -1)
+	high, low := PriorityHigh, PriorityLow
+	item := Item{
+		Text:     "Write report",
+		Priority: PriorityHigh,
+		Tags:     []string{"Work"},
+		Assignee: "alice",
+		DueDate:  &yesterday,
+	}
+	done := item
+	done.Done = true
+
+	tests := []struct {
+		name     string
+		filter   Filter
+		item     Item
+		expected bool
+	}{
+		{"empty", Filter{}, item, true},
+		{"query in text", Filter{Query: "REPORT"}, item, true},
+		{"query in tag", Filter{Query: "wor"}, item, true},
+		{"query missing", Filter{Query: "milk"}, item, false},
+		{"tag", Filter{Tag: "Work"}, item, true},
+		{"tag is exact", Filter{Tag: "work"}, item, false},
+		{"priority", Filter{Priority: &high}, item, true},
+		{"low priority", Filter{Priority: &low}, item, false},
+		{"assignee ignores case", Filter{Assignee: "Alice"}, item, true},
+		{"pending", Filter{Status: "pending"}, item, true},
+		{"pending done", Filter{Status: "pending"}, done, false},
+		{"done", Filter{Status: "done"}, done, true},
+		{"overdue", Filter{Overdue: true, Now: now}, item, true},
+		{"overdue done", Filter{Overdue: true, Now: now}, done, false},
+		{"not yet due", Filter{Overdue: true, Now: yesterday.Add(-time.Hour)}, item, false},
+		{"every field", Filter{Query: "write", Tag: "Work", Priority: &high, Assignee: "alice", Status: "pending"}, item, true},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.Match(tt.item); got != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestSameItem(t *testing.T) {
+	a := NewItem("Buy milk")
+	b := a.clone()
+	if !SameItem(a, b) {
+		t.Error("Expected a copy to be the same item")
+	}
+	b.Tags = append(b.Tags, "shopping")
+	if SameItem(a, b) {
+		t.Error("Expected a changed tag to make the items differ")
+	}
+}
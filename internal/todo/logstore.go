@@ -0,0 +1,336 @@
+package todo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// DefaultCompactEvery is how many log entries a LogStore keeps before folding
+// them into its snapshot
+const DefaultCompactEvery = 500
+
+// Log entry types. Entries for changed items carry the whole item, so replay
+// doesn't depend on how the change was made.
+const (
+	LogAdded       = "added"
+	LogUpdated     = "updated"
+	LogCompleted   = "completed"
+	LogUncompleted = "uncompleted"
+	LogDeleted     = "deleted"
+	// LogOrder records the order of the items after a sort
+	LogOrder = "order"
+)
+
+// LogEntry is one line of a LogStore log
+type LogEntry struct {
+	Seq   int64     `json:"seq"`
+	Time  time.Time `json:"time"`
+	Type  string    `json:"type"`
+	ID    string    `json:"id,omitempty"`
+	Item  *Item     `json:"item,omitempty"`
+	Order []string  `json:"order,omitempty"`
+}
+
+// LogStore keeps the list as an append-only log with one JSON entry per line.
+// Saving appends an entry for each change instead of rewriting the list, and
+// loading replays the log on top of the last snapshot. Once the log holds
+// CompactEvery entries, they are folded into the snapshot at Path+".snapshot".
+//
+// A last line cut short by a crash is ignored on load and removed by the next save.
+type LogStore struct {
+	Path         string
+	CompactEvery int
+}
+
+// logSnapshot is the list as of a log entry
+type logSnapshot struct {
+	Seq   int64
+	Items []Item
+}
+
+// logState is the result of replaying the snapshot and the log
+type logState struct {
+	seq     int64
+	items   []Item
+	entries int   // entries in the log
+	size    int64 // size of the log file
+	valid   int64 // size of the log up to the last complete entry
+}
+
+// SnapshotPath returns where the snapshot is kept
+func (s LogStore) SnapshotPath() string {
+	return s.Path + ".snapshot"
+}
+
+// Load rebuilds the list by replaying the log. A missing log yields an empty list.
+func (s LogStore) Load() (*List, error) {
+	state, err := s.replay()
+	if err != nil {
+		return nil, err
+	}
+	list := NewList()
+	list.Items = state.items
+	if list.Items == nil {
+		list.Items = []Item{}
+	}
+	return list, nil
+}
+
+// Save appends entries for the differences between the stored list and list
+func (s LogStore) Save(list *List) error {
+	for _, item := range list.Items {
+		if item.ID == "" {
+			return fmt.Errorf("Item %q has no ID", item.Text)
+		}
+	}
+
+	state, err := s.replay()
+	if err != nil {
+		return err
+	}
+
+	entries := diffLog(state.items, list.Items)
+	if len(entries) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	now := time.Now()
+	for i := range entries {
+		entries[i].Seq = state.seq + int64(i) + 1
+		entries[i].Time = now
+		line, err := json.Marshal(entries[i])
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	f, err := os.OpenFile(s.Path, os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	// Drop a partial entry left by an interrupted write before appending
+	if state.size > state.valid {
+		if err := f.Truncate(state.valid); err != nil {
+			return err
+		}
+	}
+	if _, err := f.WriteAt(buf.Bytes(), state.valid); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+
+	compactEvery := s.CompactEvery
+	if compactEvery <= 0 {
+		compactEvery = DefaultCompactEvery
+	}
+	if state.entries+len(entries) >= compactEvery {
+		return s.compact(f, logSnapshot{Seq: entries[len(entries)-1].Seq, Items: list.Items})
+	}
+	return nil
+}
+
+// compact writes a snapshot and empties the log. The snapshot records the
+// last entry it includes, so a crash before the log is emptied is harmless.
+func (s LogStore) compact(log *os.File, snap logSnapshot) error {
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	tmp := s.SnapshotPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.SnapshotPath()); err != nil {
+		return err
+	}
+	return log.Truncate(0)
+}
+
+// History returns the entries in the log since the last compaction
+func (s LogStore) History() ([]LogEntry, error) {
+	var entries []LogEntry
+	_, err := s.readLog(func(e LogEntry) {
+		entries = append(entries, e)
+	})
+	return entries, err
+}
+
+func (s LogStore) replay() (logState, error) {
+	var state logState
+
+	data, err := os.ReadFile(s.SnapshotPath())
+	switch {
+	case err == nil:
+		var snap logSnapshot
+		if err := json.Unmarshal(data, &snap); err != nil {
+			return state, fmt.Errorf("Invalid snapshot %s: %w", s.SnapshotPath(), err)
+		}
+		state.seq, state.items = snap.Seq, snap.Items
+	case !errors.Is(err, os.ErrNotExist):
+		return state, err
+	}
+
+	snapSeq := state.seq
+	info, err := s.readLog(func(e LogEntry) {
+		state.entries++
+		if e.Seq <= snapSeq {
+			// Already in the snapshot
+			return
+		}
+		state.items = applyLog(state.items, e)
+		state.seq = e.Seq
+	})
+	state.size, state.valid = info.size, info.valid
+	return state, err
+}
+
+type logInfo struct {
+	size  int64
+	valid int64
+}
+
+// readLog calls fn for every complete entry in the log
+func (s LogStore) readLog(fn func(LogEntry)) (logInfo, error) {
+	var info logInfo
+
+	f, err := os.Open(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return info, nil
+	}
+	if err != nil {
+		return info, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	lineNo := 0
+	for {
+		line, err := r.ReadBytes('\n')
+		info.size += int64(len(line))
+		if err == io.EOF {
+			// Anything after the last newline is an interrupted write
+			return info, nil
+		}
+		if err != nil {
+			return info, err
+		}
+		lineNo++
+
+		var e LogEntry
+		if err := json.Unmarshal(line, &e); err != nil {
+			if _, peekErr := r.Peek(1); peekErr == io.EOF {
+				// A garbled last line is also an interrupted write
+				return info, nil
+			}
+			return info, fmt.Errorf("Corrupt log %s at line %d: %v", s.Path, lineNo, err)
+		}
+		fn(e)
+		info.valid = info.size
+	}
+}
+
+// applyLog returns items with the entry applied
+func applyLog(items []Item, e LogEntry) []Item {
+	switch e.Type {
+	case LogDeleted:
+		for i, item := range items {
+			if item.ID == e.ID {
+				return append(items[:i:i], items[i+1:]...)
+			}
+		}
+	case LogOrder:
+		position := make(map[string]int, len(e.Order))
+		for i, id := range e.Order {
+			position[id] = i
+		}
+		ordered := make([]Item, len(e.Order))
+		found := make([]bool, len(e.Order))
+		var rest []Item
+		for _, item := range items {
+			if i, ok := position[item.ID]; ok && !found[i] {
+				ordered[i], found[i] = item, true
+			} else {
+				rest = append(rest, item)
+			}
+		}
+		result := make([]Item, 0, len(items))
+		for i, item := range ordered {
+			if found[i] {
+				result = append(result, item)
+			}
+		}
+		return append(result, rest...)
+	default:
+		if e.Item == nil {
+			return items
+		}
+		for i, item := range items {
+			if item.ID == e.Item.ID {
+				updated := append([]Item(nil), items...)
+				updated[i] = *e.Item
+				return updated
+			}
+		}
+		return append(items, *e.Item)
+	}
+	return items
+}
+
+// diffLog returns the entries that turn old into new
+func diffLog(old, new []Item) []LogEntry {
+	before := make(map[string]Item, len(old))
+	for _, item := range old {
+		before[item.ID] = item
+	}
+
+	var entries []LogEntry
+	current := make(map[string]bool, len(new))
+	for _, item := range new {
+		current[item.ID] = true
+		prev, ok := before[item.ID]
+		switch {
+		case !ok:
+			entries = append(entries, LogEntry{Type: LogAdded, ID: item.ID, Item: &item})
+		case item.Done && !prev.Done:
+			entries = append(entries, LogEntry{Type: LogCompleted, ID: item.ID, Item: &item})
+		case !item.Done && prev.Done:
+			entries = append(entries, LogEntry{Type: LogUncompleted, ID: item.ID, Item: &item})
+		case !SameItem(prev, item):
+			entries = append(entries, LogEntry{Type: LogUpdated, ID: item.ID, Item: &item})
+		}
+	}
+	for _, item := range old {
+		if !current[item.ID] {
+			entries = append(entries, LogEntry{Type: LogDeleted, ID: item.ID})
+		}
+	}
+
+	// Record the order only when replaying the changes would not produce it
+	replayed := old
+	for _, e := range entries {
+		replayed = applyLog(replayed, e)
+	}
+	for i := range replayed {
+		if replayed[i].ID != new[i].ID {
+			order := make([]string, len(new))
+			for j, item := range new {
+				order[j] = item.ID
+			}
+			entries = append(entries, LogEntry{Type: LogOrder, Order: order})
+			break
+		}
+	}
+	return entries
+}
@@ -0,0 +1,187 @@
+package todo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newLogStore(t *testing.T) LogStore {
+	return LogStore{Path: filepath.Join(t.TempDir(), "todos.log")}
+}
+
+func mustSave(t *testing.T, store Store, list *List) {
+	t.Helper()
+	if err := store.Save(list); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+}
+
+func mustLoad(t *testing.T, store Store) *List {
+	t.Helper()
+	list, err := store.Load()
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	return list
+}
+
+func itemTexts(list *List) []string {
+	var texts []string
+	for _, item := range list.Items {
+		texts = append(texts, item.Text)
+	}
+	return texts
+}
+
+func TestLogStoreReplay(t *testing.T) {
+	store := newLogStore(t)
+
+	list := mustLoad(t, store)
+	if len(list.Items) != 0 {
+		t.Errorf("Expected empty list, got %d items", len(list.Items))
+	}
+
+	mustAdd(t, list, "Task 1")
+	mustAdd(t, list, "Task 2")
+	mustAdd(t, list, "Task 3")
+	mustSave(t, store, list)
+
+	list = mustLoad(t, store)
+	mustComplete(t, list, 0)
+	if err := list.Edit(0, "Task 2 edited"); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.Delete(1); err != nil {
+		t.Fatal(err)
+	}
+	mustSave(t, store, list)
+
+	// Saving an unchanged list appends nothing
+	mustSave(t, store, list)
+
+	loaded := mustLoad(t, store)
+	if len(loaded.Items) != 2 || loaded.Items[0].Text != "Task 2 edited" || !loaded.Items[1].Done {
+		t.Errorf("Unexpected items after replay: %+v", loaded.Items)
+	}
+
+	history, err := store.History()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{LogAdded, LogAdded, LogAdded, LogUpdated, LogCompleted, LogDeleted, LogOrder}
+	if len(history) != len(expected) {
+		t.Fatalf("Expected %d log entries, got %+v", len(expected), history)
+	}
+	for i, e := range history {
+		if e.Type != expected[i] || e.Seq != int64(i+1) {
+			t.Errorf("Entry %d: expected %s #%d, got %s #%d", i, expected[i], i+1, e.Type, e.Seq)
+		}
+	}
+}
+
+func TestLogStoreKeepsOrder(t *testing.T) {
+	store := newLogStore(t)
+	list := NewList()
+	mustAdd(t, list, "Low")
+	mustAdd(t, list, "High")
+	mustSave(t, store, list)
+
+	mustSetPriority(t, list, 1, PriorityHigh)
+	list.Items[0], list.Items[1] = list.Items[1], list.Items[0]
+	mustSave(t, store, list)
+
+	loaded := mustLoad(t, store)
+	if texts := itemTexts(loaded); len(texts) != 2 || texts[0] != "High" || texts[1] != "Low" {
+		t.Errorf("Expected the saved order, got %v", texts)
+	}
+}
+
+func TestLogStoreCompaction(t *testing.T) {
+	store := newLogStore(t)
+	store.CompactEvery = 3
+
+	list := NewList()
+	mustAdd(t, list, "Task 1")
+	mustAdd(t, list, "Task 2")
+	mustSave(t, store, list)
+	if _, err := os.Stat(store.SnapshotPath()); !os.IsNotExist(err) {
+		t.Fatal("Expected no snapshot before the log is full")
+	}
+
+	mustAdd(t, list, "Task 3")
+	mustSave(t, store, list)
+	if _, err := os.Stat(store.SnapshotPath()); err != nil {
+		t.Fatalf("Expected a snapshot: %v", err)
+	}
+	if history, _ := store.History(); len(history) != 0 {
+		t.Errorf("Expected an empty log after compaction, got %d entries", len(history))
+	}
+
+	mustComplete(t, list, 0)
+	mustSave(t, store, list)
+	loaded := mustLoad(t, store)
+	if len(loaded.Items) != 3 || !loaded.Items[2].Done || loaded.Items[2].Text != "Task 1" {
+		t.Errorf("Unexpected items after compaction: %+v", loaded.Items)
+	}
+	if history, _ := store.History(); len(history) != 2 || history[0].Seq != 4 {
+		t.Errorf("Expected the log to continue after the snapshot, got %+v", history)
+	}
+}
+
+func TestLogStoreTruncatedLastLine(t *testing.T) {
+	for name, tail := range map[string]string{
+		"partial": `{"seq":3,"type":"added","item":{"ID":"x`,
+		"garbled": "\x00\x00\x00\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			store := newLogStore(t)
+			list := NewList()
+			mustAdd(t, list, "Task 1")
+			mustAdd(t, list, "Task 2")
+			mustSave(t, store, list)
+
+			f, err := os.OpenFile(store.Path, os.O_APPEND|os.O_WRONLY, 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.WriteString(tail)
+			f.Close()
+
+			loaded := mustLoad(t, store)
+			if len(loaded.Items) != 2 {
+				t.Fatalf("Expected the complete entries to load, got %+v", loaded.Items)
+			}
+
+			// The next save replaces the damaged tail
+			mustAdd(t, loaded, "Task 3")
+			mustSave(t, store, loaded)
+			history, err := store.History()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(history) != 3 || history[2].Item.Text != "Task 3" {
+				t.Errorf("Expected the damaged tail to be replaced, got %+v", history)
+			}
+		})
+	}
+}
+
+func TestLogStoreCorruptMiddleLine(t *testing.T) {
+	store := newLogStore(t)
+	list := NewList()
+	mustAdd(t, list, "Task 1")
+	mustSave(t, store, list)
+
+	data, err := os.ReadFile(store.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = append([]byte("not json\n"), data...)
+	if err := os.WriteFile(store.Path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Load(); err == nil {
+		t.Error("Expected error for a corrupt entry before the last line")
+	}
+}
@@ -0,0 +1,241 @@
+package todo
+
+import (
+	"fmt"
+	"time"
+)
+
+// Conflict is a change made differently on both sides of a merge. The merged
+// list keeps our side.
+type Conflict struct {
+	ID string
+	// Text identifies the item; it is the text in the merged list
+	Text string
+	// Field is what conflicted: text, done, priority, due, estimate, assignee, or item
+	// when one side deleted an item the other changed
+	Field  string
+	Ours   string
+	Theirs string
+}
+
+func (c Conflict) String() string {
+	return fmt.Sprintf("%q: %s is %s in ours but %s in theirs; kept ours", c.Text, c.Field, c.Ours, c.Theirs)
+}
+
+// Merge combines two lists that were changed independently since base.
+// Items are matched by ID and merged field by field: a field changed on one
+// side takes that side's value, tags are merged as sets (additions from
+// either side are kept, removals from either side are applied) and tracked
+// time logged on both sides is added up. Fields changed differently on both
+// sides, and items deleted on one side but changed on the other, are
+// conflicts; the merged list keeps our side of them.
+func Merge(base, ours, theirs *List) (*List, []Conflict) {
+	baseItems := mapByID(base)
+	ourItems := mapByID(ours)
+	theirItems := mapByID(theirs)
+
+	merged := NewList()
+	var conflicts []Conflict
+	for _, our := range ours.Items {
+		b, inBase := baseItems[our.ID]
+		their, inTheirs := theirItems[our.ID]
+		switch {
+		case !inTheirs && !inBase:
+			// Added by us
+			merged.Items = append(merged.Items, our)
+		case !inTheirs:
+			// Deleted by them; keep it only if we changed it since
+			if !SameItem(our, b) {
+				merged.Items = append(merged.Items, our)
+				conflicts = append(conflicts, Conflict{ID: our.ID, Text: our.Text, Field: "item", Ours: "changed", Theirs: "deleted"})
+			}
+		default:
+			// Both sides have it. Items added on both sides merge against an
+			// empty base, taking the time tracked on both as shared.
+			if !inBase {
+				b = Item{ID: our.ID, Tracked: min(our.Tracked, their.Tracked)}
+			}
+			item, itemConflicts := mergeItem(b, our, their)
+			merged.Items = append(merged.Items, item)
+			conflicts = append(conflicts, itemConflicts...)
+		}
+	}
+	for _, their := range theirs.Items {
+		if _, inOurs := ourItems[their.ID]; inOurs {
+			continue
+		}
+		b, inBase := baseItems[their.ID]
+		switch {
+		case !inBase:
+			// Added by them
+			merged.Items = append(merged.Items, their)
+		case !SameItem(their, b):
+			// Deleted by us after they changed it; we win
+			conflicts = append(conflicts, Conflict{ID: their.ID, Text: their.Text, Field: "item", Ours: "deleted", Theirs: "changed"})
+		}
+	}
+
+	merged.Sort()
+	return merged, conflicts
+}
+
+// mergeItem merges the fields of one item
+func mergeItem(base, ours, theirs Item) (Item, []Conflict) {
+	merged := ours
+	var conflicts []Conflict
+	conflict := func(field, o, t string) {
+		conflicts = append(conflicts, Conflict{ID: ours.ID, Field: field, Ours: o, Theirs: t})
+	}
+
+	var ok bool
+	if merged.Text, ok = merge3(base.Text, ours.Text, theirs.Text, eq[string]); !ok {
+		conflict("text", fmt.Sprintf("%q", ours.Text), fmt.Sprintf("%q", theirs.Text))
+	}
+	if merged.Done, ok = merge3(base.Done, ours.Done, theirs.Done, eq[bool]); !ok {
+		conflict("done", doneString(ours.Done), doneString(theirs.Done))
+	}
+	// The completion time goes with the done flag it belongs to
+	if ours.Done == theirs.Done {
+		merged.CompletedAt, _ = merge3(base.CompletedAt, ours.CompletedAt, theirs.CompletedAt, sameTime)
+	} else if merged.Done == theirs.Done {
+		merged.CompletedAt = theirs.CompletedAt
+	}
+	if merged.Priority, ok = merge3(base.Priority, ours.Priority, theirs.Priority, eq[Priority]); !ok {
+		conflict("priority", ours.Priority.String(), theirs.Priority.String())
+	}
+	if merged.DueDate, ok = merge3(base.DueDate, ours.DueDate, theirs.DueDate, sameTime); !ok {
+		conflict("due", dueString(ours.DueDate), dueString(theirs.DueDate))
+	}
+	if merged.Estimate, ok = merge3(base.Estimate, ours.Estimate, theirs.Estimate, sameEstimate); !ok {
+		conflict("estimate", estimateString(ours.Estimate), estimateString(theirs.Estimate))
+	}
+	if merged.Assignee, ok = merge3(base.Assignee, ours.Assignee, theirs.Assignee, eq[string]); !ok {
+		conflict("assignee", assigneeString(ours.Assignee), assigneeString(theirs.Assignee))
+	}
+	if merged.Context, ok = merge3(base.Context, ours.Context, theirs.Context, eq[string]); !ok {
+		conflict("context", contextString(ours.Context), contextString(theirs.Context))
+	}
+	merged.Tags = mergeTags(base.Tags, ours.Tags, theirs.Tags)
+	// Time logged on either side counts
+	merged.Tracked = ours.Tracked + theirs.Tracked - base.Tracked
+
+	for i := range conflicts {
+		conflicts[i].Text = merged.Text
+	}
+	return merged, conflicts
+}
+
+// merge3 merges one value: a side that left it unchanged takes the other
+// side's value. It reports false when both sides changed it differently, and
+// then returns ours.
+func merge3[T any](base, ours, theirs T, equal func(a, b T) bool) (T, bool) {
+	switch {
+	case equal(ours, theirs), equal(theirs, base):
+		return ours, true
+	case equal(ours, base):
+		return theirs, true
+	default:
+		return ours, false
+	}
+}
+
+func eq[T comparable](a, b T) bool {
+	return a == b
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func sameEstimate(a, b *Estimate) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// mergeTags keeps a tag if both sides have it, or one side added it
+func mergeTags(base, ours, theirs []string) []string {
+	inBase := tagSet(base)
+	inOurs := tagSet(ours)
+	inTheirs := tagSet(theirs)
+
+	tags := []string{}
+	for _, tag := range ours {
+		if inTheirs[tag] || !inBase[tag] {
+			tags = append(tags, tag)
+		}
+	}
+	for _, tag := range theirs {
+		if !inOurs[tag] && !inBase[tag] {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func tagSet(tags []string) map[string]bool {
+	set := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		set[tag] = true
+	}
+	return set
+}
+
+func mapByID(list *List) map[string]Item {
+	items := make(map[string]Item, len(list.Items))
+	for _, item := range list.Items {
+		items[item.ID] = item
+	}
+	return items
+}
+
+func tagsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func doneString(done bool) string {
+	if done {
+		return "done"
+	}
+	return "not done"
+}
+
+func dueString(due *time.Time) string {
+	if due == nil {
+		return "none"
+	}
+	return due.Format("2006-01-02")
+}
+
+func estimateString(e *Estimate) string {
+	if e == nil {
+		return "none"
+	}
+	return e.String()
+}
+
+func assigneeString(user string) string {
+	if user == "" {
+		return "nobody"
+	}
+	return user
+}
+
+func contextString(context string) string {
+	if context == "" {
+		return "none"
+	}
+	return "@" + context
+}
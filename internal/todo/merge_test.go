@@ -0,0 +1,172 @@
+package todo
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// forkList returns a base list and two copies of it
+func forkList(t *testing.T, texts ...string) (*List, *List, *List) {
+	t.Helper()
+	base := NewList()
+	for _, text := range texts {
+		mustAdd(t, base, text)
+	}
+	copyList := func() *List {
+		list := NewList()
+		for _, item := range base.Items {
+			list.Items = append(list.Items, item.clone())
+		}
+		return list
+	}
+	return base, copyList(), copyList()
+}
+
+func TestMergeDifferentFields(t *testing.T) {
+	base, ours, theirs := forkList(t, "Write report", "Buy milk")
+	due := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	if err := ours.Edit(0, "Write quarterly report"); err != nil {
+		t.Fatal(err)
+	}
+	mustSetPriority(t, theirs, 0, PriorityHigh)
+	mustSetDueDate(t, theirs, 0, due)
+	mustComplete(t, theirs, 1)
+
+	merged, conflicts := Merge(base, ours, theirs)
+	if len(conflicts) != 0 {
+		t.Errorf("Expected no conflicts, got %v", conflicts)
+	}
+	if len(merged.Items) != 2 {
+		t.Fatalf("Expected 2 items, got %+v", merged.Items)
+	}
+	report := merged.Items[0]
+	if report.Text != "Write quarterly report" || report.Priority != PriorityHigh || report.DueDate == nil || !report.DueDate.Equal(due) {
+		t.Errorf("Expected changes from both sides, got %+v", report)
+	}
+	if milk := merged.Items[1]; !milk.Done || milk.CompletedAt == nil {
+		t.Errorf("Expected Buy milk to be completed, got %+v", milk)
+	}
+}
+
+func TestMergeTagsAsSets(t *testing.T) {
+	base, ours, theirs := forkList(t, "Task")
+	mustAddTag(t, base, 0, "keep")
+	mustAddTag(t, base, 0, "drop-ours")
+	mustAddTag(t, base, 0, "drop-theirs")
+	ours.Items[0].Tags = []string{"keep", "drop-theirs", "new-ours"}
+	theirs.Items[0].Tags = []string{"keep", "drop-ours", "new-theirs"}
+
+	merged, conflicts := Merge(base, ours, theirs)
+	if len(conflicts) != 0 {
+		t.Errorf("Tags should never conflict, got %v", conflicts)
+	}
+	if tags := strings.Join(merged.Items[0].Tags, ","); tags != "keep,new-ours,new-theirs" {
+		t.Errorf("Expected keep,new-ours,new-theirs, got %s", tags)
+	}
+}
+
+func TestMergeConflicts(t *testing.T) {
+	base, ours, theirs := forkList(t, "Draft", "Plan")
+	if err := ours.Edit(0, "Draft A"); err != nil {
+		t.Fatal(err)
+	}
+	if err := theirs.Edit(0, "Draft B"); err != nil {
+		t.Fatal(err)
+	}
+	mustSetPriority(t, ours, 0, PriorityHigh)
+	mustSetPriority(t, theirs, 0, PriorityLow)
+
+	merged, conflicts := Merge(base, ours, theirs)
+	if len(conflicts) != 2 {
+		t.Fatalf("Expected text and priority conflicts, got %v", conflicts)
+	}
+	if conflicts[0].Field != "text" || conflicts[1].Field != "priority" || conflicts[0].Text != "Draft A" {
+		t.Errorf("Unexpected conflicts: %+v", conflicts)
+	}
+	if merged.Items[0].Text != "Draft A" || merged.Items[0].Priority != PriorityHigh {
+		t.Errorf("Expected our side to be kept, got %+v", merged.Items[0])
+	}
+	if !strings.Contains(conflicts[0].String(), `"Draft B" in theirs`) {
+		t.Errorf("Unexpected report: %s", conflicts[0])
+	}
+}
+
+func TestMergeAddsAndDeletes(t *testing.T) {
+	base, ours, theirs := forkList(t, "Unchanged", "Deleted by us", "Deleted by them", "Changed then deleted")
+	mustAdd(t, ours, "Added by us")
+	mustAdd(t, theirs, "Added by them")
+	if err := ours.Delete(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := theirs.Delete(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := ours.Delete(2); err != nil { // "Changed then deleted"
+		t.Fatal(err)
+	}
+	if err := theirs.Edit(2, "Changed by them"); err != nil {
+		t.Fatal(err)
+	}
+
+	merged, conflicts := Merge(base, ours, theirs)
+	var texts []string
+	for _, item := range merged.Items {
+		texts = append(texts, item.Text)
+	}
+	if got := strings.Join(texts, ","); got != "Unchanged,Added by us,Added by them" {
+		t.Errorf("Unexpected items: %s", got)
+	}
+	if len(conflicts) != 1 || conflicts[0].Field != "item" || conflicts[0].Theirs != "changed" {
+		t.Errorf("Expected a delete/change conflict, got %v", conflicts)
+	}
+}
+
+func TestMergeTrackedTime(t *testing.T) {
+	base, ours, theirs := forkList(t, "Task")
+	base.Items[0].Tracked = time.Hour
+	ours.Items[0].Tracked = time.Hour + 30*time.Minute
+	theirs.Items[0].Tracked = 2 * time.Hour
+
+	merged, conflicts := Merge(base, ours, theirs)
+	if len(conflicts) != 0 || merged.Items[0].Tracked != 2*time.Hour+30*time.Minute {
+		t.Errorf("Expected time from both sides, got %s (%v)", merged.Items[0].Tracked, conflicts)
+	}
+}
+
+func TestMergeAssignee(t *testing.T) {
+	base, ours, theirs := forkList(t, "Task 1", "Task 2")
+	mustAssign(t, theirs, 0, "alice")
+	mustAssign(t, ours, 1, "bob")
+	mustAssign(t, theirs, 1, "carol")
+
+	merged, conflicts := Merge(base, ours, theirs)
+	if merged.Items[0].Assignee != "alice" {
+		t.Errorf("Expected their assignment to be merged, got %q", merged.Items[0].Assignee)
+	}
+	if len(conflicts) != 1 || conflicts[0].Field != "assignee" || merged.Items[1].Assignee != "bob" {
+		t.Errorf("Expected an assignee conflict resolved to ours, got %v, %+v", conflicts, merged.Items[1])
+	}
+}
+
+func TestMergeContext(t *testing.T) {
+	base, ours, theirs := forkList(t, "Task 1", "Task 2")
+	if err := theirs.SetContext(0, "office"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ours.SetContext(1, "home"); err != nil {
+		t.Fatal(err)
+	}
+	if err := theirs.SetContext(1, "phone"); err != nil {
+		t.Fatal(err)
+	}
+
+	merged, conflicts := Merge(base, ours, theirs)
+	if merged.Items[0].Context != "office" {
+		t.Errorf("Expected their context to be merged, got %q", merged.Items[0].Context)
+	}
+	if len(conflicts) != 1 || conflicts[0].Field != "context" || conflicts[0].Theirs != "@phone" {
+		t.Errorf("Expected a context conflict, got %v", conflicts)
+	}
+}
@@ -0,0 +1,196 @@
+package todo
+
+import (
+	"slices"
+	"sort"
+	"time"
+)
+
+// Observer is told about the changes saved through an ObservedStore
+type Observer interface {
+	// BeforeSave may change the items of the events, which are saved in
+	// place of the list's, or return an error to stop the save
+	BeforeSave(changes []Event) error
+	// AfterSave is told about the changes once they are saved
+	AfterSave(changes []Event)
+}
+
+// ObservedStore is a Store whose observers see every change saved through
+// it, however the list was changed: through its methods, by a sync, or by
+// replacing its items
+type ObservedStore struct {
+	Store
+	Observers []Observer
+}
+
+// Observe wraps store so observers see the changes saved through it. The
+// result is a Mutator when store is one.
+func Observe(store Store, observers ...Observer) Store {
+	observed := &ObservedStore{Store: store, Observers: observers}
+	if m, ok := store.(Mutator); ok {
+		return &observedMutator{observed, m}
+	}
+	return observed
+}
+
+// Save compares the list with the stored one and saves it unless an
+// observer stops it. Changes observers make to items are applied to list.
+func (s *ObservedStore) Save(list *List) error {
+	if len(s.Observers) == 0 {
+		return s.Store.Save(list)
+	}
+	stored, err := s.Store.Load()
+	if err != nil {
+		return err
+	}
+	changes := Diff(stored, list, time.Now())
+	if len(changes) == 0 {
+		return s.Store.Save(list)
+	}
+
+	for _, o := range s.Observers {
+		if err := o.BeforeSave(changes); err != nil {
+			return err
+		}
+	}
+	for _, change := range changes {
+		if change.Type == EventDeleted {
+			continue
+		}
+		if index := list.IndexOf(change.Item.ID); index >= 0 && !SameItem(list.Items[index], change.Item) {
+			if err := list.Update(index, change.Item); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := s.Store.Save(list); err != nil {
+		return err
+	}
+	for _, o := range s.Observers {
+		o.AfterSave(changes)
+	}
+	return nil
+}
+
+// observedMutator passes changes to single items through the observers
+type observedMutator struct {
+	*ObservedStore
+	mutator Mutator
+}
+
+func (s *observedMutator) ItemAt(index int) (Item, error) {
+	return s.mutator.ItemAt(index)
+}
+
+func (s *observedMutator) Apply(changes []Event) error {
+	if len(changes) == 0 {
+		return s.mutator.Apply(changes)
+	}
+	for _, o := range s.Observers {
+		if err := o.BeforeSave(changes); err != nil {
+			return err
+		}
+	}
+	if err := s.mutator.Apply(changes); err != nil {
+		return err
+	}
+	for _, o := range s.Observers {
+		o.AfterSave(changes)
+	}
+	return nil
+}
+
+// Diff returns the changes that turn before into after, as the events the
+// list's methods would emit where a single kind of change was made to an
+// item, and EventUpdated where several were. Removed items are reported as
+// EventDeleted. Moving items is not a change.
+func Diff(before, after *List, now time.Time) []Event {
+	previous := mapByID(before)
+	var changes []Event
+	for _, item := range after.Items {
+		prev, ok := previous[item.ID]
+		switch {
+		case !ok:
+			changes = append(changes, Event{Type: EventAdded, Item: item.clone(), Time: now})
+		case !SameItem(prev, item):
+			changes = append(changes, itemChanges(prev, item, now)...)
+		}
+	}
+
+	current := mapByID(after)
+	for _, item := range before.Items {
+		if _, ok := current[item.ID]; !ok {
+			changes = append(changes, Event{Type: EventDeleted, Item: item.clone(), Previous: item.clone(), Time: now})
+		}
+	}
+	return changes
+}
+
+// itemChanges describes how an item changed
+func itemChanges(prev, item Item, now time.Time) []Event {
+	event := func(typ EventType, tag string) Event {
+		return Event{Type: typ, Item: item.clone(), Previous: prev.clone(), Tag: tag, Time: now}
+	}
+
+	var types []EventType
+	if prev.Done != item.Done {
+		if item.Done {
+			types = append(types, EventCompleted)
+		} else {
+			types = append(types, EventUncompleted)
+		}
+	}
+	if prev.Text != item.Text {
+		types = append(types, EventEdited)
+	}
+	if prev.Priority != item.Priority {
+		types = append(types, EventPriorityChanged)
+	}
+	if !sameTime(prev.DueDate, item.DueDate) {
+		types = append(types, EventDueDateChanged)
+	}
+	if !sameEstimate(prev.Estimate, item.Estimate) {
+		types = append(types, EventEstimated)
+	}
+	if prev.Tracked != item.Tracked {
+		types = append(types, EventTimeLogged)
+	}
+	if prev.Assignee != item.Assignee {
+		types = append(types, EventAssigned)
+	}
+	if prev.Context != item.Context {
+		types = append(types, EventContextChanged)
+	}
+	tagsChanged := !tagsEqual(prev.Tags, item.Tags)
+
+	switch {
+	case len(types) == 1 && !tagsChanged:
+		return []Event{event(types[0], "")}
+	case len(types) == 0 && tagsChanged:
+		var changes []Event
+		for _, tag := range difference(item.Tags, prev.Tags) {
+			changes = append(changes, event(EventTagAdded, tag))
+		}
+		for _, tag := range difference(prev.Tags, item.Tags) {
+			changes = append(changes, event(EventTagRemoved, tag))
+		}
+		if len(changes) > 0 {
+			return changes
+		}
+	}
+	// Several changes, reordered tags, or fields only Update changes
+	return []Event{event(EventUpdated, "")}
+}
+
+// difference returns the tags in a that are not in b, sorted
+func difference(a, b []string) []string {
+	var tags []string
+	for _, tag := range a {
+		if !slices.Contains(b, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
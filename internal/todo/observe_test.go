@@ -0,0 +1,194 @@
+package todo
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	before := NewList()
+	mustAdd(t, before, "Write report")
+	mustAdd(t, before, "Buy milk")
+	mustAdd(t, before, "Call mum")
+	mustAdd(t, before, "Water plants")
+
+	after := NewList()
+	after.Items = append(after.Items, before.Items...)
+	for i := range after.Items {
+		after.Items[i] = after.Items[i].clone()
+	}
+	if err := after.Complete(after.IndexOf(before.Items[0].ID)); err != nil {
+		t.Fatal(err)
+	}
+	if err := after.AddTag(after.IndexOf(before.Items[1].ID), "shopping"); err != nil {
+		t.Fatal(err)
+	}
+	index := after.IndexOf(before.Items[2].ID)
+	after.Items[index].Text = "Call mum back"
+	after.Items[index].Priority = PriorityHigh
+	if err := after.Delete(after.IndexOf(before.Items[3].ID)); err != nil {
+		t.Fatal(err)
+	}
+	mustAdd(t, after, "Book flights")
+
+	changes := Diff(before, after, time.Now())
+	got := map[string]EventType{}
+	for _, e := range changes {
+		got[e.Item.Text] = e.Type
+	}
+	expected := map[string]EventType{
+		"Write report":  EventCompleted,
+		"Buy milk":      EventTagAdded,
+		"Call mum back": EventUpdated,
+		"Water plants":  EventDeleted,
+		"Book flights":  EventAdded,
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %+v", len(expected), changes)
+	}
+	for text, typ := range expected {
+		if got[text] != typ {
+			t.Errorf("%s: expected %s, got %s", text, typ, got[text])
+		}
+	}
+	for _, e := range changes {
+		if e.Type == EventTagAdded && e.Tag != "shopping" {
+			t.Errorf("Expected the added tag, got %q", e.Tag)
+		}
+		if e.Type == EventUpdated && e.Previous.Text != "Call mum" {
+			t.Errorf("Expected the item before the change, got %+v", e.Previous)
+		}
+	}
+
+	if changes := Diff(after, after, time.Now()); len(changes) != 0 {
+		t.Errorf("Expected no changes, got %+v", changes)
+	}
+}
+
+// testObserver records what it is told and may change or reject saves
+type testObserver struct {
+	before, after []Event
+	change        func(changes []Event) error
+}
+
+func (o *testObserver) BeforeSave(changes []Event) error {
+	o.before = append(o.before, changes...)
+	if o.change != nil {
+		return o.change(changes)
+	}
+	return nil
+}
+
+func (o *testObserver) AfterSave(changes []Event) {
+	o.after = append(o.after, changes...)
+}
+
+func TestObservedStore(t *testing.T) {
+	observer := &testObserver{}
+	store := Observe(FileStore{Path: filepath.Join(t.TempDir(), "todos.json")}, observer)
+
+	list := NewList()
+	mustAdd(t, list, "buy milk")
+	observer.change = func(changes []Event) error {
+		changes[0].Item.Text = "Buy milk"
+		return nil
+	}
+	if err := store.Save(list); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.(Mutator); ok {
+		t.Error("Expected a FileStore not to become a Mutator")
+	}
+	if len(observer.after) != 1 || observer.after[0].Type != EventAdded {
+		t.Fatalf("Expected the add to be observed, got %+v", observer.after)
+	}
+	saved, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Items[0].Text != "Buy milk" || saved.Items[0].Text != "Buy milk" {
+		t.Errorf("Expected the observer's change to be applied and saved, got %q and %q", list.Items[0].Text, saved.Items[0].Text)
+	}
+
+	// Saving without changes tells observers nothing
+	observer.before, observer.after = nil, nil
+	if err := store.Save(list); err != nil {
+		t.Fatal(err)
+	}
+	if len(observer.before) != 0 {
+		t.Errorf("Expected no changes, got %+v", observer.before)
+	}
+
+	// A rejected change is not saved
+	observer.change = func([]Event) error { return errors.New("no") }
+	if err := list.Complete(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(list); err == nil {
+		t.Fatal("Expected the save to be rejected")
+	}
+	if saved, _ := store.Load(); saved.Items[0].Done {
+		t.Error("Expected the rejected change not to be saved")
+	}
+	if len(observer.after) != 0 {
+		t.Errorf("Expected a rejected change not to be reported as saved, got %+v", observer.after)
+	}
+}
+
+// testMutator records the changes applied to it
+type testMutator struct {
+	Store
+	applied []Event
+}
+
+func (m *testMutator) ItemAt(index int) (Item, error) { return Item{}, nil }
+
+func (m *testMutator) Apply(changes []Event) error {
+	m.applied = append(m.applied, changes...)
+	return nil
+}
+
+func TestObservedMutator(t *testing.T) {
+	observer := &testObserver{}
+	inner := &testMutator{Store: FileStore{Path: filepath.Join(t.TempDir(), "todos.json")}}
+	store, ok := Observe(inner, observer).(Mutator)
+	if !ok {
+		t.Fatal("Expected a Mutator to stay one")
+	}
+
+	added := Event{Type: EventAdded, Item: NewItem("buy milk"), Time: time.Now()}
+	observer.change = func(changes []Event) error {
+		changes[0].Item.Text = "Buy milk"
+		return nil
+	}
+	if err := store.Apply([]Event{added}); err != nil {
+		t.Fatal(err)
+	}
+	if len(inner.applied) != 1 || inner.applied[0].Item.Text != "Buy milk" {
+		t.Errorf("Expected the observer's change to be applied, got %+v", inner.applied)
+	}
+	if len(observer.after) != 1 {
+		t.Errorf("Expected the change to be reported as saved, got %+v", observer.after)
+	}
+
+	// A rejected change is not applied
+	inner.applied, observer.after = nil, nil
+	observer.change = func([]Event) error { return errors.New("no") }
+	if err := store.Apply([]Event{added}); err == nil {
+		t.Fatal("Expected the change to be rejected")
+	}
+	if len(inner.applied) != 0 || len(observer.after) != 0 {
+		t.Errorf("Expected nothing applied, got %+v and %+v", inner.applied, observer.after)
+	}
+
+	// No changes tell observers nothing
+	observer.before = nil
+	if err := store.Apply(nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(observer.before) != 0 {
+		t.Errorf("Expected no changes, got %+v", observer.before)
+	}
+}
@@ -0,0 +1,55 @@
+package todo
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+// Store loads and saves a whole todo list
+type Store interface {
+	Load() (*List, error)
+	Save(list *List) error
+}
+
+// Querier is a Store that answers queries without loading the whole list.
+// Results are in list order, like those of the corresponding List methods.
+type Querier interface {
+	Search(query string) ([]Item, error)
+	FilterByPriority(priority Priority) ([]Item, error)
+	FilterByTag(tag string) ([]Item, error)
+	Overdue(now time.Time) ([]Item, error)
+	Stats() (Stats, error)
+}
+
+// Mutator is a Store that changes single items without loading and saving
+// the whole list
+type Mutator interface {
+	// ItemAt returns the item at index, in list order
+	ItemAt(index int) (Item, error)
+	// Apply saves the changes events describe, as the list's methods would
+	// make them, or none of them
+	Apply(changes []Event) error
+}
+
+// FileStore keeps the list in a JSON file
+type FileStore struct {
+	Path string
+}
+
+// Load reads the list from the file. A missing file yields an empty list.
+func (s FileStore) Load() (*List, error) {
+	list := NewList()
+	if err := list.Load(s.Path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return list, nil
+		}
+		return nil, err
+	}
+	return list, nil
+}
+
+// Save writes the list to the file
+func (s FileStore) Save(list *List) error {
+	return list.Save(s.Path)
+}
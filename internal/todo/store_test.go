@@ -0,0 +1,40 @@
+package todo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileStore(t *testing.T) {
+	store := FileStore{Path: filepath.Join(t.TempDir(), "todos.json")}
+
+	// A missing file is an empty list
+	list, err := store.Load()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(list.Items) != 0 {
+		t.Errorf("Expected empty list, got %d items", len(list.Items))
+	}
+
+	mustAdd(t, list, "Task 1")
+	if err := store.Save(list); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	if len(loaded.Items) != 1 || loaded.Items[0].Text != "Task 1" {
+		t.Errorf("Unexpected items after reload: %+v", loaded.Items)
+	}
+
+	if err := os.WriteFile(store.Path, []byte("{{{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Load(); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+}
@@ -1,6 +1,9 @@
 package todo
 
 import (
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -30,30 +33,68 @@ func (p Priority) String() string {
 	}
 }
 
-func ParsePriority(s string) Priority {
-	switch strings.ToUpper(s) {
-	case "HIGH", "H":
-		return PriorityHigh
-	case "MEDIUM", "MED", "M":
-		return PriorityMedium
-	case "LOW", "L":
-		return PriorityLow
-	default:
-		return PriorityMedium
+// priorityNames are the names ParsePriority accepts, ignoring case
+var priorityNames = []struct {
+	name     string
+	priority Priority
+}{
+	{"high", PriorityHigh},
+	{"h", PriorityHigh},
+	{"medium", PriorityMedium},
+	{"med", PriorityMedium},
+	{"m", PriorityMedium},
+	{"low", PriorityLow},
+	{"l", PriorityLow},
+}
+
+// PriorityNames returns the names ParsePriority accepts, from high to low
+func PriorityNames() []string {
+	names := make([]string, len(priorityNames))
+	for i, p := range priorityNames {
+		names[i] = p.name
 	}
+	return names
+}
+
+// LookupPriority returns the priority named s, reporting whether s is a
+// priority name at all
+func LookupPriority(s string) (Priority, bool) {
+	for _, p := range priorityNames {
+		if strings.EqualFold(p.name, s) {
+			return p.priority, true
+		}
+	}
+	return PriorityMedium, false
+}
+
+// ParsePriority returns the priority named s, or medium for unknown names
+func ParsePriority(s string) Priority {
+	priority, _ := LookupPriority(s)
+	return priority
 }
 
 type Item struct {
+	ID        string `json:"ID,omitempty"`
 	Text      string
 	Done      bool
 	Priority  Priority
-	DueDate   *time.Time `json:"DueDate,omitempty"`
-	Tags      []string   `json:"Tags,omitempty"`
+	DueDate   *time.Time    `json:"DueDate,omitempty"`
+	Tags      []string      `json:"Tags,omitempty"`
+	Estimate  *Estimate     `json:"Estimate,omitempty"`
+	Tracked   time.Duration `json:"Tracked,omitempty"`
 	CreatedAt time.Time
+	// CompletedAt is when the item was last marked done
+	CompletedAt *time.Time `json:"CompletedAt,omitempty"`
+	// Assignee is who the task is assigned to; Creator is who added it
+	Assignee string `json:"Assignee,omitempty"`
+	Creator  string `json:"Creator,omitempty"`
+	// Context is where or how the task can be done, such as office or phone
+	Context string `json:"Context,omitempty"`
 }
 
 func NewItem(text string) Item {
 	return Item{
+		ID:        newID(),
 		Text:      text,
 		Done:      false,
 		Priority:  PriorityMedium,
@@ -62,8 +103,29 @@ func NewItem(text string) Item {
 	}
 }
 
+// newID returns a random identifier that stays with an item for its whole life
+func newID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// legacyID derives a stable identifier for items saved before IDs existed, so
+// repeated loads of an old file agree on it until the file is saved again
+func legacyID(item Item) string {
+	sum := sha1.Sum([]byte(item.CreatedAt.UTC().Format(time.RFC3339Nano) + "\x00" + item.Text))
+	return hex.EncodeToString(sum[:8])
+}
+
 type List struct {
 	Items []Item
+	// User is who is making changes; items added are recorded as created by them
+	User string `json:"-"`
+
+	subscribers    []subscriber
+	nextSubscriber int
 }
 
 func NewList() *List {
@@ -73,14 +135,36 @@ func NewList() *List {
 }
 
 func (l *List) Add(text string) error {
-	item := NewItem(text)
+	return l.AddItem(NewItem(text))
+}
+
+// AddItem appends a fully populated item, e.g. one read from an import file.
+// Like Add it rejects items whose text (or ID) is already in the list, and it fills in
+// an ID and creation time when they are missing.
+func (l *List) AddItem(item Item) error {
+	if item.Text == "" {
+		return errors.New("Task text cannot be empty")
+	}
 	// here check that this should not be in the list already
 	for _, existing := range l.Items {
-		if existing.Text == item.Text {
+		if existing.Text == item.Text || (item.ID != "" && existing.ID == item.ID) {
 			return errors.New("Item already exists in the list")
 		}
 	}
+	if item.ID == "" {
+		item.ID = newID()
+	}
+	if item.CreatedAt.IsZero() {
+		item.CreatedAt = time.Now()
+	}
+	if item.Creator == "" {
+		item.Creator = l.User
+	}
+	if item.Tags == nil {
+		item.Tags = []string{}
+	}
 	l.Items = append(l.Items, item)
+	l.emit(EventAdded, item, Item{})
 	return nil
 }
 
@@ -91,10 +175,17 @@ func (l *List) Complete(index int) error {
 	if index < 0 || index >= len(l.Items) {
 		return errors.New("Item index out of Range")
 	}
+	previous := l.Items[index].clone()
+	if !l.Items[index].Done {
+		now := time.Now()
+		l.Items[index].CompletedAt = &now
+	}
 	l.Items[index].Done = true
+	item := l.Items[index]
 
 	// Sort: move completed tasks to the bottom
 	l.Sort()
+	l.emit(EventCompleted, item, previous)
 	return nil
 }
 
@@ -119,7 +210,9 @@ func (l *List) Delete(index int) error {
 	if index < 0 || index >= len(l.Items) {
 		return errors.New("Item index out of Range")
 	}
+	item := l.Items[index]
 	l.Items = append(l.Items[:index], l.Items[index+1:]...)
+	l.emit(EventDeleted, item, item)
 	return nil
 }
 
@@ -131,7 +224,9 @@ func (l *List) Edit(index int, newText string) error {
 	if newText == "" {
 		return errors.New("Task text cannot be empty")
 	}
+	previous := l.Items[index].clone()
 	l.Items[index].Text = newText
+	l.emit(EventEdited, l.Items[index], previous)
 	return nil
 }
 
@@ -140,26 +235,33 @@ func (l *List) Uncomplete(index int) error {
 	if index < 0 || index >= len(l.Items) {
 		return errors.New("Item index out of Range")
 	}
+	previous := l.Items[index].clone()
 	l.Items[index].Done = false
+	l.Items[index].CompletedAt = nil
+	item := l.Items[index]
 	l.Sort()
+	l.emit(EventUncompleted, item, previous)
 	return nil
 }
 
 // ClearCompleted removes all completed tasks from the list
 func (l *List) ClearCompleted() int {
 	var incomplete []Item
-	count := 0
+	var cleared []Item
 
 	for _, item := range l.Items {
 		if !item.Done {
 			incomplete = append(incomplete, item)
 		} else {
-			count++
+			cleared = append(cleared, item)
 		}
 	}
 
 	l.Items = incomplete
-	return count
+	for _, item := range cleared {
+		l.emit(EventCleared, item, item)
+	}
+	return len(cleared)
 }
 
 // Stats represents statistics about the todo list
@@ -167,6 +269,10 @@ type Stats struct {
 	Total     int
 	Completed int
 	Pending   int
+
+	// Remaining estimated effort of pending tasks
+	EstimatedTime   time.Duration
+	EstimatedPoints float64
 }
 
 // GetStats returns statistics about the todo list
@@ -178,18 +284,67 @@ func (l *List) GetStats() Stats {
 			stats.Completed++
 		} else {
 			stats.Pending++
+			if item.Estimate != nil {
+				stats.EstimatedTime += item.Estimate.Duration
+				stats.EstimatedPoints += item.Estimate.Points
+			}
 		}
 	}
 
 	return stats
 }
 
+// IndexOf returns the index of the item with the given ID, or -1 if there is none
+func (l *List) IndexOf(id string) int {
+	for i, item := range l.Items {
+		if item.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Update replaces the editable fields of a task with those of item. The task keeps
+// its ID and creation time, and its completion time is kept or set as needed.
+func (l *List) Update(index int, item Item) error {
+	if index < 0 || index >= len(l.Items) {
+		return errors.New("Item index out of Range")
+	}
+	if item.Text == "" {
+		return errors.New("Task text cannot be empty")
+	}
+
+	existing := l.Items[index]
+	item.ID = existing.ID
+	item.CreatedAt = existing.CreatedAt
+	if item.Tags == nil {
+		item.Tags = []string{}
+	}
+
+	switch {
+	case !item.Done:
+		item.CompletedAt = nil
+	case item.CompletedAt == nil && existing.Done:
+		item.CompletedAt = existing.CompletedAt
+	case item.CompletedAt == nil:
+		now := time.Now()
+		item.CompletedAt = &now
+	}
+
+	l.Items[index] = item
+	l.Sort()
+	l.emit(EventUpdated, item, existing)
+	return nil
+}
+
 // SetPriority sets the priority of a task
 func (l *List) SetPriority(index int, priority Priority) error {
 	if index < 0 || index >= len(l.Items) {
 		return errors.New("Item index out of Range")
 	}
+	previous := l.Items[index].clone()
 	l.Items[index].Priority = priority
+	l.emit(EventPriorityChanged, l.Items[index], previous)
 	return nil
 }
 
@@ -198,7 +353,9 @@ func (l *List) SetDueDate(index int, dueDate time.Time) error {
 	if index < 0 || index >= len(l.Items) {
 		return errors.New("Item index out of Range")
 	}
+	previous := l.Items[index].clone()
 	l.Items[index].DueDate = &dueDate
+	l.emit(EventDueDateChanged, l.Items[index], previous)
 	return nil
 }
 
@@ -213,7 +370,9 @@ func (l *List) AddTag(index int, tag string) error {
 			return errors.New("Tag already exists")
 		}
 	}
+	previous := l.Items[index].clone()
 	l.Items[index].Tags = append(l.Items[index].Tags, tag)
+	l.emitTag(EventTagAdded, l.Items[index], previous, tag)
 	return nil
 }
 
@@ -222,10 +381,12 @@ func (l *List) RemoveTag(index int, tag string) error {
 	if index < 0 || index >= len(l.Items) {
 		return errors.New("Item index out of Range")
 	}
+	previous := l.Items[index].clone()
 	tags := l.Items[index].Tags
 	for i, t := range tags {
 		if t == tag {
 			l.Items[index].Tags = append(tags[:i], tags[i+1:]...)
+			l.emitTag(EventTagRemoved, l.Items[index], previous, tag)
 			return nil
 		}
 	}
@@ -234,68 +395,31 @@ func (l *List) RemoveTag(index int, tag string) error {
 
 // Search returns items that match the query in text or tags
 func (l *List) Search(query string) []Item {
-	var results []Item
-	query = strings.ToLower(query)
-
-	for _, item := range l.Items {
-		// Search in text
-		if strings.Contains(strings.ToLower(item.Text), query) {
-			results = append(results, item)
-			continue
-		}
-
-		// Search in tags
-		for _, tag := range item.Tags {
-			if strings.Contains(strings.ToLower(tag), query) {
-				results = append(results, item)
-				break
-			}
-		}
-	}
-
-	return results
+	return l.Filter(Filter{Query: query})
 }
 
 // FilterByPriority returns items with the specified priority
 func (l *List) FilterByPriority(priority Priority) []Item {
-	var results []Item
-	for _, item := range l.Items {
-		if item.Priority == priority {
-			results = append(results, item)
-		}
-	}
-	return results
+	return l.Filter(Filter{Priority: &priority})
 }
 
 // FilterByTag returns items with the specified tag
 func (l *List) FilterByTag(tag string) []Item {
-	var results []Item
-	for _, item := range l.Items {
-		for _, t := range item.Tags {
-			if t == tag {
-				results = append(results, item)
-				break
-			}
-		}
-	}
-	return results
+	return l.Filter(Filter{Tag: tag})
 }
 
 // GetOverdue returns items that are past their due date
 func (l *List) GetOverdue() []Item {
-	var results []Item
-	now := time.Now()
-
-	for _, item := range l.Items {
-		if item.DueDate != nil && item.DueDate.Before(now) && !item.Done {
-			results = append(results, item)
-		}
-	}
-
-	return results
+	return l.Filter(Filter{Overdue: true, Now: time.Now()})
 }
 
 func (l *List) String() string {
+	return l.Filtered(nil)
+}
+
+// Filtered renders the list like String, but only the items keep accepts.
+// Items keep their numbers in the whole list, so commands can refer to them.
+func (l *List) Filtered(keep func(Item) bool) string {
 	if len(l.Items) == 0 {
 		return "No items to return"
 	}
@@ -303,6 +427,9 @@ func (l *List) String() string {
 	result := "TODO List:\n"
 
 	for i, item := range l.Items {
+		if keep != nil && !keep(item) {
+			continue
+		}
 		status := " "
 		if item.Done {
 			status = "✓"
@@ -331,11 +458,24 @@ func (l *List) String() string {
 			}
 		}
 
+		// Add estimate if present
+		if item.Estimate != nil {
+			result += fmt.Sprintf(" ⏱️  %s", item.Estimate)
+		}
+
 		// Add tags if present
 		if len(item.Tags) > 0 {
 			result += fmt.Sprintf(" 🏷️  %s", strings.Join(item.Tags, ", "))
 		}
 
+		if item.Assignee != "" {
+			result += fmt.Sprintf(" 👤 %s", item.Assignee)
+		}
+
+		if item.Context != "" {
+			result += fmt.Sprintf(" 📍 @%s", item.Context)
+		}
+
 		result += "\n"
 	}
 	return result
@@ -362,3 +502,21 @@ func (l *List) Load(filename string) error {
 
 	return json.Unmarshal(data, l)
 }
+
+// UnmarshalJSON decodes a list written by Save. Items saved before IDs
+// existed get a stable ID derived from their contents.
+func (l *List) UnmarshalJSON(data []byte) error {
+	saved := struct {
+		Items []Item
+	}{l.Items}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return err
+	}
+	for i := range saved.Items {
+		if saved.Items[i].ID == "" {
+			saved.Items[i].ID = legacyID(saved.Items[i])
+		}
+	}
+	l.Items = saved.Items
+	return nil
+}
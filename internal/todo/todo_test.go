@@ -382,6 +382,21 @@ func TestParsePriority(t *testing.T) {
 	}
 }
 
+func TestPriorityNames(t *testing.T) {
+	names := PriorityNames()
+	if len(names) != 7 || names[0] != "high" || names[len(names)-1] != "l" {
+		t.Errorf("Expected names from high to low, got %v", names)
+	}
+	for _, name := range names {
+		if _, ok := LookupPriority(name); !ok {
+			t.Errorf("Expected %s to be a priority name", name)
+		}
+	}
+	if _, ok := LookupPriority("urgent"); ok {
+		t.Error("Expected urgent not to be a priority name")
+	}
+}
+
 func TestSetPriority(t *testing.T) {
 	list := NewList()
 	mustAdd(t, list, "Task 1")
@@ -879,3 +894,141 @@ func TestStringWithFutureDueDate(t *testing.T) {
 		t.Error("String output should not show OVERDUE for future dates")
 	}
 }
+
+func TestItemIDs(t *testing.T) {
+	list := NewList()
+	mustAdd(t, list, "Task 1")
+	mustAdd(t, list, "Task 2")
+
+	if list.Items[0].ID == "" || list.Items[0].ID == list.Items[1].ID {
+		t.Errorf("Expected unique item IDs, got %q and %q", list.Items[0].ID, list.Items[1].ID)
+	}
+}
+
+func TestLoadAssignsStableIDsToLegacyItems(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "legacy-json")
+	if err != nil {
+		t.Fatalf("Could not create temp file: %v", err)
+	}
+	defer os.Remove(tmpfile.Name())
+
+	legacy := `{"Items":[{"Text":"Old task","Done":false,"Priority":1,"CreatedAt":"2024-01-02T03:04:05Z"}]}`
+	if _, err := tmpfile.WriteString(legacy); err != nil {
+		t.Fatalf("Could not write to temp file: %v", err)
+	}
+	tmpfile.Close()
+
+	first := NewList()
+	if err := first.Load(tmpfile.Name()); err != nil {
+		t.Fatalf("Failed to load list: %v", err)
+	}
+	second := NewList()
+	if err := second.Load(tmpfile.Name()); err != nil {
+		t.Fatalf("Failed to load list: %v", err)
+	}
+
+	if first.Items[0].ID == "" {
+		t.Fatal("Expected legacy item to get an ID")
+	}
+	if first.Items[0].ID != second.Items[0].ID {
+		t.Errorf("Expected the same ID on every load, got %q and %q", first.Items[0].ID, second.Items[0].ID)
+	}
+}
+
+func TestAddItem_Imported(t *testing.T) {
+	list := NewList()
+	mustAdd(t, list, "Existing")
+
+	created := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	item := Item{Text: "Imported", Priority: PriorityHigh, CreatedAt: created}
+	if err := list.AddItem(item); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	added := list.Items[1]
+	if added.ID == "" {
+		t.Error("Expected imported item to get an ID")
+	}
+	if !added.CreatedAt.Equal(created) {
+		t.Errorf("Expected CreatedAt to be kept, got %v", added.CreatedAt)
+	}
+	if added.Tags == nil {
+		t.Error("Expected tags to be initialized")
+	}
+
+	if err := list.AddItem(Item{Text: "Existing"}); err == nil {
+		t.Error("Expected error when importing a duplicate item")
+	}
+	if err := list.AddItem(Item{}); err == nil {
+		t.Error("Expected error when importing an item without text")
+	}
+}
+
+func TestCompletedAt(t *testing.T) {
+	list := NewList()
+	mustAdd(t, list, "Task 1")
+	mustComplete(t, list, 0)
+
+	if list.Items[0].CompletedAt == nil {
+		t.Fatal("Expected CompletedAt to be set when completing")
+	}
+
+	if err := list.Uncomplete(0); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if list.Items[0].CompletedAt != nil {
+		t.Error("Expected CompletedAt to be cleared when uncompleting")
+	}
+}
+
+func TestIndexOf(t *testing.T) {
+	list := NewList()
+	mustAdd(t, list, "Task 1")
+	mustAdd(t, list, "Task 2")
+
+	if i := list.IndexOf(list.Items[1].ID); i != 1 {
+		t.Errorf("Expected index 1, got %d", i)
+	}
+	if i := list.IndexOf("missing"); i != -1 {
+		t.Errorf("Expected -1 for unknown ID, got %d", i)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	list := NewList()
+	mustAdd(t, list, "Task 1")
+	mustAdd(t, list, "Task 2")
+	original := list.Items[0]
+
+	due := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	err := list.Update(0, Item{ID: "ignored", Text: "Task 1 updated", Done: true, Priority: PriorityHigh, DueDate: &due})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The completed task moved to the bottom
+	updated := list.Items[1]
+	if updated.ID != original.ID || !updated.CreatedAt.Equal(original.CreatedAt) {
+		t.Error("Update should keep the ID and creation time")
+	}
+	if updated.Text != "Task 1 updated" || updated.Priority != PriorityHigh || updated.DueDate == nil {
+		t.Errorf("Fields were not updated: %+v", updated)
+	}
+	if updated.CompletedAt == nil || updated.Tags == nil {
+		t.Errorf("Expected completion time and tags to be set: %+v", updated)
+	}
+
+	if err := list.Update(1, Item{Text: "Task 1 updated"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if list.Items[1].Done || list.Items[1].CompletedAt != nil {
+		t.Error("Expected task to be reopened")
+	}
+
+	if err := list.Update(0, Item{}); err == nil {
+		t.Error("Expected error when updating with empty text")
+	}
+	if err := list.Update(5, Item{Text: "x"}); err == nil {
+		t.Error("Expected error when updating invalid index")
+	}
+}
@@ -0,0 +1,297 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rahul4507/todo/internal/filewatch"
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+// Event types published on the event stream
+const (
+	EventAdded     = "item-added"
+	EventUpdated   = "item-updated"
+	EventCompleted = "item-completed"
+	EventDeleted   = "item-deleted"
+
+	// eventReset tells a client that events were missed and it must reload
+	eventReset = "reset"
+)
+
+const (
+	// historySize is how many events are kept for clients resuming a stream
+	historySize = 256
+	// subscriberBuffer is how many events a slow client may fall behind before
+	// it is disconnected; it then resumes from its last event
+	subscriberBuffer = 64
+	keepAlive        = 15 * time.Second
+)
+
+// Change is a difference between two versions of a list
+type Change struct {
+	Type string
+	Item todo.Item
+}
+
+// Diff derives the changes that turn old into new, matching items by ID.
+// Completing an item is reported as EventCompleted; any other change to an
+// existing item, including reopening it, is EventUpdated.
+func Diff(old, new []todo.Item) []Change {
+	before := make(map[string]todo.Item, len(old))
+	for _, item := range old {
+		before[item.ID] = item
+	}
+
+	var changes []Change
+	seen := make(map[string]bool, len(new))
+	for _, item := range new {
+		seen[item.ID] = true
+		prev, ok := before[item.ID]
+		switch {
+		case !ok:
+			changes = append(changes, Change{EventAdded, item})
+		case item.Done && !prev.Done:
+			changes = append(changes, Change{EventCompleted, item})
+		case !todo.SameItem(prev, item):
+			changes = append(changes, Change{EventUpdated, item})
+		}
+	}
+	for _, item := range old {
+		if !seen[item.ID] {
+			changes = append(changes, Change{EventDeleted, item})
+		}
+	}
+	return changes
+}
+
+// Event is a change as sent to clients
+type Event struct {
+	ID   int64    `json:"-"`
+	Type string   `json:"type"`
+	Item ItemView `json:"item"`
+}
+
+// broker fans events out to subscribers and keeps recent ones for resuming
+type broker struct {
+	mu      sync.Mutex
+	last    int64
+	history []Event
+	subs    map[chan Event]struct{}
+}
+
+func newBroker() *broker {
+	// Start from the clock so IDs keep increasing across server restarts and
+	// a client resuming from a previous run is told to reload
+	return &broker{
+		last: time.Now().UnixMilli(),
+		subs: make(map[chan Event]struct{}),
+	}
+}
+
+func (b *broker) publish(changes []Change) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	for _, c := range changes {
+		b.last++
+		e := Event{ID: b.last, Type: c.Type, Item: NewItemView(c.Item, now)}
+		b.history = append(b.history, e)
+		for ch := range b.subs {
+			select {
+			case ch <- e:
+			default:
+				// Too far behind: drop it and let it resume
+				delete(b.subs, ch)
+				close(ch)
+			}
+		}
+	}
+	if len(b.history) > historySize {
+		b.history = append([]Event(nil), b.history[len(b.history)-historySize:]...)
+	}
+}
+
+// subscribe registers a subscriber. When resuming from lastID it also returns
+// the events missed since then, or reset if they are no longer available.
+func (b *broker) subscribe(lastID int64, resume bool) (ch chan Event, backlog []Event, reset bool, current int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if resume && lastID != b.last {
+		oldest := b.last + 1
+		if len(b.history) > 0 {
+			oldest = b.history[0].ID
+		}
+		if lastID < oldest-1 || lastID > b.last {
+			reset = true
+		} else {
+			for _, e := range b.history {
+				if e.ID > lastID {
+					backlog = append(backlog, e)
+				}
+			}
+		}
+	}
+
+	ch = make(chan Event, subscriberBuffer)
+	b.subs[ch] = struct{}{}
+	return ch, backlog, reset, b.last
+}
+
+func (b *broker) unsubscribe(ch chan Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subs[ch]; ok {
+		delete(b.subs, ch)
+		close(ch)
+	}
+}
+
+// observe publishes the changes between the last list seen and list, made
+// by other programs. The handler lock must be held.
+func (h *Handler) observe(list *todo.List) {
+	items := snapshot(list)
+	if h.seen != nil {
+		if changes := Diff(h.seen, items); len(changes) > 0 {
+			h.events.publish(changes)
+		}
+	}
+	h.seen = items
+}
+
+// record collects the changes made through the list's methods until they are
+// published by commit. The handler lock must be held.
+func (h *Handler) record(list *todo.List) {
+	h.pending = nil
+	list.Subscribe(func(e todo.Event) {
+		h.pending = append(h.pending, changeFor(e))
+	})
+}
+
+// commit publishes the recorded changes once the list has been saved. The
+// handler lock must be held.
+func (h *Handler) commit(list *todo.List) {
+	if len(h.pending) > 0 {
+		h.events.publish(h.pending)
+	}
+	h.pending = nil
+	h.seen = snapshot(list)
+}
+
+// changeFor maps a list event to the event sent to clients
+func changeFor(e todo.Event) Change {
+	switch e.Type {
+	case todo.EventAdded:
+		return Change{EventAdded, e.Item}
+	case todo.EventDeleted, todo.EventCleared:
+		return Change{EventDeleted, e.Item}
+	case todo.EventCompleted:
+		return Change{EventCompleted, e.Item}
+	case todo.EventUpdated:
+		if e.Item.Done && !e.Previous.Done {
+			return Change{EventCompleted, e.Item}
+		}
+	}
+	return Change{EventUpdated, e.Item}
+}
+
+// snapshot deep copies the items, since list methods may modify them in place
+func snapshot(list *todo.List) []todo.Item {
+	data, _ := json.Marshal(list.Items)
+	var items []todo.Item
+	json.Unmarshal(data, &items)
+	return items
+}
+
+// Watch publishes events for changes made to the store by other programs,
+// such as the CLI, until ctx is done. When path is set, the store is only
+// reloaded after the file's size or modification time changes; see
+// filewatch.Poll.
+func (h *Handler) Watch(ctx context.Context, path string, interval time.Duration, onError func(error)) {
+	filewatch.Poll(ctx, path, interval, func() error {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		list, err := h.Store.Load()
+		if err != nil {
+			return err
+		}
+		h.observe(list)
+		return nil
+	}, onError)
+}
+
+// stream sends changes as Server-Sent Events. Clients resume after a
+// disconnect by sending the Last-Event-ID header, which EventSource does
+// automatically.
+func (h *Handler) stream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "Streaming not supported")
+		return
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("lastEventId")
+	}
+	var lastID int64
+	resume := lastEventID != ""
+	if resume {
+		var err error
+		if lastID, err = strconv.ParseInt(lastEventID, 10, 64); err != nil {
+			lastID = -1
+		}
+	}
+
+	ch, backlog, reset, current := h.events.subscribe(lastID, resume)
+	defer h.events.unsubscribe(ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprint(w, "retry: 2000\n\n")
+	switch {
+	case reset:
+		fmt.Fprintf(w, "id: %d\nevent: %s\ndata: {}\n\n", current, eventReset)
+	case len(backlog) > 0:
+		for _, e := range backlog {
+			writeEvent(w, e)
+		}
+	default:
+		// Hand the client the current position so it can resume from it
+		// even if it disconnects before any event arrives
+		fmt.Fprintf(w, "id: %d\n\n", current)
+	}
+	flusher.Flush()
+
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-ch:
+			if !ok {
+				return
+			}
+			writeEvent(w, e)
+		case <-ticker.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		}
+		flusher.Flush()
+	}
+}
+
+func writeEvent(w http.ResponseWriter, e Event) {
+	data, _ := json.Marshal(e)
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+}
@@ -0,0 +1,162 @@
+package web
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+func TestDiff(t *testing.T) {
+	old := []todo.Item{
+		{ID: "a", Text: "Keep"},
+		{ID: "b", Text: "Finish"},
+		{ID: "c", Text: "Rename"},
+		{ID: "d", Text: "Remove"},
+		{ID: "e", Text: "Reopen", Done: true},
+	}
+	new := []todo.Item{
+		{ID: "a", Text: "Keep"},
+		{ID: "b", Text: "Finish", Done: true},
+		{ID: "c", Text: "Renamed"},
+		{ID: "e", Text: "Reopen"},
+		{ID: "f", Text: "Add"},
+	}
+
+	var got []string
+	for _, c := range Diff(old, new) {
+		got = append(got, c.Type+":"+c.Item.ID)
+	}
+	expected := "item-completed:b item-updated:c item-updated:e item-added:f item-deleted:d"
+	if strings.Join(got, " ") != expected {
+		t.Errorf("Expected %s, got %s", expected, strings.Join(got, " "))
+	}
+}
+
+func TestBrokerResume(t *testing.T) {
+	b := newBroker()
+	start := b.last
+	b.publish([]Change{{EventAdded, todo.Item{ID: "a"}}, {EventAdded, todo.Item{ID: "b"}}})
+
+	ch, backlog, reset, current := b.subscribe(start+1, true)
+	defer b.unsubscribe(ch)
+	if reset || len(backlog) != 1 || backlog[0].Item.ID != "b" || current != start+2 {
+		t.Errorf("Expected to resume with the second event, got %+v reset=%v", backlog, reset)
+	}
+
+	// Events older than the history, or from another server run, need a reload
+	_, _, reset, _ = b.subscribe(start-100, true)
+	if !reset {
+		t.Error("Expected reset for an unknown event ID")
+	}
+
+	b.publish([]Change{{EventDeleted, todo.Item{ID: "a"}}})
+	select {
+	case e := <-ch:
+		if e.Type != EventDeleted || e.ID != start+3 {
+			t.Errorf("Unexpected live event: %+v", e)
+		}
+	default:
+		t.Error("Expected the subscriber to receive the new event")
+	}
+}
+
+// readEvents reads SSE messages until n with a type have arrived
+func readEvents(t *testing.T, r *bufio.Reader, n int) []string {
+	t.Helper()
+	var types []string
+	for len(types) < n {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Reading event stream: %v", err)
+		}
+		if strings.HasPrefix(line, "event: ") {
+			types = append(types, strings.TrimSpace(strings.TrimPrefix(line, "event: ")))
+		}
+	}
+	return types
+}
+
+func TestStreamPublishesChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.json")
+	store := todo.FileStore{Path: path}
+	list := todo.NewList()
+	if err := list.Add("Existing"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(list); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := NewHandler(store)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go handler.Watch(ctx, path, 10*time.Millisecond, func(err error) { t.Error(err) })
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Expected an event stream, got %q", ct)
+	}
+	events := bufio.NewReader(resp.Body)
+
+	// A change through the API
+	do(t, http.MethodPost, server.URL+"/api/items", `{"text": "From the UI"}`, nil)
+	if got := readEvents(t, events, 1); got[0] != EventAdded {
+		t.Errorf("Expected item-added, got %v", got)
+	}
+
+	// A change made to the file by another program, such as the CLI
+	list, _ = store.Load()
+	if err := list.Complete(list.IndexOf(list.Items[0].ID)); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(list); err != nil {
+		t.Fatal(err)
+	}
+	if got := readEvents(t, events, 1); got[0] != EventCompleted {
+		t.Errorf("Expected item-completed, got %v", got)
+	}
+}
+
+func TestStreamResumesFromLastEventID(t *testing.T) {
+	server, store := newTestServer(t)
+	handler := server.Config.Handler.(*Handler)
+
+	// Prime the handler with the current list, then change it while no client is connected
+	getItems(t, server.URL+"/api/items")
+	last := handler.events.last
+	list, _ := store.Load()
+	if err := list.Delete(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(list); err != nil {
+		t.Fatal(err)
+	}
+	getItems(t, server.URL+"/api/items")
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/api/events", nil)
+	req.Header.Set("Last-Event-ID", strconv.FormatInt(last, 10))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if got := readEvents(t, bufio.NewReader(resp.Body), 1); got[0] != EventDeleted {
+		t.Errorf("Expected the missed item-deleted event, got %v", got)
+	}
+}
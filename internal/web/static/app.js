@@ -0,0 +1,252 @@
+"use strict";
+
+// Fallback polling interval (ms) for browsers without EventSource
+const POLL_INTERVAL = 5000;
+
+const state = {
+  etag: "",
+  tag: "",
+};
+
+const $ = (id) => document.getElementById(id);
+
+// An API token, when the server requires one. Open the UI as /#token=... to
+// hand it over; it is kept in local storage.
+const TOKEN_KEY = "todo-token";
+const hashToken = new URLSearchParams(location.hash.slice(1)).get("token");
+if (hashToken) {
+  localStorage.setItem(TOKEN_KEY, hashToken);
+  history.replaceState(null, "", location.pathname + location.search);
+}
+
+function authorize(headers) {
+  const token = localStorage.getItem(TOKEN_KEY);
+  if (token) headers["Authorization"] = "Bearer " + token;
+  return headers;
+}
+
+// send makes a request, asking for a token once if the server refuses it
+async function send(path, options) {
+  let resp = await fetch(path, { ...options, headers: authorize({ ...options.headers }) });
+  if (resp.status === 401) {
+    const token = prompt("This server needs an API token (see 'todo token create'):");
+    if (token) {
+      localStorage.setItem(TOKEN_KEY, token.trim());
+      if (events) listen();
+      resp = await fetch(path, { ...options, headers: authorize({ ...options.headers }) });
+    }
+  }
+  return resp;
+}
+
+async function api(method, path, body) {
+  const options = { method, headers: {} };
+  if (body !== undefined) {
+    options.headers["Content-Type"] = "application/json";
+    options.body = JSON.stringify(body);
+  }
+  const resp = await send(path, options);
+  if (!resp.ok) {
+    let message = resp.statusText;
+    try {
+      message = (await resp.json()).error || message;
+    } catch (e) {
+      // not a JSON error body
+    }
+    throw new Error(message);
+  }
+  return resp.status === 204 ? null : resp.json();
+}
+
+function showError(err) {
+  const el = $("error");
+  el.textContent = err ? "Error: " + err.message : "";
+  el.hidden = !err;
+}
+
+function query() {
+  const params = new URLSearchParams();
+  const q = $("filter-query").value.trim();
+  if (q) params.set("q", q);
+  if ($("filter-status").value) params.set("status", $("filter-status").value);
+  if ($("filter-priority").value) params.set("priority", $("filter-priority").value);
+  if (state.tag) params.set("tag", state.tag);
+  return params.toString();
+}
+
+async function refresh(force) {
+  const headers = {};
+  if (!force && state.etag) headers["If-None-Match"] = state.etag;
+
+  const resp = await send("/api/items?" + query(), { headers });
+  if (resp.status === 304) return;
+  if (!resp.ok) throw new Error(resp.statusText);
+
+  state.etag = resp.headers.get("ETag") || "";
+  render((await resp.json()).items);
+}
+
+function render(items) {
+  const list = $("items");
+  const template = $("item-template");
+  list.replaceChildren();
+
+  for (const item of items) {
+    const li = template.content.firstElementChild.cloneNode(true);
+    li.classList.toggle("done", item.done);
+
+    const done = li.querySelector(".done");
+    done.checked = item.done;
+    done.addEventListener("change", () => update(item.id, { done: done.checked }));
+
+    li.querySelector(".priority").textContent = item.prioritySymbol;
+    li.querySelector(".priority").title = item.priority;
+
+    const text = li.querySelector(".text");
+    text.textContent = item.text;
+    text.addEventListener("dblclick", () => edit(text, item));
+
+    if (item.due) {
+      const due = li.querySelector(".due");
+      due.textContent = "📅 " + item.due + (item.overdue ? " (OVERDUE!)" : "");
+      due.classList.toggle("overdue", item.overdue);
+    }
+    if (item.estimate) {
+      li.querySelector(".estimate").textContent = "⏱️ " + item.estimate;
+    }
+    if (item.tags.length > 0) {
+      const tags = li.querySelector(".tags");
+      tags.append("🏷️ ");
+      for (const tag of item.tags) {
+        const span = document.createElement("span");
+        span.className = "tag";
+        span.textContent = tag;
+        span.title = "Show only " + tag;
+        span.addEventListener("click", () => setTag(tag));
+        tags.append(span);
+      }
+    }
+
+    li.querySelector(".delete").addEventListener("click", () => remove(item));
+    list.append(li);
+  }
+
+  $("empty").hidden = items.length > 0;
+}
+
+function edit(span, item) {
+  const input = document.createElement("input");
+  input.className = "edit";
+  input.value = item.text;
+  span.replaceWith(input);
+  input.focus();
+
+  let finished = false;
+  const finish = (save) => {
+    if (finished) return;
+    finished = true;
+    const text = input.value.trim();
+    if (save && text && text !== item.text) {
+      update(item.id, { text });
+    } else {
+      input.replaceWith(span);
+    }
+  };
+  input.addEventListener("keydown", (e) => {
+    if (e.key === "Enter") finish(true);
+    if (e.key === "Escape") finish(false);
+  });
+  input.addEventListener("blur", () => finish(true));
+}
+
+function setTag(tag) {
+  state.tag = tag;
+  const button = $("filter-tag");
+  button.textContent = tag ? "🏷️ " + tag + " ✕" : "";
+  button.hidden = !tag;
+  reload();
+}
+
+async function update(id, changes) {
+  try {
+    await api("PATCH", "/api/items/" + encodeURIComponent(id), changes);
+    showError(null);
+  } catch (err) {
+    showError(err);
+  }
+  reload();
+}
+
+async function remove(item) {
+  if (!confirm('Delete "' + item.text + '"?')) return;
+  try {
+    await api("DELETE", "/api/items/" + encodeURIComponent(item.id));
+    showError(null);
+  } catch (err) {
+    showError(err);
+  }
+  reload();
+}
+
+function reload() {
+  refresh(true).catch(showError);
+}
+
+$("add-form").addEventListener("submit", async (e) => {
+  e.preventDefault();
+  const tags = $("add-tags").value.split(",").map((t) => t.trim()).filter(Boolean);
+  try {
+    await api("POST", "/api/items", {
+      text: $("add-text").value,
+      priority: $("add-priority").value,
+      due: $("add-due").value,
+      tags,
+    });
+    e.target.reset();
+    showError(null);
+  } catch (err) {
+    showError(err);
+  }
+  reload();
+});
+
+$("filter-query").addEventListener("input", reload);
+$("filter-status").addEventListener("change", reload);
+$("filter-priority").addEventListener("change", reload);
+$("filter-tag").addEventListener("click", () => setTag(""));
+
+// Pick up changes made elsewhere (other browsers, the CLI). The server pushes
+// an event for every change; EventSource reconnects by itself and resumes from
+// the last event it saw. Changes are applied once an edit is finished.
+let pending = false;
+
+function changed() {
+  if (document.querySelector(".edit")) {
+    pending = true;
+    return;
+  }
+  pending = false;
+  refresh(false).catch(showError);
+}
+
+let events = null;
+
+function listen() {
+  if (events) events.close();
+  // EventSource can't send headers, so the token goes in the URL
+  const token = localStorage.getItem(TOKEN_KEY);
+  events = new EventSource("/api/events" + (token ? "?access_token=" + encodeURIComponent(token) : ""));
+  for (const type of ["item-added", "item-updated", "item-completed", "item-deleted", "reset"]) {
+    events.addEventListener(type, changed);
+  }
+}
+
+if (window.EventSource) {
+  listen();
+  setInterval(() => pending && changed(), 1000);
+} else {
+  // The ETag makes unchanged polls cheap
+  setInterval(() => document.hidden || changed(), POLL_INTERVAL);
+}
+
+reload();
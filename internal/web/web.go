@@ -0,0 +1,358 @@
+// Package web serves a small single-page UI for a todo list together with the
+// JSON API it uses. The UI is embedded in the binary.
+package web
+
+import (
+	"crypto/sha1"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+//go:embed static
+var static embed.FS
+
+const maxBodySize = 1 << 20
+
+// Handler is an http.Handler serving the UI and the API on top of a Store
+type Handler struct {
+	Store todo.Store
+
+	// mu serializes load-modify-save cycles on the store
+	mu  sync.Mutex
+	mux *http.ServeMux
+
+	events *broker
+	// seen is the list as of the last load or save, to derive events for
+	// changes made by other programs from
+	seen []todo.Item
+	// pending holds changes made by this handler that are not saved yet
+	pending []Change
+}
+
+// NewHandler returns a web UI handler for the store
+func NewHandler(store todo.Store) *Handler {
+	h := &Handler{Store: store, mux: http.NewServeMux(), events: newBroker()}
+
+	assets, _ := fs.Sub(static, "static")
+	h.mux.Handle("GET /", http.FileServer(http.FS(assets)))
+	h.mux.HandleFunc("GET /api/items", h.list)
+	h.mux.HandleFunc("POST /api/items", h.create)
+	h.mux.HandleFunc("PATCH /api/items/{id}", h.update)
+	h.mux.HandleFunc("DELETE /api/items/{id}", h.delete)
+	h.mux.HandleFunc("GET /api/events", h.stream)
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// ItemView is an item as the UI shows it, with the same indicators as List.String
+type ItemView struct {
+	ID             string   `json:"id"`
+	Text           string   `json:"text"`
+	Done           bool     `json:"done"`
+	Priority       string   `json:"priority"`
+	PrioritySymbol string   `json:"prioritySymbol"`
+	Due            string   `json:"due,omitempty"`
+	Overdue        bool     `json:"overdue"`
+	Estimate       string   `json:"estimate,omitempty"`
+	Tags           []string `json:"tags"`
+}
+
+// NewItemView converts an item for the UI
+func NewItemView(item todo.Item, now time.Time) ItemView {
+	v := ItemView{
+		ID:             item.ID,
+		Text:           item.Text,
+		Done:           item.Done,
+		Priority:       strings.ToLower(item.Priority.String()),
+		PrioritySymbol: prioritySymbol(item.Priority),
+		Tags:           item.Tags,
+	}
+	if v.Tags == nil {
+		v.Tags = []string{}
+	}
+	if item.DueDate != nil {
+		v.Due = item.DueDate.Format("2006-01-02")
+		v.Overdue = item.DueDate.Before(now) && !item.Done
+	}
+	if item.Estimate != nil {
+		v.Estimate = item.Estimate.String()
+	}
+	return v
+}
+
+func prioritySymbol(p todo.Priority) string {
+	switch p {
+	case todo.PriorityHigh:
+		return "🔴"
+	case todo.PriorityMedium:
+		return "🟡"
+	case todo.PriorityLow:
+		return "🟢"
+	}
+	return ""
+}
+
+// Version changes whenever anything in the list changes
+func Version(list *todo.List) string {
+	data, _ := json.Marshal(list.Items)
+	sum := sha1.Sum(data)
+	return hex.EncodeToString(sum[:10])
+}
+
+// Filter selects the items shown by the UI
+type Filter struct {
+	Query    string // matches text or tags, like List.Search
+	Tag      string
+	Priority string
+	Status   string // "pending", "done" or "" for all
+}
+
+// Match reports whether the item passes the filter
+func (f Filter) Match(item todo.Item) bool {
+	filter := todo.Filter{Query: f.Query, Tag: f.Tag, Status: f.Status}
+	if f.Priority != "" {
+		priority := todo.ParsePriority(f.Priority)
+		filter.Priority = &priority
+	}
+	return filter.Match(item)
+}
+
+type listResponse struct {
+	Version string     `json:"version"`
+	Items   []ItemView `json:"items"`
+}
+
+// itemRequest is the body of create and update requests. Fields left out of
+// an update keep their current value; an empty due date clears it.
+type itemRequest struct {
+	Text     *string   `json:"text"`
+	Done     *bool     `json:"done"`
+	Priority *string   `json:"priority"`
+	Due      *string   `json:"due"`
+	Tags     *[]string `json:"tags"`
+}
+
+func (req itemRequest) apply(item *todo.Item) error {
+	if req.Text != nil {
+		item.Text = strings.TrimSpace(*req.Text)
+	}
+	if req.Done != nil {
+		item.Done = *req.Done
+	}
+	if req.Priority != nil {
+		item.Priority = todo.ParsePriority(*req.Priority)
+	}
+	if req.Due != nil {
+		if *req.Due == "" {
+			item.DueDate = nil
+		} else {
+			due, err := time.Parse("2006-01-02", *req.Due)
+			if err != nil {
+				return errors.New("Invalid date format. Use YYYY-MM-DD")
+			}
+			item.DueDate = &due
+		}
+	}
+	if req.Tags != nil {
+		item.Tags = []string{}
+		for _, tag := range *req.Tags {
+			tag = strings.TrimSpace(tag)
+			if tag != "" && !item.HasTag(tag) {
+				item.Tags = append(item.Tags, tag)
+			}
+		}
+	}
+	return nil
+}
+
+func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	list, ok := h.load(w)
+	h.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	version := Version(list)
+	filter := Filter{
+		Query:    r.URL.Query().Get("q"),
+		Tag:      r.URL.Query().Get("tag"),
+		Priority: r.URL.Query().Get("priority"),
+		Status:   r.URL.Query().Get("status"),
+	}
+	// The ETag covers the filter too, so a changed filter never gets a stale 304
+	etag := `"` + version + "-" + hex.EncodeToString([]byte(r.URL.RawQuery)) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	resp := listResponse{Version: version, Items: []ItemView{}}
+	now := time.Now()
+	for _, item := range list.Items {
+		if filter.Match(item) {
+			resp.Items = append(resp.Items, NewItemView(item, now))
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) create(w http.ResponseWriter, r *http.Request) {
+	var req itemRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	item := todo.NewItem("")
+	if err := req.apply(&item); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if item.Done {
+		now := time.Now()
+		item.CompletedAt = &now
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	list, ok := h.load(w)
+	if !ok {
+		return
+	}
+	if err := list.AddItem(item); err != nil {
+		status := http.StatusConflict
+		if item.Text == "" {
+			status = http.StatusBadRequest
+		}
+		writeError(w, status, err.Error())
+		return
+	}
+	list.Sort()
+	if !h.save(w, list) {
+		return
+	}
+	writeJSON(w, http.StatusCreated, NewItemView(list.Items[list.IndexOf(item.ID)], time.Now()))
+}
+
+func (h *Handler) update(w http.ResponseWriter, r *http.Request) {
+	var req itemRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	list, ok := h.load(w)
+	if !ok {
+		return
+	}
+	id := r.PathValue("id")
+	index := list.IndexOf(id)
+	if index < 0 {
+		writeError(w, http.StatusNotFound, "Item not found")
+		return
+	}
+
+	item := list.Items[index]
+	if err := req.apply(&item); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	for i, other := range list.Items {
+		if i != index && other.Text == item.Text {
+			writeError(w, http.StatusConflict, "Item already exists in the list")
+			return
+		}
+	}
+	if err := list.Update(index, item); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !h.save(w, list) {
+		return
+	}
+	writeJSON(w, http.StatusOK, NewItemView(list.Items[list.IndexOf(id)], time.Now()))
+}
+
+func (h *Handler) delete(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	list, ok := h.load(w)
+	if !ok {
+		return
+	}
+	index := list.IndexOf(r.PathValue("id"))
+	if index < 0 {
+		writeError(w, http.StatusNotFound, "Item not found")
+		return
+	}
+	if err := list.Delete(index); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !h.save(w, list) {
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// load reads the list, publishing events for changes made since the last
+// load. The handler lock must be held.
+func (h *Handler) load(w http.ResponseWriter) (*todo.List, bool) {
+	list, err := h.Store.Load()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Loading todos failed: "+err.Error())
+		return nil, false
+	}
+	h.observe(list)
+	h.record(list)
+	return list, true
+}
+
+// save writes the list and publishes events for the changes. The handler
+// lock must be held.
+func (h *Handler) save(w http.ResponseWriter, list *todo.List) bool {
+	if err := h.Store.Save(list); err != nil {
+		writeError(w, http.StatusInternalServerError, "Saving todos failed: "+err.Error())
+		return false
+	}
+	h.commit(list)
+	return true
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
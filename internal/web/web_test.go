@@ -0,0 +1,186 @@
+package web
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+func newTestServer(t *testing.T) (*httptest.Server, todo.Store) {
+	t.Helper()
+	store := todo.FileStore{Path: filepath.Join(t.TempDir(), "todos.json")}
+
+	list := todo.NewList()
+	if err := list.Add("Write report"); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.AddTag(0, "work"); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.SetPriority(0, todo.PriorityHigh); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.Add("Buy milk"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(list); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(NewHandler(store))
+	t.Cleanup(server.Close)
+	return server, store
+}
+
+func do(t *testing.T, method, url, body string, headers map[string]string) (*http.Response, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, string(data)
+}
+
+func getItems(t *testing.T, url string) []ItemView {
+	t.Helper()
+	resp, body := do(t, http.MethodGet, url, "", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", resp.StatusCode, body)
+	}
+	var out listResponse
+	if err := json.Unmarshal([]byte(body), &out); err != nil {
+		t.Fatal(err)
+	}
+	return out.Items
+}
+
+func TestServesUI(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	for _, path := range []string{"/", "/app.js", "/style.css"} {
+		resp, _ := do(t, http.MethodGet, server.URL+path, "", nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected 200 for %s, got %d", path, resp.StatusCode)
+		}
+	}
+}
+
+func TestListAndFilter(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	items := getItems(t, server.URL+"/api/items")
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(items))
+	}
+	if items[0].PrioritySymbol != "🔴" || items[0].Tags[0] != "work" {
+		t.Errorf("Unexpected first item: %+v", items[0])
+	}
+
+	if items := getItems(t, server.URL+"/api/items?tag=work"); len(items) != 1 || items[0].Text != "Write report" {
+		t.Errorf("Expected only the work item, got %+v", items)
+	}
+	if items := getItems(t, server.URL+"/api/items?q=MILK"); len(items) != 1 || items[0].Text != "Buy milk" {
+		t.Errorf("Expected search to match Buy milk, got %+v", items)
+	}
+	if items := getItems(t, server.URL+"/api/items?status=done"); len(items) != 0 {
+		t.Errorf("Expected no done items, got %+v", items)
+	}
+}
+
+func TestListNotModified(t *testing.T) {
+	server, store := newTestServer(t)
+
+	resp, _ := do(t, http.MethodGet, server.URL+"/api/items", "", nil)
+	etag := resp.Header.Get("ETag")
+
+	resp, _ = do(t, http.MethodGet, server.URL+"/api/items", "", map[string]string{"If-None-Match": etag})
+	if resp.StatusCode != http.StatusNotModified {
+		t.Errorf("Expected 304 for unchanged list, got %d", resp.StatusCode)
+	}
+
+	// A change made by another client (e.g. the CLI) is picked up
+	list, _ := store.Load()
+	if err := list.Complete(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(list); err != nil {
+		t.Fatal(err)
+	}
+	resp, _ = do(t, http.MethodGet, server.URL+"/api/items", "", map[string]string{"If-None-Match": etag})
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200 after a change, got %d", resp.StatusCode)
+	}
+}
+
+func TestCreateUpdateDelete(t *testing.T) {
+	server, store := newTestServer(t)
+
+	resp, body := do(t, http.MethodPost, server.URL+"/api/items",
+		`{"text": "Plan trip", "priority": "low", "due": "2030-06-01", "tags": ["home", " ", "home"]}`, nil)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", resp.StatusCode, body)
+	}
+	var created ItemView
+	if err := json.Unmarshal([]byte(body), &created); err != nil {
+		t.Fatal(err)
+	}
+	if created.Priority != "low" || created.Due != "2030-06-01" || len(created.Tags) != 1 {
+		t.Errorf("Unexpected created item: %+v", created)
+	}
+
+	resp, _ = do(t, http.MethodPost, server.URL+"/api/items", `{"text": "Buy milk"}`, nil)
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("Expected 409 for duplicate, got %d", resp.StatusCode)
+	}
+
+	url := server.URL + "/api/items/" + created.ID
+	resp, body = do(t, http.MethodPatch, url, `{"done": true, "text": "Plan summer trip", "due": ""}`, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", resp.StatusCode, body)
+	}
+
+	list, _ := store.Load()
+	item := list.Items[list.IndexOf(created.ID)]
+	if !item.Done || item.CompletedAt == nil || item.Text != "Plan summer trip" || item.DueDate != nil {
+		t.Errorf("Unexpected updated item: %+v", item)
+	}
+	if item.Priority != todo.PriorityLow {
+		t.Errorf("Fields left out of the update should be kept, got priority %v", item.Priority)
+	}
+
+	resp, _ = do(t, http.MethodPatch, url, `{"text": "Buy milk"}`, nil)
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("Expected 409 when renaming to an existing task, got %d", resp.StatusCode)
+	}
+	resp, _ = do(t, http.MethodPatch, url, `{"due": "tomorrow"}`, nil)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid date, got %d", resp.StatusCode)
+	}
+
+	resp, _ = do(t, http.MethodDelete, url, "", nil)
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected 204, got %d", resp.StatusCode)
+	}
+	resp, _ = do(t, http.MethodDelete, url, "", nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 after delete, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,392 @@
+// Package webhook delivers task events to HTTP endpoints as signed JSON
+// payloads, queueing failed deliveries for retry with exponential backoff.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+// Headers sent with every delivery
+const (
+	HeaderEvent     = "X-Todo-Event"
+	HeaderDelivery  = "X-Todo-Delivery"
+	HeaderSignature = "X-Todo-Signature"
+)
+
+// TestEvent is the event sent by Dispatcher.Test
+const TestEvent = "test"
+
+const (
+	// DefaultMaxAttempts is how often a delivery is tried before it is dropped
+	DefaultMaxAttempts = 8
+
+	backoffBase = 30 * time.Second
+	backoffMax  = time.Hour
+)
+
+// Target is an endpoint that receives events
+type Target struct {
+	URL string `json:"url"`
+	// Secret signs payloads; the signature is sent in HeaderSignature
+	Secret string `json:"secret,omitempty"`
+	// Events limits the events sent (e.g. "added", "completed"); empty means all
+	Events []string `json:"events,omitempty"`
+}
+
+// Wants reports whether the target subscribes to event
+func (t Target) Wants(event string) bool {
+	if len(t.Events) == 0 || event == TestEvent {
+		return true
+	}
+	for _, e := range t.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Config is the list of targets, kept in a JSON file
+type Config struct {
+	path    string
+	Targets []Target
+}
+
+// LoadConfig reads the targets from path. A missing file yields no targets.
+func LoadConfig(path string) (*Config, error) {
+	c := &Config{path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &c.Targets); err != nil {
+		return nil, fmt.Errorf("Invalid webhook config %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Save writes the targets back to the file they were loaded from. The file
+// holds secrets, so it is only readable by the owner.
+func (c *Config) Save() error {
+	data, err := json.MarshalIndent(c.Targets, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0600)
+}
+
+// Target returns the target with the given URL
+func (c *Config) Target(url string) (Target, bool) {
+	for _, t := range c.Targets {
+		if t.URL == url {
+			return t, true
+		}
+	}
+	return Target{}, false
+}
+
+// Payload is the JSON body of a delivery
+type Payload struct {
+	ID       string     `json:"id"`
+	Event    string     `json:"event"`
+	Time     time.Time  `json:"time"`
+	Item     todo.Item  `json:"item"`
+	Previous *todo.Item `json:"previous,omitempty"`
+}
+
+// NewPayload builds the payload for a list event
+func NewPayload(e todo.Event) Payload {
+	p := Payload{ID: newID(), Event: string(e.Type), Time: e.Time, Item: e.Item}
+	if e.Type != todo.EventAdded {
+		previous := e.Previous
+		p.Previous = &previous
+	}
+	return p
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// Sign returns the signature of body: "sha256=" followed by the hex encoded
+// HMAC-SHA256 of the body with the secret as key
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature made by Sign
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// Delivery is a payload waiting to be sent to one target
+type Delivery struct {
+	ID          string          `json:"id"`
+	URL         string          `json:"url"`
+	Event       string          `json:"event"`
+	Body        json.RawMessage `json:"body"`
+	Attempts    int             `json:"attempts"`
+	NextAttempt time.Time       `json:"next_attempt"`
+	LastError   string          `json:"last_error,omitempty"`
+}
+
+// Queue holds deliveries that have not succeeded yet, kept in a JSON file so
+// they survive restarts
+type Queue struct {
+	path       string
+	Deliveries []Delivery
+}
+
+// LoadQueue reads the queue from path. A missing file yields an empty queue.
+func LoadQueue(path string) (*Queue, error) {
+	q := &Queue{path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &q.Deliveries); err != nil {
+		return nil, fmt.Errorf("Invalid webhook queue %s: %w", path, err)
+	}
+	return q, nil
+}
+
+// Save writes the queue back to the file it was loaded from
+func (q *Queue) Save() error {
+	data, err := json.Marshal(q.Deliveries)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(q.path, data, 0644)
+}
+
+// Backoff returns how long to wait before retrying a delivery that has
+// failed attempts times: 30s, 1m, 2m, ... up to an hour
+func Backoff(attempts int) time.Duration {
+	d := backoffBase
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= backoffMax {
+			return backoffMax
+		}
+	}
+	return d
+}
+
+// Dispatcher queues events for the targets that want them and sends them
+type Dispatcher struct {
+	Targets     []Target
+	Queue       *Queue
+	Client      *http.Client
+	MaxAttempts int
+}
+
+// Enqueue adds a delivery of the event for every target that wants it
+func (d *Dispatcher) Enqueue(e todo.Event, now time.Time) error {
+	payload := NewPayload(e)
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	for _, t := range d.Targets {
+		if !t.Wants(payload.Event) {
+			continue
+		}
+		d.Queue.Deliveries = append(d.Queue.Deliveries, Delivery{
+			ID:          payload.ID,
+			URL:         t.URL,
+			Event:       payload.Event,
+			Body:        body,
+			NextAttempt: now,
+		})
+	}
+	return nil
+}
+
+// FlushResult summarizes a Flush
+type FlushResult struct {
+	Sent     int
+	Retrying int
+	// Dropped are deliveries that ran out of attempts or whose target was removed
+	Dropped []Delivery
+}
+
+// Flush sends the deliveries that are due. Failed deliveries are retried
+// later with exponential backoff; later deliveries to a failing target wait
+// for the next flush so events arrive in order.
+func (d *Dispatcher) Flush(now time.Time) FlushResult {
+	var result FlushResult
+	maxAttempts := d.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+
+	// Targets with an undelivered earlier event get nothing newer this time
+	blocked := make(map[string]bool)
+	var remaining []Delivery
+	for _, delivery := range d.Queue.Deliveries {
+		target, ok := d.target(delivery.URL)
+		if !ok {
+			delivery.LastError = "target removed"
+			result.Dropped = append(result.Dropped, delivery)
+			continue
+		}
+		if blocked[delivery.URL] || delivery.NextAttempt.After(now) {
+			blocked[delivery.URL] = true
+			remaining = append(remaining, delivery)
+			continue
+		}
+
+		err := d.send(target, delivery.ID, delivery.Event, delivery.Body)
+		if err == nil {
+			result.Sent++
+			continue
+		}
+
+		blocked[delivery.URL] = true
+		delivery.Attempts++
+		delivery.LastError = err.Error()
+		if delivery.Attempts >= maxAttempts {
+			result.Dropped = append(result.Dropped, delivery)
+			continue
+		}
+		delivery.NextAttempt = now.Add(Backoff(delivery.Attempts))
+		remaining = append(remaining, delivery)
+	}
+
+	for _, delivery := range remaining {
+		if delivery.Attempts > 0 {
+			result.Retrying++
+		}
+	}
+	d.Queue.Deliveries = remaining
+	return result
+}
+
+// Notifier sends the changes saved through a todo.ObservedStore to the
+// webhooks, so they go out however the list was changed
+type Notifier struct {
+	// Load reads the targets and the retry queue for each delivery, so
+	// changes to them take effect at once
+	Load func() (*Dispatcher, error)
+	// Report is told the result of each delivery; nil ignores it
+	Report func(FlushResult)
+	// OnError is told about errors; nil ignores them. The changes are
+	// already saved, so they don't stop anything.
+	OnError func(error)
+}
+
+// BeforeSave does nothing; webhooks only hear about saved changes
+func (n Notifier) BeforeSave([]todo.Event) error {
+	return nil
+}
+
+// AfterSave queues the changes for the targets that want them, sends what
+// is due and saves the rest for a retry
+func (n Notifier) AfterSave(changes []todo.Event) {
+	d, err := n.Load()
+	if err != nil {
+		n.error(err)
+		return
+	}
+	if len(d.Targets) == 0 && len(d.Queue.Deliveries) == 0 {
+		return
+	}
+
+	now := time.Now()
+	for _, e := range changes {
+		if err := d.Enqueue(e, now); err != nil {
+			n.error(err)
+		}
+	}
+	result := d.Flush(now)
+	if n.Report != nil {
+		n.Report(result)
+	}
+	if err := d.Queue.Save(); err != nil {
+		n.error(err)
+	}
+}
+
+func (n Notifier) error(err error) {
+	if n.OnError != nil {
+		n.OnError(err)
+	}
+}
+
+// Test sends a test event with a sample item straight to the target,
+// bypassing the queue
+func (d *Dispatcher) Test(t Target) error {
+	item := todo.NewItem("Webhook test")
+	payload := Payload{ID: newID(), Event: TestEvent, Time: time.Now(), Item: item}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return d.send(t, payload.ID, payload.Event, body)
+}
+
+func (d *Dispatcher) target(url string) (Target, bool) {
+	for _, t := range d.Targets {
+		if t.URL == url {
+			return t, true
+		}
+	}
+	return Target{}, false
+}
+
+// send makes one delivery attempt; any response other than 2xx is a failure
+func (d *Dispatcher) send(t Target, id, event string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, t.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "todo-webhooks")
+	req.Header.Set(HeaderEvent, event)
+	req.Header.Set(HeaderDelivery, id)
+	if t.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(t.Secret, body))
+	}
+
+	client := d.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
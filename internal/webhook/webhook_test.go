@@ -0,0 +1,251 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rahul4507/todo/internal/rpc"
+	"github.com/rahul4507/todo/internal/todo"
+)
+
+// receiver is a webhook endpoint that records what it receives and fails
+// while failing is set
+type receiver struct {
+	mu       sync.Mutex
+	failing  bool
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	body, _ := io.ReadAll(r.Body)
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+	if rc.failing {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}
+}
+
+func newReceiver(t *testing.T) (*receiver, *httptest.Server) {
+	rc := &receiver{}
+	server := httptest.NewServer(rc)
+	t.Cleanup(server.Close)
+	return rc, server
+}
+
+func completedEvent() todo.Event {
+	item := todo.NewItem("Ship release")
+	previous := item
+	item.Done = true
+	return todo.Event{Type: todo.EventCompleted, Item: item, Previous: previous, Time: time.Now()}
+}
+
+func TestDeliverySignedPayload(t *testing.T) {
+	rc, server := newReceiver(t)
+	queue, _ := LoadQueue(filepath.Join(t.TempDir(), "queue.json"))
+	d := &Dispatcher{Targets: []Target{{URL: server.URL, Secret: "s3cret"}}, Queue: queue}
+
+	now := time.Now()
+	if err := d.Enqueue(completedEvent(), now); err != nil {
+		t.Fatal(err)
+	}
+	result := d.Flush(now)
+	if result.Sent != 1 || len(queue.Deliveries) != 0 {
+		t.Fatalf("Expected one delivery sent, got %+v", result)
+	}
+
+	req, body := rc.requests[0], rc.bodies[0]
+	if req.Header.Get(HeaderEvent) != "completed" || req.Header.Get(HeaderDelivery) == "" {
+		t.Errorf("Unexpected headers: %v", req.Header)
+	}
+	if !Verify("s3cret", body, req.Header.Get(HeaderSignature)) {
+		t.Errorf("Signature %q does not verify", req.Header.Get(HeaderSignature))
+	}
+	if Verify("other", body, req.Header.Get(HeaderSignature)) {
+		t.Error("Signature should not verify with another secret")
+	}
+
+	var payload Payload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Event != "completed" || payload.Item.Text != "Ship release" || !payload.Item.Done ||
+		payload.Previous == nil || payload.Previous.Done {
+		t.Errorf("Unexpected payload: %+v", payload)
+	}
+}
+
+func TestTargetEventFilter(t *testing.T) {
+	rc, server := newReceiver(t)
+	queue, _ := LoadQueue(filepath.Join(t.TempDir(), "queue.json"))
+	d := &Dispatcher{Targets: []Target{{URL: server.URL, Events: []string{"added"}}}, Queue: queue}
+
+	now := time.Now()
+	d.Enqueue(completedEvent(), now)
+	d.Enqueue(todo.Event{Type: todo.EventAdded, Item: todo.NewItem("New")}, now)
+	d.Flush(now)
+
+	if len(rc.requests) != 1 || rc.requests[0].Header.Get(HeaderEvent) != "added" {
+		t.Errorf("Expected only the added event, got %d requests", len(rc.requests))
+	}
+}
+
+func TestRetryWithBackoff(t *testing.T) {
+	rc, server := newReceiver(t)
+	rc.failing = true
+	path := filepath.Join(t.TempDir(), "queue.json")
+	queue, _ := LoadQueue(path)
+	d := &Dispatcher{Targets: []Target{{URL: server.URL}}, Queue: queue, MaxAttempts: 3}
+
+	now := time.Now()
+	d.Enqueue(completedEvent(), now)
+	d.Enqueue(todo.Event{Type: todo.EventAdded, Item: todo.NewItem("Second")}, now)
+
+	result := d.Flush(now)
+	if result.Sent != 0 || result.Retrying != 1 || len(queue.Deliveries) != 2 {
+		t.Fatalf("Expected both deliveries kept, one retrying: %+v", result)
+	}
+	if len(rc.requests) != 1 {
+		t.Errorf("Later deliveries to a failing target should wait, got %d requests", len(rc.requests))
+	}
+	first := queue.Deliveries[0]
+	if first.Attempts != 1 || !first.NextAttempt.Equal(now.Add(30*time.Second)) || first.LastError == "" {
+		t.Errorf("Unexpected retry state: %+v", first)
+	}
+
+	// The queue survives a restart
+	if err := queue.Save(); err != nil {
+		t.Fatal(err)
+	}
+	queue, err := LoadQueue(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Queue = queue
+
+	// Not due yet
+	if result := d.Flush(now.Add(10 * time.Second)); len(rc.requests) != 1 || result.Sent != 0 {
+		t.Errorf("Expected no attempt before the backoff, got %d requests", len(rc.requests))
+	}
+
+	rc.failing = false
+	result = d.Flush(now.Add(31 * time.Second))
+	if result.Sent != 2 || len(queue.Deliveries) != 0 {
+		t.Errorf("Expected both deliveries sent after recovery: %+v", result)
+	}
+}
+
+func TestDropAfterMaxAttempts(t *testing.T) {
+	rc, server := newReceiver(t)
+	rc.failing = true
+	queue, _ := LoadQueue(filepath.Join(t.TempDir(), "queue.json"))
+	d := &Dispatcher{Targets: []Target{{URL: server.URL}}, Queue: queue, MaxAttempts: 2}
+
+	now := time.Now()
+	d.Enqueue(completedEvent(), now)
+	d.Flush(now)
+	result := d.Flush(now.Add(time.Hour))
+	if len(result.Dropped) != 1 || len(queue.Deliveries) != 0 {
+		t.Errorf("Expected the delivery to be dropped: %+v", result)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	cases := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{4, 4 * time.Minute},
+		{20, time.Hour},
+	}
+	for _, c := range cases {
+		if got := Backoff(c.attempts); got != c.expected {
+			t.Errorf("Backoff(%d): expected %s, got %s", c.attempts, c.expected, got)
+		}
+	}
+}
+
+func TestSendTest(t *testing.T) {
+	rc, server := newReceiver(t)
+	d := &Dispatcher{}
+
+	if err := d.Test(Target{URL: server.URL, Events: []string{"added"}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(rc.requests) != 1 || rc.requests[0].Header.Get(HeaderEvent) != TestEvent {
+		t.Errorf("Expected a test delivery, got %d requests", len(rc.requests))
+	}
+
+	rc.failing = true
+	if err := d.Test(Target{URL: server.URL}); err == nil {
+		t.Error("Expected an error from a failing target")
+	}
+}
+
+func TestConfigRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "webhooks.json")
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Targets = append(config.Targets, Target{URL: "https://example.com/hook", Secret: "x", Events: []string{"completed"}})
+	if err := config.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, ok := loaded.Target("https://example.com/hook")
+	if !ok || target.Secret != "x" || !target.Wants("completed") || target.Wants("added") {
+		t.Errorf("Unexpected target: %+v", target)
+	}
+}
+
+func TestNotifierDeliversServerChanges(t *testing.T) {
+	rc, server := newReceiver(t)
+	queuePath := filepath.Join(t.TempDir(), "queue.json")
+	notifier := Notifier{
+		Load: func() (*Dispatcher, error) {
+			queue, err := LoadQueue(queuePath)
+			return &Dispatcher{Targets: []Target{{URL: server.URL}}, Queue: queue}, err
+		},
+		OnError: func(err error) { t.Error(err) },
+	}
+	store := todo.Observe(todo.FileStore{Path: filepath.Join(t.TempDir(), "todos.json")}, notifier)
+	service := &rpc.Service{Store: store, User: "alice"}
+
+	added, err := service.Add(rpc.AddParams{Text: "Added by an assistant"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Complete(rpc.Ref{ID: added.Item.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if len(rc.bodies) != 2 {
+		t.Fatalf("Expected two deliveries, got %d", len(rc.bodies))
+	}
+	for i, event := range []string{"added", "completed"} {
+		var payload Payload
+		if err := json.Unmarshal(rc.bodies[i], &payload); err != nil {
+			t.Fatal(err)
+		}
+		if payload.Event != event || payload.Item.Text != "Added by an assistant" || payload.Item.Creator != "alice" {
+			t.Errorf("Delivery %d: unexpected payload %+v", i, payload)
+		}
+	}
+}